@@ -0,0 +1,167 @@
+// Package calendar does the date math of the calendar view. It works on
+// calendar dates rather than instants, so that days stay days across
+// daylight saving changes, and knows which day weeks start on in each
+// region.
+package calendar
+
+import (
+	"strings"
+	"time"
+)
+
+// Date is a calendar day, without a time or a location.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the day t falls on in its location.
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{y, m, d}
+}
+
+// utc returns midnight UTC on the date, where every day has 24 hours.
+func (d Date) utc() time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, time.UTC)
+}
+
+// AddDays returns the date n days later, or earlier when n is negative.
+func (d Date) AddDays(n int) Date {
+	return DateOf(d.utc().AddDate(0, 0, n))
+}
+
+// AddMonths returns the same day n months later, clamped to the end of
+// shorter months: January 31 plus one month is the end of February.
+func (d Date) AddMonths(n int) Date {
+	first := time.Date(d.Year, d.Month+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	return Date{first.Year(), first.Month(), min(d.Day, last)}
+}
+
+func (d Date) Weekday() time.Weekday {
+	return d.utc().Weekday()
+}
+
+func (d Date) Before(other Date) bool {
+	return d.utc().Before(other.utc())
+}
+
+// DaysUntil returns how many days other is after d.
+func (d Date) DaysUntil(other Date) int {
+	return int(other.utc().Sub(d.utc()).Hours() / 24)
+}
+
+// At returns the time on the date at the given clock time in loc.
+func (d Date) At(hour, min int, loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, hour, min, 0, 0, loc)
+}
+
+func (d Date) String() string {
+	return d.utc().Format("2006-01-02")
+}
+
+// Reschedule moves t to another date, keeping its clock time in its
+// location.
+func Reschedule(t time.Time, to Date) time.Time {
+	return time.Date(to.Year, to.Month, to.Day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// StartOfWeek returns the first day of the week containing d, for weeks
+// starting on weekStart.
+func StartOfWeek(d Date, weekStart time.Weekday) Date {
+	offset := (int(d.Weekday()) - int(weekStart) + 7) % 7
+	return d.AddDays(-offset)
+}
+
+// Week returns the seven days of the week containing d.
+func Week(d Date, weekStart time.Weekday) []Date {
+	start := StartOfWeek(d, weekStart)
+	days := make([]Date, 7)
+	for i := range days {
+		days[i] = start.AddDays(i)
+	}
+	return days
+}
+
+// MonthGrid returns the weeks covering a month, as shown by a month
+// calendar: the first week holds the 1st and the last the last day, with
+// days of the neighboring months filling them out.
+func MonthGrid(year int, month time.Month, weekStart time.Weekday) [][]Date {
+	first := Date{year, month, 1}
+	last := first.AddMonths(1).AddDays(-1)
+	var weeks [][]Date
+	for day := StartOfWeek(first, weekStart); !last.Before(day); day = day.AddDays(7) {
+		weeks = append(weeks, Week(day, weekStart))
+	}
+	return weeks
+}
+
+// Regions whose weeks start on a day other than Monday, from the Unicode
+// CLDR week data.
+var (
+	sundayRegions = map[string]bool{
+		"AG": true, "AS": true, "BD": true, "BR": true, "BS": true, "BT": true, "BW": true, "BZ": true,
+		"CA": true, "CN": true, "CO": true, "DM": true, "DO": true, "ET": true, "GT": true, "GU": true,
+		"HK": true, "HN": true, "ID": true, "IL": true, "IN": true, "JM": true, "JP": true, "KE": true,
+		"KH": true, "KR": true, "LA": true, "MH": true, "MM": true, "MO": true, "MT": true, "MX": true,
+		"MZ": true, "NI": true, "NP": true, "PA": true, "PE": true, "PH": true, "PK": true, "PR": true,
+		"PT": true, "PY": true, "SA": true, "SG": true, "SV": true, "TH": true, "TT": true, "TW": true,
+		"UM": true, "US": true, "VE": true, "VI": true, "WS": true, "YE": true, "ZA": true, "ZW": true,
+	}
+	saturdayRegions = map[string]bool{
+		"AE": true, "AF": true, "BH": true, "DJ": true, "DZ": true, "EG": true, "IQ": true, "IR": true,
+		"JO": true, "KW": true, "LY": true, "OM": true, "QA": true, "SD": true, "SY": true,
+	}
+)
+
+// WeekStart returns the day weeks customarily start on in a locale such
+// as "en_US.UTF-8" or "de-DE". Locales without a known region start on
+// Monday, as ISO 8601 weeks do.
+func WeekStart(locale string) time.Weekday {
+	// Drop the encoding and modifier, as in "ja_JP.UTF-8@calendar"
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	parts := strings.FieldsFunc(locale, func(r rune) bool { return r == '_' || r == '-' })
+	for _, part := range parts[min(1, len(parts)):] {
+		region := strings.ToUpper(part)
+		if len(region) != 2 {
+			continue // A script such as "Hant"
+		}
+		switch {
+		case sundayRegions[region]:
+			return time.Sunday
+		case saturdayRegions[region]:
+			return time.Saturday
+		}
+		return time.Monday
+	}
+	return time.Monday
+}
+
+// LocaleFromEnv returns the locale dates are formatted for, from the
+// LC_ALL, LC_TIME and LANG environment variables in that order.
+func LocaleFromEnv(getenv func(string) string) string {
+	for _, name := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		if locale := getenv(name); locale != "" {
+			return locale
+		}
+	}
+	return ""
+}
+
+// ParseWeekday parses an English weekday name such as "sunday" or "Mon".
+func ParseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) < 3 {
+		return 0, false
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if name := strings.ToLower(day.String()); strings.HasPrefix(name, s) {
+			return day, true
+		}
+	}
+	return 0, false
+}
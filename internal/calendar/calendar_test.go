@@ -0,0 +1,174 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateArithmetic(t *testing.T) {
+	d := Date{2024, time.February, 28}
+	if got := d.AddDays(1); got != (Date{2024, time.February, 29}) {
+		t.Errorf("Expected the leap day, got %v", got)
+	}
+	if got := d.AddDays(2); got != (Date{2024, time.March, 1}) {
+		t.Errorf("Expected March 1, got %v", got)
+	}
+	if got := (Date{2024, time.January, 1}).AddDays(-1); got != (Date{2023, time.December, 31}) {
+		t.Errorf("Expected the end of the previous year, got %v", got)
+	}
+
+	tests := []struct {
+		d    Date
+		n    int
+		want Date
+	}{
+		{Date{2024, time.January, 31}, 1, Date{2024, time.February, 29}},
+		{Date{2023, time.January, 31}, 1, Date{2023, time.February, 28}},
+		{Date{2024, time.March, 31}, -1, Date{2024, time.February, 29}},
+		{Date{2024, time.December, 15}, 1, Date{2025, time.January, 15}},
+		{Date{2024, time.January, 15}, -13, Date{2022, time.December, 15}},
+	}
+	for _, tt := range tests {
+		if got := tt.d.AddMonths(tt.n); got != tt.want {
+			t.Errorf("Expected %v plus %d months to be %v, got %v", tt.d, tt.n, tt.want, got)
+		}
+	}
+
+	if n := (Date{2024, time.March, 1}).DaysUntil(Date{2024, time.April, 1}); n != 31 {
+		t.Errorf("Expected 31 days in March, got %d", n)
+	}
+	if s := (Date{2024, time.March, 5}).String(); s != "2024-03-05" {
+		t.Errorf("Expected ISO format, got %q", s)
+	}
+}
+
+func TestDateAcrossDaylightSaving(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Time zone data not available: %v", err)
+	}
+
+	// March 10, 2024 had 23 hours there, so stepping 24 hours from late on
+	// March 9 skips a day, where stepping a date does not
+	late := Date{2024, time.March, 9}.At(23, 30, loc)
+	if got := DateOf(late.Add(24 * time.Hour)); got != (Date{2024, time.March, 11}) {
+		t.Fatalf("Expected 24 hours to skip March 10, got %v", got)
+	}
+	if got := DateOf(Reschedule(late, DateOf(late).AddDays(1))); got != (Date{2024, time.March, 10}) {
+		t.Errorf("Expected the next day to be March 10, got %v", got)
+	}
+
+	// Rescheduling keeps the clock time across the change
+	before := Date{2024, time.March, 8}.At(9, 15, loc)
+	after := Reschedule(before, Date{2024, time.March, 11})
+	if after.Hour() != 9 || after.Minute() != 15 || DateOf(after) != (Date{2024, time.March, 11}) {
+		t.Errorf("Expected 9:15 on March 11, got %v", after)
+	}
+	if d := after.Sub(before); d != 3*24*time.Hour-time.Hour {
+		t.Errorf("Expected three days less the skipped hour, got %v", d)
+	}
+}
+
+func TestWeeks(t *testing.T) {
+	wed := Date{2024, time.March, 13}
+	if got := StartOfWeek(wed, time.Monday); got != (Date{2024, time.March, 11}) {
+		t.Errorf("Expected Monday March 11, got %v", got)
+	}
+	if got := StartOfWeek(wed, time.Sunday); got != (Date{2024, time.March, 10}) {
+		t.Errorf("Expected Sunday March 10, got %v", got)
+	}
+	if got := StartOfWeek(wed, time.Saturday); got != (Date{2024, time.March, 9}) {
+		t.Errorf("Expected Saturday March 9, got %v", got)
+	}
+	if got := StartOfWeek(Date{2024, time.March, 10}, time.Sunday); got != (Date{2024, time.March, 10}) {
+		t.Errorf("Expected a Sunday to start its own week, got %v", got)
+	}
+
+	week := Week(Date{2024, time.December, 31}, time.Monday)
+	if len(week) != 7 || week[0] != (Date{2024, time.December, 30}) || week[6] != (Date{2025, time.January, 5}) {
+		t.Errorf("Expected the week across the new year, got %v", week)
+	}
+}
+
+func TestMonthGrid(t *testing.T) {
+	tests := []struct {
+		year      int
+		month     time.Month
+		weekStart time.Weekday
+		weeks     int
+		first     Date
+	}{
+		// March 2024 starts on a Friday and ends on a Sunday
+		{2024, time.March, time.Monday, 5, Date{2024, time.February, 26}},
+		{2024, time.March, time.Sunday, 6, Date{2024, time.February, 25}},
+		// February 2026 fills exactly four weeks starting on Sunday
+		{2026, time.February, time.Sunday, 4, Date{2026, time.February, 1}},
+		{2026, time.February, time.Monday, 5, Date{2026, time.January, 26}},
+	}
+	for _, tt := range tests {
+		grid := MonthGrid(tt.year, tt.month, tt.weekStart)
+		if len(grid) != tt.weeks || grid[0][0] != tt.first {
+			t.Errorf("%v %d from %v: expected %d weeks from %v, got %d from %v",
+				tt.month, tt.year, tt.weekStart, tt.weeks, tt.first, len(grid), grid[0][0])
+			continue
+		}
+		for _, week := range grid {
+			if week[0].Weekday() != tt.weekStart {
+				t.Errorf("Expected weeks to start on %v, got %v", tt.weekStart, week[0])
+			}
+		}
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   time.Weekday
+	}{
+		{"en_US.UTF-8", time.Sunday},
+		{"ja_JP.UTF-8", time.Sunday},
+		{"en_GB.UTF-8", time.Monday},
+		{"de-DE", time.Monday},
+		{"ar_EG.UTF-8", time.Saturday},
+		{"zh_Hant_TW", time.Sunday},
+		{"fr_FR@euro", time.Monday},
+		{"C", time.Monday},
+		{"", time.Monday},
+	}
+	for _, tt := range tests {
+		if got := WeekStart(tt.locale); got != tt.want {
+			t.Errorf("Expected weeks in %q to start on %v, got %v", tt.locale, tt.want, got)
+		}
+	}
+}
+
+func TestLocaleFromEnv(t *testing.T) {
+	env := map[string]string{"LANG": "en_US.UTF-8", "LC_TIME": "de_DE.UTF-8"}
+	if got := LocaleFromEnv(func(name string) string { return env[name] }); got != "de_DE.UTF-8" {
+		t.Errorf("Expected LC_TIME to win over LANG, got %q", got)
+	}
+	env["LC_ALL"] = "ja_JP.UTF-8"
+	if got := LocaleFromEnv(func(name string) string { return env[name] }); got != "ja_JP.UTF-8" {
+		t.Errorf("Expected LC_ALL to win, got %q", got)
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Weekday
+		ok   bool
+	}{
+		{"sunday", time.Sunday, true},
+		{"Monday", time.Monday, true},
+		{"sat", time.Saturday, true},
+		{"s", 0, false},
+		{"someday", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseWeekday(tt.s)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Expected %q to parse as %v, %v, got %v, %v", tt.s, tt.want, tt.ok, got, ok)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Package fuzzy ranks strings against a typed pattern. A string matches
+// when every rune of the pattern appears in it in order, ignoring case.
+// Matches score higher when the runes are consecutive, start words, or
+// appear early in the string.
+package fuzzy
+
+import (
+	"math"
+	"sort"
+	"unicode"
+)
+
+const (
+	scoreMatch       = 16
+	bonusWordStart   = 8
+	bonusConsecutive = 12
+	penaltyGap       = 1
+	penaltyLeading   = 1
+	maxLeading       = 8
+)
+
+// Result is a matched candidate. Positions are the rune indexes of the
+// matched runes, for highlighting.
+type Result struct {
+	Index     int
+	Score     int
+	Positions []int
+}
+
+// Match scores s against pattern. It reports false when s does not contain
+// the pattern as a subsequence. An empty pattern matches with score 0.
+func Match(pattern, s string) (Result, bool) {
+	p := []rune(pattern)
+	runes := []rune(s)
+	if len(p) == 0 {
+		return Result{}, true
+	}
+	if len(p) > len(runes) {
+		return Result{}, false
+	}
+
+	const none = math.MinInt32
+	n, m := len(p), len(runes)
+	// score[i][j] is the best score with p[i] matched at runes[j], and
+	// from[i][j] the position of p[i-1] in that match.
+	score := make([][]int, n)
+	from := make([][]int, n)
+	for i := range score {
+		score[i] = make([]int, m)
+		from[i] = make([]int, m)
+		for j := range score[i] {
+			score[i][j] = none
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		// Best score of p[i-1] matched at some k <= j-2, less the gap to j
+		gapped, gappedFrom := none, -1
+		for j := 0; j < m; j++ {
+			if i > 0 && j >= 2 {
+				if gapped != none {
+					gapped -= penaltyGap
+				}
+				if prev := score[i-1][j-2]; prev != none && prev-penaltyGap > gapped {
+					gapped, gappedFrom = prev-penaltyGap, j-2
+				}
+			}
+			if unicode.ToLower(p[i]) != unicode.ToLower(runes[j]) {
+				continue
+			}
+
+			base := scoreMatch
+			if isWordStart(runes, j) {
+				base += bonusWordStart
+			}
+			if i == 0 {
+				score[i][j] = base - min(j, maxLeading)*penaltyLeading
+				continue
+			}
+			if j > 0 && score[i-1][j-1] != none {
+				score[i][j] = score[i-1][j-1] + base + bonusConsecutive
+				from[i][j] = j - 1
+			}
+			if gapped != none && gapped+base > score[i][j] {
+				score[i][j] = gapped + base
+				from[i][j] = gappedFrom
+			}
+		}
+	}
+
+	best, end := none, -1
+	for j := 0; j < m; j++ {
+		if score[n-1][j] > best {
+			best, end = score[n-1][j], j
+		}
+	}
+	if end < 0 {
+		return Result{}, false
+	}
+
+	positions := make([]int, n)
+	for i := n - 1; i >= 0; i-- {
+		positions[i] = end
+		end = from[i][end]
+	}
+	return Result{Score: best, Positions: positions}, true
+}
+
+// Rank matches every candidate against pattern and returns the matches,
+// best first. Ties keep the original order.
+func Rank(pattern string, candidates []string) []Result {
+	var results []Result
+	for i, c := range candidates {
+		if r, ok := Match(pattern, c); ok {
+			r.Index = i
+			results = append(results, r)
+		}
+	}
+	sort.SliceStable(results, func(a, b int) bool {
+		return results[a].Score > results[b].Score
+	})
+	return results
+}
+
+// isWordStart reports whether runes[j] begins a word: it follows a
+// separator or is an upper-case letter after a lower-case one.
+func isWordStart(runes []rune, j int) bool {
+	if j == 0 {
+		return true
+	}
+	prev, cur := runes[j-1], runes[j]
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return unicode.IsLetter(cur) || unicode.IsDigit(cur)
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
@@ -0,0 +1,88 @@
+package fuzzy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		s         string
+		ok        bool
+		positions []int
+	}{
+		{"", "anything", true, nil},
+		{"abc", "abc", true, []int{0, 1, 2}},
+		{"ABC", "aXbXc", true, []int{0, 2, 4}},
+		{"sa", "Show active todos", true, []int{0, 5}},
+		{"todo", "today todo", true, []int{6, 7, 8, 9}},
+		{"cba", "abc", false, nil},
+		{"abcd", "abc", false, nil},
+		{"é", "Café", true, []int{3}},
+	}
+
+	for _, tt := range tests {
+		r, ok := Match(tt.pattern, tt.s)
+		if ok != tt.ok {
+			t.Errorf("Match(%q, %q): expected ok=%v, got %v", tt.pattern, tt.s, tt.ok, ok)
+			continue
+		}
+		if ok && !reflect.DeepEqual(r.Positions, tt.positions) {
+			t.Errorf("Match(%q, %q): expected positions %v, got %v", tt.pattern, tt.s, tt.positions, r.Positions)
+		}
+	}
+}
+
+func TestMatchPrefersBetterMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		better  string
+		worse   string
+	}{
+		{"todo", "todo list", "the old door"},      // consecutive beats scattered
+		{"sa", "Show active", "Search"},            // word starts beat inner letters
+		{"fi", "filter", "a big file"},             // earlier beats later
+		{"nt", "NewTodo", "renting"},               // camel case counts as a word start
+		{"milk", "buy milk", "make it look kinky"}, // fewer gaps
+	}
+
+	for _, tt := range tests {
+		better, ok := Match(tt.pattern, tt.better)
+		if !ok {
+			t.Fatalf("Match(%q, %q) did not match", tt.pattern, tt.better)
+		}
+		worse, ok := Match(tt.pattern, tt.worse)
+		if !ok {
+			t.Fatalf("Match(%q, %q) did not match", tt.pattern, tt.worse)
+		}
+		if better.Score <= worse.Score {
+			t.Errorf("%q: expected %q (%d) to score above %q (%d)", tt.pattern, tt.better, better.Score, tt.worse, worse.Score)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	candidates := []string{"Search todos", "Show active todos", "Show all todos", "New todo", "Zebra"}
+
+	results := Rank("sa", candidates)
+	var got []string
+	for _, r := range results {
+		got = append(got, candidates[r.Index])
+	}
+	want := []string{"Show active todos", "Show all todos", "Search todos"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// An empty pattern keeps every candidate in order
+	results = Rank("", candidates)
+	for i, r := range results {
+		if r.Index != i {
+			t.Errorf("Expected candidate %d at position %d, got %d", i, i, r.Index)
+		}
+	}
+	if len(results) != len(candidates) {
+		t.Errorf("Expected %d results, got %d", len(candidates), len(results))
+	}
+}
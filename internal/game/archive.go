@@ -0,0 +1,113 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// The archive holds completed todos that have been cleared from the list.
+
+func (g *Game) newArchiveView() *collectionView {
+	v := newCollectionView(g.archive)
+	v.title = "Archive"
+	v.emptyMessage = "No archived todos. Completed todos go here when you clear them."
+	v.deleteHint = "move to trash"
+	v.detail = func(todo *models.Todo) string {
+		if todo.ArchivedAt == nil {
+			return ""
+		}
+		return "Archived " + todo.ArchivedAt.Format("2006-01-02")
+	}
+	v.onRestore = g.restoreArchived
+	v.onDelete = g.trashArchived
+	return v
+}
+
+// archiveCompleted moves the todos completed at or before cutoff to the
+// archive. The archive is saved first, and the todos stay in the list if
+// that fails, so that a failure never loses a todo.
+func (g *Game) archiveCompleted(cutoff time.Time) (*undoStep, error) {
+	if err := g.archive.load(); err != nil {
+		return nil, err
+	}
+
+	archived := g.todos.CompletedBy(cutoff)
+	if len(archived) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(archived))
+	now := g.clock.Now()
+	for i := range archived {
+		ids[i] = archived[i].ID
+		archived[i].ArchivedAt = &now
+	}
+	step := g.captureUndo(ids...)
+	if err := g.archive.add(archived...); err != nil {
+		return nil, err
+	}
+
+	g.todos.RemoveTodos(ids...)
+	step.moved, step.movedTo = ids, g.archive
+	return step, g.saveTodos()
+}
+
+// clearCompleted archives every completed todo, as one undoable step.
+func (g *Game) clearCompleted() {
+	step, err := g.archiveCompleted(g.clock.Now())
+	g.error = ""
+	if err != nil {
+		g.error = fmt.Sprintf("Failed to archive: %v", err)
+	}
+	if step != nil {
+		g.pushUndo(step)
+	}
+	g.updateTodoItems()
+}
+
+// autoArchive archives the todos completed longer ago than the number of
+// days in the settings.
+func (g *Game) autoArchive() {
+	days := g.settings.AutoArchiveDays
+	if days <= 0 {
+		return
+	}
+	if _, err := g.archiveCompleted(g.clock.Now().AddDate(0, 0, -days)); err != nil {
+		g.error = fmt.Sprintf("Failed to archive: %v", err)
+	}
+}
+
+// restoreArchived moves an archived todo back to the end of the todo list.
+func (g *Game) restoreArchived(id string) {
+	g.restoreFrom(g.archive, id, func(todo *models.Todo) {
+		todo.ArchivedAt = nil
+	})
+	g.uiManager.archive.refresh()
+}
+
+// trashArchived moves an archived todo to the trash. The trash is saved
+// first so that a failure leaves the todo in the archive.
+func (g *Game) trashArchived(id string) {
+	var todos []models.Todo
+	for _, todo := range g.archive.todos {
+		if todo.ID == id {
+			todos = append(todos, todo)
+		}
+	}
+	g.error = ""
+	err := g.trashTodos(todos)
+	if err == nil {
+		_, err = g.archive.remove(id)
+	}
+	if err != nil {
+		g.error = fmt.Sprintf("Failed to save: %v", err)
+	}
+	g.uiManager.archive.refresh()
+}
+
+func (g *Game) openArchive() {
+	if err := g.uiManager.archive.open(); err != nil {
+		g.error = fmt.Sprintf("Failed to load archive: %v", err)
+	}
+}
@@ -0,0 +1,412 @@
+package game
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/models"
+	"github.com/lapis2411/todo/internal/storage"
+	"github.com/lapis2411/todo/internal/ui"
+)
+
+const (
+	boardCardHeight    = 44
+	boardCardGap       = 6
+	boardHeaderHeight  = 30
+	boardColumnGap     = 10
+	boardDragThreshold = 4 // Pixels the cursor moves before a press drags
+
+	viewBoard = "board"
+)
+
+// boardView shows the todos in the list as cards in columns by status,
+// instead of the list. It shares the list's todos, filter, search and
+// cursor. Dragging a card to another column, or Shift+Left/Right, changes
+// its status.
+type boardView struct {
+	g       *Game
+	visible bool
+	rect    image.Rectangle
+	columns []models.BoardColumn
+	scroll  []int // Scroll offset of each column in pixels
+	drag    *boardDrag
+}
+
+// boardDrag is a card being dragged with the mouse.
+type boardDrag struct {
+	id     string
+	start  image.Point
+	grab   image.Point // Cursor position within the card
+	cursor image.Point
+	moved  bool
+}
+
+func (g *Game) newBoardView() *boardView {
+	b := &boardView{g: g, visible: g.settings.View == viewBoard}
+	b.columns = models.DefaultBoardColumns
+	if columns := g.settings.BoardColumns; len(columns) > 0 {
+		if err := models.ValidateBoardColumns(columns); err != nil {
+			g.error = fmt.Sprintf("Invalid board columns: %v", err)
+		} else {
+			b.columns = columns
+		}
+	}
+	b.scroll = make([]int, len(b.columns))
+	return b
+}
+
+// The board takes a single tab stop, like the list; the arrow keys then
+// move the cursor between cards.
+
+func (b *boardView) SetFocus(focused bool) {
+	if focused && b.g.selectedIndex() < 0 {
+		b.g.selectTodo(0)
+	}
+}
+
+func (b *boardView) Contains(x, y int) bool {
+	return image.Pt(x, y).In(b.rect)
+}
+
+// Bounds is the visible part of the selected card, so the focus ring
+// follows the cursor.
+func (b *boardView) Bounds() image.Rectangle {
+	col, pos := b.position(b.g.uiManager.selectedID)
+	if col < 0 {
+		return b.rect
+	}
+	return b.cardRect(col, pos).Intersect(b.bodyRect(col))
+}
+
+// cards returns the indexes in the list of the todos in each column, in
+// list order.
+func (b *boardView) cards() [][]int {
+	cards := make([][]int, len(b.columns))
+	for i := range b.g.uiManager.todos {
+		col := models.ColumnOf(b.columns, &b.g.uiManager.todos[i])
+		cards[col] = append(cards[col], i)
+	}
+	return cards
+}
+
+// position returns the column of a todo and its place in the column, or
+// -1 when it is not on the board.
+func (b *boardView) position(id string) (col, pos int) {
+	for col, cards := range b.cards() {
+		for pos, i := range cards {
+			if b.g.uiManager.todos[i].ID == id {
+				return col, pos
+			}
+		}
+	}
+	return -1, -1
+}
+
+func (b *boardView) columnRect(col int) image.Rectangle {
+	n := len(b.columns)
+	width := (b.rect.Dx() - boardColumnGap*(n-1)) / n
+	x := b.rect.Min.X + col*(width+boardColumnGap)
+	return image.Rect(x, b.rect.Min.Y, x+width, b.rect.Max.Y)
+}
+
+// bodyRect is the part of a column below its header, where cards scroll.
+func (b *boardView) bodyRect(col int) image.Rectangle {
+	r := b.columnRect(col)
+	r.Min.Y += boardHeaderHeight
+	return r
+}
+
+func (b *boardView) cardRect(col, pos int) image.Rectangle {
+	body := b.bodyRect(col)
+	y := body.Min.Y + boardCardGap + pos*(boardCardHeight+boardCardGap) - b.scroll[col]
+	return image.Rect(body.Min.X+boardCardGap, y, body.Max.X-boardCardGap, y+boardCardHeight)
+}
+
+// columnAt returns the column under a point.
+func (b *boardView) columnAt(x, y int) (int, bool) {
+	for col := range b.columns {
+		if image.Pt(x, y).In(b.columnRect(col)) {
+			return col, true
+		}
+	}
+	return -1, false
+}
+
+// cardAt returns the index in the list of the card under a point.
+func (b *boardView) cardAt(x, y int) (int, bool) {
+	col, ok := b.columnAt(x, y)
+	if !ok || !image.Pt(x, y).In(b.bodyRect(col)) {
+		return -1, false
+	}
+	for pos, i := range b.cards()[col] {
+		if image.Pt(x, y).In(b.cardRect(col, pos)) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// scrollBy scrolls a column, keeping its cards in view.
+func (b *boardView) scrollBy(col, dy int) {
+	count := len(b.cards()[col])
+	content := boardCardGap + count*(boardCardHeight+boardCardGap)
+	b.scroll[col] = max(0, min(b.scroll[col]+dy, content-b.bodyRect(col).Dy()))
+}
+
+// scrollToCard scrolls a column so that a card is fully in view.
+func (b *boardView) scrollToCard(col, pos int) {
+	card, body := b.cardRect(col, pos), b.bodyRect(col)
+	if card.Min.Y < body.Min.Y {
+		b.scroll[col] -= body.Min.Y - card.Min.Y + boardCardGap
+	} else if card.Max.Y > body.Max.Y {
+		b.scroll[col] += card.Max.Y - body.Max.Y + boardCardGap
+	}
+	b.scroll[col] = max(b.scroll[col], 0)
+}
+
+// selectCard moves the cursor to a card and scrolls it into view.
+func (b *boardView) selectCard(col, pos int) {
+	cards := b.cards()[col]
+	if pos < 0 || pos >= len(cards) {
+		return
+	}
+	b.g.selectTodo(cards[pos])
+	b.scrollToCard(col, pos)
+}
+
+// press starts dragging the card under the cursor, selecting it.
+func (b *boardView) press(x, y int) {
+	i, ok := b.cardAt(x, y)
+	if !ok {
+		return
+	}
+	b.g.selectTodo(i)
+	b.g.uiManager.focus.Focus(b)
+	id := b.g.uiManager.todos[i].ID
+	col, pos := b.position(id)
+	card := b.cardRect(col, pos)
+	b.drag = &boardDrag{
+		id:     id,
+		start:  image.Pt(x, y),
+		grab:   image.Pt(x-card.Min.X, y-card.Min.Y),
+		cursor: image.Pt(x, y),
+	}
+}
+
+func (b *boardView) dragTo(x, y int) {
+	if b.drag == nil {
+		return
+	}
+	b.drag.cursor = image.Pt(x, y)
+	if d := b.drag.cursor.Sub(b.drag.start); max(d.X, -d.X, d.Y, -d.Y) > boardDragThreshold {
+		b.drag.moved = true
+	}
+}
+
+// release drops a dragged card into the column under the cursor.
+func (b *boardView) release(x, y int) {
+	drag := b.drag
+	b.drag = nil
+	if drag == nil || !drag.moved {
+		return
+	}
+	if col, ok := b.columnAt(x, y); ok {
+		b.g.moveToColumn(drag.id, col)
+	}
+}
+
+// update handles the mouse over the board and, while it has focus, the
+// keys that move the cursor and cards.
+func (b *boardView) update(in ui.InputSource, kb *ui.Keyboard) {
+	x, y := ui.CursorPosition(in)
+	switch {
+	case in.IsMouseButtonJustPressed(ebiten.MouseButtonLeft):
+		b.press(x, y)
+	case in.IsMouseButtonJustReleased(ebiten.MouseButtonLeft):
+		b.release(x, y)
+	case in.IsMouseButtonPressed(ebiten.MouseButtonLeft):
+		b.dragTo(x, y)
+	}
+	if _, dy := in.Wheel(); dy != 0 {
+		if col, ok := b.columnAt(x, y); ok {
+			b.scrollBy(col, -int(dy*20))
+		}
+	}
+	b.handleKeys(kb)
+}
+
+// handleKeys moves the cursor with the arrow keys and the selected card
+// with Shift+Left/Right. Space, Enter and Delete complete, show and
+// delete the selected card.
+func (b *boardView) handleKeys(kb *ui.Keyboard) {
+	m := b.g.uiManager
+	if !m.focus.IsFocused(b) || m.list.Count() == 0 {
+		return
+	}
+	col, pos := b.position(m.selectedID)
+	if col < 0 {
+		return
+	}
+	cards := b.cards()
+	switch {
+	case kb.IsShiftPressed() && kb.IsTriggered(ebiten.KeyArrowLeft):
+		b.moveSelected(col - 1)
+	case kb.IsShiftPressed() && kb.IsTriggered(ebiten.KeyArrowRight):
+		b.moveSelected(col + 1)
+	case kb.IsTriggered(ebiten.KeyArrowUp):
+		b.selectCard(col, max(pos-1, 0))
+	case kb.IsTriggered(ebiten.KeyArrowDown):
+		b.selectCard(col, min(pos+1, len(cards[col])-1))
+	case kb.IsTriggered(ebiten.KeyArrowLeft):
+		for c := col - 1; c >= 0; c-- {
+			if len(cards[c]) > 0 {
+				b.selectCard(c, min(pos, len(cards[c])-1))
+				break
+			}
+		}
+	case kb.IsTriggered(ebiten.KeyArrowRight):
+		for c := col + 1; c < len(cards); c++ {
+			if len(cards[c]) > 0 {
+				b.selectCard(c, min(pos, len(cards[c])-1))
+				break
+			}
+		}
+	case kb.IsJustPressed(ebiten.KeySpace):
+		b.g.toggleTodo(m.selectedID)
+		b.followSelected()
+	case kb.IsJustPressed(ebiten.KeyEnter):
+		b.g.showDetails()
+	case kb.IsJustPressed(ebiten.KeyDelete):
+		b.g.deleteTodo(m.selectedID)
+		// Keep a card selected so repeated deletes walk down the column
+		if rest := b.cards()[col]; len(rest) > 0 {
+			b.selectCard(col, min(pos, len(rest)-1))
+		}
+	}
+}
+
+// moveSelected moves the selected card to another column.
+func (b *boardView) moveSelected(col int) {
+	if col < 0 || col >= len(b.columns) {
+		return
+	}
+	b.g.moveToColumn(b.g.uiManager.selectedID, col)
+	b.followSelected()
+}
+
+// followSelected scrolls to the selected card after it changed column, or
+// selects the first card when it has left the filtered list.
+func (b *boardView) followSelected() {
+	if col, pos := b.position(b.g.uiManager.selectedID); col >= 0 {
+		b.scrollToCard(col, pos)
+	} else if b.g.uiManager.list.Count() > 0 {
+		b.g.selectTodo(0)
+	}
+}
+
+func (b *boardView) draw(screen *ebiten.Image) {
+	theme := ui.CurrentTheme()
+	m := b.g.uiManager
+
+	var dragged *ui.BoardCard
+	target := -1
+	if b.drag != nil && b.drag.moved {
+		target, _ = b.columnAt(b.drag.cursor.X, b.drag.cursor.Y)
+	}
+	for col, cards := range b.cards() {
+		r := b.columnRect(col)
+		borderColor := theme.Border
+		if col == target {
+			borderColor = theme.Accent
+		}
+		ui.DrawRect(screen, float64(r.Min.X), float64(r.Min.Y), float64(r.Dx()), float64(r.Dy()), borderColor)
+		ui.DrawRect(screen, float64(r.Min.X+1), float64(r.Min.Y+1), float64(r.Dx()-2), float64(r.Dy()-2), theme.Background)
+
+		title := fmt.Sprintf("%s (%d)", b.columns[col].Title, len(cards))
+		ui.DrawText(screen, title, r.Min.X+boardCardGap+4, r.Min.Y+(boardHeaderHeight+10)/2, theme.Text)
+		ui.DrawRect(screen, float64(r.Min.X), float64(r.Min.Y+boardHeaderHeight-1), float64(r.Dx()), 1, theme.Border)
+
+		body := b.bodyRect(col)
+		if body.Empty() {
+			continue
+		}
+		if len(cards) == 0 {
+			ui.DrawText(screen, "No todos", body.Min.X+boardCardGap+4, body.Min.Y+24, theme.TextMuted)
+			continue
+		}
+
+		// Only the cards in view are drawn, clipped to the column
+		clip := ui.Clip(screen, body)
+		for pos, i := range cards {
+			rect := b.cardRect(col, pos)
+			if rect.Max.Y < body.Min.Y || rect.Min.Y > body.Max.Y {
+				continue
+			}
+			card := ui.NewBoardCard(&m.todos[i], rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy())
+			card.Selected = m.todos[i].ID == m.selectedID
+			if b.drag != nil && b.drag.moved && b.drag.id == m.todos[i].ID {
+				// Lift the dragged card out of its column
+				card.Dragging = true
+				card.X, card.Y = b.drag.cursor.X-b.drag.grab.X, b.drag.cursor.Y-b.drag.grab.Y
+				dragged = card
+				continue
+			}
+			card.Draw(clip)
+		}
+	}
+	if dragged != nil {
+		dragged.Draw(screen)
+	}
+}
+
+// moveToColumn gives a todo the status of a board column, completing or
+// reopening it to match the column.
+func (g *Game) moveToColumn(id string, col int) {
+	columns := g.uiManager.board.columns
+	todo := g.todos.FindTodo(id)
+	if todo == nil || col < 0 || col >= len(columns) || models.ColumnOf(columns, todo) == col {
+		return
+	}
+	step := g.captureUndo(id)
+	todo.SetStatus(columns[col].Status, columns[col].Done, g.clock.Now())
+	g.repeatCompleted(step, id)
+	g.pushUndo(step)
+	g.error = ""
+	if err := g.saveTodos(); err != nil {
+		g.error = fmt.Sprintf("Failed to save: %v", err)
+	}
+	g.updateTodoItems()
+}
+
+// setBoardVisible switches between the list and the board, remembering
+// the choice in the settings.
+func (g *Game) setBoardVisible(visible bool) {
+	m := g.uiManager
+	focused := m.focus.IsFocused(m.todoList) || m.focus.IsFocused(m.board)
+	m.board.visible = visible
+	m.board.drag = nil
+	if visible {
+		m.viewButton.Text = "List"
+		g.settings.View = viewBoard
+		// The board has no multi-select, so collapse it to the cursor
+		g.clearSelection()
+	} else {
+		m.viewButton.Text = "Board"
+		g.settings.View = ""
+	}
+	if err := storage.SaveSettings(g.settingsPath, g.settings); err != nil {
+		g.error = fmt.Sprintf("Failed to save settings: %v", err)
+	}
+
+	// Keep focus on the todos when it was there
+	g.updateFocusOrder()
+	if focused && m.list.Count() > 0 {
+		if visible {
+			m.focus.Focus(m.board)
+		} else {
+			m.focus.Focus(m.todoList)
+		}
+	}
+}
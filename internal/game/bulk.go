@@ -0,0 +1,209 @@
+package game
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/layout"
+	"github.com/lapis2411/todo/internal/models"
+	"github.com/lapis2411/todo/internal/ui"
+)
+
+// bulkBar is the toolbar shown under the list while several rows are
+// selected. Its actions apply to the whole selection.
+type bulkBar struct {
+	visible         bool
+	labelRect       image.Rectangle // "N selected"
+	completeButton  *ui.Button
+	reopenButton    *ui.Button
+	deleteButton    *ui.Button
+	tagBox          *ui.TextBox
+	tagButton       *ui.Button
+	priorityButtons []*ui.Button
+}
+
+// bulkPriorities are the priorities offered by the toolbar, in button order.
+var bulkPriorities = []models.Priority{models.PriorityHigh, models.PriorityMedium, models.PriorityLow, models.PriorityNone}
+
+func (g *Game) newBulkBar() *bulkBar {
+	bar := &bulkBar{}
+	bar.completeButton = ui.NewButton(0, 0, 0, 0, "Complete", func() {
+		g.bulkSetCompleted(true)
+	})
+	bar.reopenButton = ui.NewButton(0, 0, 0, 0, "Reopen", func() {
+		g.bulkSetCompleted(false)
+	})
+	bar.reopenButton.SetVariant(ui.ButtonSecondary)
+	bar.deleteButton = ui.NewButton(0, 0, 0, 0, "Delete", func() {
+		g.bulkDelete()
+	})
+	bar.deleteButton.SetVariant(ui.ButtonDanger)
+	bar.tagBox = ui.NewTextBox(0, 0, 0, 0, "Tag...")
+	bar.tagButton = ui.NewButton(0, 0, 0, 0, "Tag", func() {
+		g.bulkAddTag(bar.tagBox.GetText())
+	})
+	for _, priority := range bulkPriorities {
+		label := priority.String()
+		if priority == models.PriorityNone {
+			label = "No priority"
+		}
+		button := ui.NewButton(0, 0, 0, 0, label, func(p models.Priority) func() {
+			return func() { g.bulkSetPriority(p) }
+		}(priority))
+		button.SetVariant(ui.ButtonSecondary)
+		bar.priorityButtons = append(bar.priorityButtons, button)
+	}
+	return bar
+}
+
+// node returns the layout of the toolbar, which takes no space while hidden.
+func (bar *bulkBar) node() layout.Node {
+	row := layout.Row(
+		layout.Fixed(100, layout.Rect(&bar.labelRect)),
+		layout.Fixed(80, layout.Leaf(80, 30, bar.completeButton.SetBounds)),
+		layout.Fixed(70, layout.Leaf(70, 30, bar.reopenButton.SetBounds)),
+		layout.Fixed(60, layout.Leaf(60, 30, bar.deleteButton.SetBounds)),
+		layout.Flex(1, layout.Leaf(0, 30, bar.tagBox.SetBounds)),
+		layout.Fixed(40, layout.Leaf(40, 30, bar.tagButton.SetBounds)),
+	)
+	for _, button := range bar.priorityButtons {
+		width := len(button.Text)*7 + 16
+		row.Children = append(row.Children, layout.Fixed(width, layout.Leaf(width, 30, button.SetBounds)))
+	}
+	row.Padding = layout.Insets{Top: 8, Bottom: 8}
+	row.Gap = 5
+	return layout.When(func() bool { return bar.visible }, row)
+}
+
+// widgets returns the toolbar's focusable widgets in tab order.
+func (bar *bulkBar) widgets() []ui.Focusable {
+	if !bar.visible {
+		return nil
+	}
+	widgets := []ui.Focusable{bar.completeButton, bar.reopenButton, bar.deleteButton, bar.tagBox, bar.tagButton}
+	for _, button := range bar.priorityButtons {
+		widgets = append(widgets, button)
+	}
+	return widgets
+}
+
+func (g *Game) updateBulkBar(in ui.InputSource) {
+	bar := g.uiManager.bulkBar
+	if !bar.visible {
+		return
+	}
+	if bar.tagBox.IsEnterPressed() {
+		g.bulkAddTag(bar.tagBox.GetText())
+	}
+	bar.tagBox.Update(in)
+	for _, button := range []*ui.Button{bar.completeButton, bar.reopenButton, bar.deleteButton, bar.tagButton} {
+		button.Update(in)
+	}
+	for _, button := range bar.priorityButtons {
+		button.Update(in)
+	}
+}
+
+func (g *Game) drawBulkBar(screen *ebiten.Image) {
+	bar := g.uiManager.bulkBar
+	if !bar.visible {
+		return
+	}
+	theme := ui.CurrentTheme()
+
+	// Separate the toolbar from the rows above it
+	list := g.uiManager.list.Bounds()
+	ui.DrawRect(screen, float64(list.Min.X), float64(list.Max.Y), float64(list.Dx()), 1, theme.Border)
+
+	label := fmt.Sprintf("%d selected", len(g.uiManager.selectedIDs))
+	ui.DrawText(screen, label, bar.labelRect.Min.X, bar.labelRect.Min.Y+(bar.labelRect.Dy()+10)/2, theme.Text)
+
+	bar.completeButton.Draw(screen)
+	bar.reopenButton.Draw(screen)
+	bar.deleteButton.Draw(screen)
+	bar.tagBox.Draw(screen)
+	bar.tagButton.Draw(screen)
+	for _, button := range bar.priorityButtons {
+		button.Draw(screen)
+	}
+}
+
+// bulkUpdate applies change to each selected todo, then saves once and
+// records a single undo step. It returns how many todos changed.
+func (g *Game) bulkUpdate(change func(todo *models.Todo) bool) int {
+	ids := g.selectedTodoIDs()
+	step := g.captureUndo(ids...)
+	selected := g.uiManager.selectedIDs
+	changed := 0
+	for i := range g.todos.Todos {
+		if selected[g.todos.Todos[i].ID] && change(&g.todos.Todos[i]) {
+			changed++
+		}
+	}
+	if changed == 0 {
+		return 0
+	}
+	g.repeatCompleted(step, ids...)
+
+	g.pushUndo(step)
+	g.error = ""
+	if err := g.saveTodos(); err != nil {
+		g.error = fmt.Sprintf("Failed to save: %v", err)
+	}
+	g.updateTodoItems()
+	return changed
+}
+
+// bulkSetCompleted marks the selected todos completed or active.
+func (g *Game) bulkSetCompleted(completed bool) {
+	g.bulkUpdate(func(todo *models.Todo) bool {
+		if todo.Completed == completed {
+			return false
+		}
+		todo.Toggle(g.clock.Now())
+		return true
+	})
+}
+
+// bulkToggle completes the selection, or reopens it when every selected
+// todo is already completed, like Space does for a single row.
+func (g *Game) bulkToggle() {
+	completed := true
+	for _, todo := range g.uiManager.todos {
+		if g.uiManager.selectedIDs[todo.ID] && !todo.Completed {
+			completed = false
+			break
+		}
+	}
+	g.bulkSetCompleted(!completed)
+}
+
+func (g *Game) bulkSetPriority(priority models.Priority) {
+	g.bulkUpdate(func(todo *models.Todo) bool {
+		if todo.Priority == priority {
+			return false
+		}
+		todo.SetPriority(priority, g.clock.Now())
+		return true
+	})
+}
+
+func (g *Game) bulkAddTag(tag string) {
+	if models.NormalizeTag(tag) == "" {
+		g.error = "Tag cannot be empty"
+		return
+	}
+	g.bulkUpdate(func(todo *models.Todo) bool {
+		return todo.AddTag(tag, g.clock.Now())
+	})
+	g.uiManager.bulkBar.tagBox.Clear()
+}
+
+// bulkDelete moves the selected todos to the trash with a single save.
+func (g *Game) bulkDelete() {
+	if ids := g.selectedTodoIDs(); len(ids) > 0 {
+		g.deleteTodos(ids...)
+	}
+}
@@ -0,0 +1,444 @@
+package game
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/calendar"
+	"github.com/lapis2411/todo/internal/layout"
+	"github.com/lapis2411/todo/internal/models"
+	"github.com/lapis2411/todo/internal/ui"
+)
+
+const (
+	calendarHeaderHeight = 20 // Weekday names above the days
+	calendarDayHeight    = 18 // Day number at the top of a day
+	calendarChipHeight   = 15
+	calendarChipGap      = 2
+)
+
+type calendarMode int
+
+const (
+	calendarMonth calendarMode = iota
+	calendarWeek
+)
+
+// calendarView is the modal panel placing the todos with a due date on
+// their days, a month or a week at a time. Clicking a day filters the
+// list to it, and dragging a todo to another day reschedules it.
+type calendarView struct {
+	g         *Game
+	visible   bool
+	mode      calendarMode
+	weekStart time.Weekday
+	// cursor is the day the keys act on. The month or week shown is the
+	// one containing it.
+	cursor calendar.Date
+	drag   *calendarDrag
+
+	panelRect   image.Rectangle
+	titleRect   image.Rectangle
+	gridRect    image.Rectangle
+	prevButton  *ui.Button
+	todayButton *ui.Button
+	nextButton  *ui.Button
+	modeButton  *ui.Button
+	allButton   *ui.Button
+	closeButton *ui.Button
+}
+
+// calendarDrag is a press on a day, which drags a todo when it started on
+// one.
+type calendarDrag struct {
+	id     string
+	start  image.Point
+	cursor image.Point
+	moved  bool
+}
+
+func (g *Game) newCalendarView() *calendarView {
+	v := &calendarView{g: g, weekStart: g.weekStart()}
+	v.prevButton = ui.NewButton(0, 0, 0, 0, "<", func() { v.step(-1) })
+	v.todayButton = ui.NewButton(0, 0, 0, 0, "Today", func() { v.cursor = calendar.DateOf(g.clock.Now()) })
+	v.nextButton = ui.NewButton(0, 0, 0, 0, ">", func() { v.step(1) })
+	v.modeButton = ui.NewButton(0, 0, 0, 0, "Week", func() {
+		if v.mode == calendarMonth {
+			v.setMode(calendarWeek)
+		} else {
+			v.setMode(calendarMonth)
+		}
+	})
+	v.allButton = ui.NewButton(0, 0, 0, 0, "All dates", func() {
+		g.setDueFilter(nil)
+		v.visible = false
+	})
+	v.closeButton = ui.NewButton(0, 0, 0, 0, "Close", func() {
+		v.visible = false
+	})
+	for _, button := range v.buttons() {
+		button.SetVariant(ui.ButtonSecondary)
+	}
+	return v
+}
+
+func (v *calendarView) buttons() []*ui.Button {
+	return []*ui.Button{v.prevButton, v.todayButton, v.nextButton, v.modeButton, v.allButton, v.closeButton}
+}
+
+// weekStart returns the day weeks start on: the one in the settings, or
+// else the custom of the system locale.
+func (g *Game) weekStart() time.Weekday {
+	if g.settings.WeekStart != "" {
+		if day, ok := calendar.ParseWeekday(g.settings.WeekStart); ok {
+			return day
+		}
+		g.error = fmt.Sprintf("Unknown week start %q", g.settings.WeekStart)
+	}
+	return calendar.WeekStart(calendar.LocaleFromEnv(os.Getenv))
+}
+
+// node returns the layout of the panel inside the window.
+func (v *calendarView) node() layout.Node {
+	titleRow := layout.Row(
+		layout.Fixed(30, layout.Leaf(30, 30, v.prevButton.SetBounds)),
+		layout.Fixed(60, layout.Leaf(60, 30, v.todayButton.SetBounds)),
+		layout.Fixed(30, layout.Leaf(30, 30, v.nextButton.SetBounds)),
+		layout.Flex(1, layout.Rect(&v.titleRect)),
+		layout.Fixed(60, layout.Leaf(60, 30, v.modeButton.SetBounds)),
+		layout.Fixed(80, layout.Leaf(80, 30, v.allButton.SetBounds)),
+		layout.Fixed(70, layout.Leaf(70, 30, v.closeButton.SetBounds)),
+	)
+	titleRow.Gap = 5
+	column := layout.Column(
+		layout.Fixed(30, titleRow),
+		layout.Flex(1, layout.Rect(&v.gridRect)),
+	)
+	column.Padding = layout.Uniform(10)
+	column.Gap = 10
+	return layout.NewStack(
+		layout.Anchored(layout.Fill, layout.Rect(&v.panelRect)),
+		layout.Anchored(layout.Fill, column),
+	)
+}
+
+// openCalendar shows the calendar at the filtered day, or today.
+func (g *Game) openCalendar() {
+	v := g.uiManager.calendar
+	v.cursor = calendar.DateOf(g.clock.Now())
+	if g.dueFilter != nil {
+		v.cursor = *g.dueFilter
+	}
+	v.drag = nil
+	v.visible = true
+}
+
+func (v *calendarView) setMode(mode calendarMode) {
+	v.mode = mode
+	if mode == calendarMonth {
+		v.modeButton.Text = "Week"
+	} else {
+		v.modeButton.Text = "Month"
+	}
+}
+
+// step moves the cursor n months or weeks.
+func (v *calendarView) step(n int) {
+	if v.mode == calendarMonth {
+		v.cursor = v.cursor.AddMonths(n)
+	} else {
+		v.cursor = v.cursor.AddDays(7 * n)
+	}
+}
+
+// weeks returns the days shown, a row per week.
+func (v *calendarView) weeks() [][]calendar.Date {
+	if v.mode == calendarWeek {
+		return [][]calendar.Date{calendar.Week(v.cursor, v.weekStart)}
+	}
+	return calendar.MonthGrid(v.cursor.Year, v.cursor.Month, v.weekStart)
+}
+
+func (v *calendarView) title() string {
+	if v.mode == calendarWeek {
+		week := calendar.Week(v.cursor, v.weekStart)
+		first, last := week[0].At(0, 0, time.Local), week[6].At(0, 0, time.Local)
+		return first.Format("Jan 2") + " - " + last.Format("Jan 2, 2006")
+	}
+	return v.cursor.At(0, 0, time.Local).Format("January 2006")
+}
+
+// dayRect returns the cell of the day in the given week row and column.
+func (v *calendarView) dayRect(row, col, rows int) image.Rectangle {
+	grid := v.gridRect
+	grid.Min.Y += calendarHeaderHeight
+	x0 := grid.Min.X + col*grid.Dx()/7
+	x1 := grid.Min.X + (col+1)*grid.Dx()/7
+	y0 := grid.Min.Y + row*grid.Dy()/rows
+	y1 := grid.Min.Y + (row+1)*grid.Dy()/rows
+	return image.Rect(x0, y0, x1, y1)
+}
+
+// dayAt returns the day under a point.
+func (v *calendarView) dayAt(x, y int) (calendar.Date, bool) {
+	weeks := v.weeks()
+	for row, week := range weeks {
+		for col, day := range week {
+			if image.Pt(x, y).In(v.dayRect(row, col, len(weeks))) {
+				return day, true
+			}
+		}
+	}
+	return calendar.Date{}, false
+}
+
+// todosOn returns the todos due on a day, earliest first.
+func (v *calendarView) todosOn(day calendar.Date) []*models.Todo {
+	var todos []*models.Todo
+	for i := range v.g.todos.Todos {
+		todo := &v.g.todos.Todos[i]
+		if todo.Due != nil && calendar.DateOf(todo.Due.In(time.Local)) == day {
+			todos = append(todos, todo)
+		}
+	}
+	sort.SliceStable(todos, func(i, j int) bool { return todos[i].Due.Before(*todos[j].Due) })
+	return todos
+}
+
+// chips lays out the todos of a day in its cell. When they do not all fit
+// the last line is left for a "+N more" note, and more is N.
+func (v *calendarView) chips(day calendar.Date, cell image.Rectangle) (chips []*ui.TodoChip, more int) {
+	todos := v.todosOn(day)
+	fit := max((cell.Dy()-calendarDayHeight)/(calendarChipHeight+calendarChipGap), 0)
+	if len(todos) > fit {
+		more = len(todos) - max(fit-1, 0)
+		todos = todos[:max(fit-1, 0)]
+	}
+	now := v.g.clock.Now()
+	for k, todo := range todos {
+		y := cell.Min.Y + calendarDayHeight + k*(calendarChipHeight+calendarChipGap)
+		chip := ui.NewTodoChip(todo, cell.Min.X+3, y, cell.Dx()-6, calendarChipHeight)
+		chip.Overdue = todo.IsOverdue(now)
+		chips = append(chips, chip)
+	}
+	return chips, more
+}
+
+// chipAt returns the ID of the todo under a point.
+func (v *calendarView) chipAt(x, y int) (string, bool) {
+	weeks := v.weeks()
+	for row, week := range weeks {
+		for col, day := range week {
+			cell := v.dayRect(row, col, len(weeks))
+			if !image.Pt(x, y).In(cell) {
+				continue
+			}
+			chips, _ := v.chips(day, cell)
+			for _, chip := range chips {
+				if chip.Contains(x, y) {
+					return chip.Todo.ID, true
+				}
+			}
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// press starts a click on a day, or a drag of the todo under the cursor.
+func (v *calendarView) press(x, y int) {
+	day, ok := v.dayAt(x, y)
+	if !ok {
+		return
+	}
+	v.cursor = day
+	id, _ := v.chipAt(x, y)
+	v.drag = &calendarDrag{id: id, start: image.Pt(x, y), cursor: image.Pt(x, y)}
+}
+
+func (v *calendarView) dragTo(x, y int) {
+	if v.drag == nil {
+		return
+	}
+	v.drag.cursor = image.Pt(x, y)
+	if d := v.drag.cursor.Sub(v.drag.start); max(d.X, -d.X, d.Y, -d.Y) > boardDragThreshold {
+		v.drag.moved = true
+	}
+}
+
+// release drops a dragged todo on the day under the cursor, or filters
+// the list to the day clicked.
+func (v *calendarView) release(x, y int) {
+	drag := v.drag
+	v.drag = nil
+	if drag == nil {
+		return
+	}
+	day, ok := v.dayAt(x, y)
+	switch {
+	case !ok:
+	case drag.moved && drag.id != "":
+		v.g.rescheduleTodo(drag.id, day)
+		v.cursor = day
+	case !drag.moved:
+		v.showDay(day)
+	}
+}
+
+// showDay filters the list to the todos due on a day and closes the
+// calendar.
+func (v *calendarView) showDay(day calendar.Date) {
+	v.g.setDueFilter(&day)
+	v.visible = false
+}
+
+// update handles input while the panel is open. Like the help overlay it
+// is modal: Escape closes it, the arrow keys move between days, Page Up
+// and Page Down between months or weeks, and Enter shows the todos of a
+// day.
+func (v *calendarView) update(in ui.InputSource, kb *ui.Keyboard) {
+	if kb.IsJustPressed(ebiten.KeyEscape) {
+		v.visible = false
+		return
+	}
+	for _, button := range v.buttons() {
+		button.Update(in)
+	}
+	if !v.visible {
+		return
+	}
+
+	x, y := ui.CursorPosition(in)
+	switch {
+	case in.IsMouseButtonJustPressed(ebiten.MouseButtonLeft):
+		v.press(x, y)
+	case in.IsMouseButtonJustReleased(ebiten.MouseButtonLeft):
+		v.release(x, y)
+	case in.IsMouseButtonPressed(ebiten.MouseButtonLeft):
+		v.dragTo(x, y)
+	}
+
+	switch {
+	case kb.IsTriggered(ebiten.KeyArrowLeft):
+		v.cursor = v.cursor.AddDays(-1)
+	case kb.IsTriggered(ebiten.KeyArrowRight):
+		v.cursor = v.cursor.AddDays(1)
+	case kb.IsTriggered(ebiten.KeyArrowUp):
+		v.cursor = v.cursor.AddDays(-7)
+	case kb.IsTriggered(ebiten.KeyArrowDown):
+		v.cursor = v.cursor.AddDays(7)
+	case kb.IsTriggered(ebiten.KeyPageUp):
+		v.step(-1)
+	case kb.IsTriggered(ebiten.KeyPageDown):
+		v.step(1)
+	case kb.IsJustPressed(ebiten.KeyHome):
+		v.cursor = calendar.DateOf(v.g.clock.Now())
+	case kb.IsJustPressed(ebiten.KeyEnter):
+		v.showDay(v.cursor)
+	}
+}
+
+func (v *calendarView) draw(screen *ebiten.Image, windowWidth, windowHeight int) {
+	theme := ui.CurrentTheme()
+
+	// Dim the window behind the panel
+	ui.DrawRect(screen, 0, 0, float64(windowWidth), float64(windowHeight), theme.Overlay)
+
+	panel := v.panelRect
+	ui.DrawRect(screen, float64(panel.Min.X), float64(panel.Min.Y), float64(panel.Dx()), float64(panel.Dy()), theme.Border)
+	ui.DrawRect(screen, float64(panel.Min.X+1), float64(panel.Min.Y+1), float64(panel.Dx()-2), float64(panel.Dy()-2), theme.Surface)
+
+	title := v.title()
+	baseline := v.titleRect.Min.Y + (v.titleRect.Dy()+10)/2
+	ui.DrawText(screen, title, v.titleRect.Min.X+(v.titleRect.Dx()-len(title)*7)/2, baseline, theme.Text)
+	for _, button := range v.buttons() {
+		button.Draw(screen)
+	}
+
+	weeks := v.weeks()
+	for col, day := range weeks[0] {
+		name := day.Weekday().String()[:3]
+		cell := v.dayRect(0, col, len(weeks))
+		ui.DrawText(screen, name, cell.Min.X+4, v.gridRect.Min.Y+14, theme.TextMuted)
+	}
+
+	today := calendar.DateOf(v.g.clock.Now())
+	target, dropping := calendar.Date{}, false
+	if v.drag != nil && v.drag.moved && v.drag.id != "" {
+		target, dropping = v.dayAt(v.drag.cursor.X, v.drag.cursor.Y)
+	}
+	var dragged *ui.TodoChip
+	for row, week := range weeks {
+		for col, day := range week {
+			cell := v.dayRect(row, col, len(weeks))
+			borderColor := theme.Border
+			if day == today || dropping && day == target {
+				borderColor = theme.Accent
+			}
+			bgColor := theme.Surface
+			if day == v.cursor {
+				bgColor = theme.SelectedRow
+			}
+			ui.DrawRect(screen, float64(cell.Min.X), float64(cell.Min.Y), float64(cell.Dx()), float64(cell.Dy()), borderColor)
+			ui.DrawRect(screen, float64(cell.Min.X+1), float64(cell.Min.Y+1), float64(cell.Dx()-2), float64(cell.Dy()-2), bgColor)
+
+			numberColor := theme.Text
+			if v.mode == calendarMonth && day.Month != v.cursor.Month {
+				numberColor = theme.TextDisabled
+			}
+			ui.DrawText(screen, fmt.Sprint(day.Day), cell.Min.X+4, cell.Min.Y+14, numberColor)
+
+			chips, more := v.chips(day, cell)
+			for _, chip := range chips {
+				if v.drag != nil && v.drag.moved && v.drag.id == chip.Todo.ID {
+					chip.Dragging = true
+					chip.X += v.drag.cursor.X - v.drag.start.X
+					chip.Y += v.drag.cursor.Y - v.drag.start.Y
+					dragged = chip
+					continue
+				}
+				chip.Draw(screen)
+			}
+			if more > 0 {
+				y := cell.Min.Y + calendarDayHeight + len(chips)*(calendarChipHeight+calendarChipGap)
+				ui.DrawText(screen, fmt.Sprintf("+%d more", more), cell.Min.X+5, y+12, theme.TextMuted)
+			}
+		}
+	}
+	if dragged != nil {
+		dragged.Draw(screen)
+	}
+}
+
+// setDueFilter shows only the todos due on a day in the list, or all of
+// them with nil.
+func (g *Game) setDueFilter(day *calendar.Date) {
+	g.dueFilter = day
+	g.updateTodoItems()
+	g.uiManager.list.SetScrollOffset(0)
+}
+
+// rescheduleTodo moves a todo's due date to another day, keeping the time
+// of day.
+func (g *Game) rescheduleTodo(id string, day calendar.Date) {
+	todo := g.todos.FindTodo(id)
+	if todo == nil || todo.Due == nil {
+		return
+	}
+	due := calendar.Reschedule(todo.Due.In(time.Local), day)
+	if due.Equal(*todo.Due) {
+		return
+	}
+	g.pushUndo(g.captureUndo(id))
+	todo.SetDue(&due, g.clock.Now())
+	g.error = ""
+	if err := g.saveTodos(); err != nil {
+		g.error = fmt.Sprintf("Failed to save: %v", err)
+	}
+	g.updateTodoItems()
+}
@@ -0,0 +1,317 @@
+package game
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/layout"
+	"github.com/lapis2411/todo/internal/models"
+	"github.com/lapis2411/todo/internal/storage"
+	"github.com/lapis2411/todo/internal/ui"
+)
+
+// collection is a set of todos put away in a file of their own, such as the
+// archive or the trash, so that the todo file loaded at every start only
+// holds live work. A collection is read the first time it is needed.
+type collection struct {
+	storage storage.Storage
+	todos   []models.Todo
+	loaded  bool
+}
+
+func newCollection(s storage.Storage) *collection {
+	return &collection{storage: s}
+}
+
+// load reads the collection's file unless it has been read already.
+func (c *collection) load() error {
+	if c.loaded {
+		return nil
+	}
+	todos, err := c.storage.LoadTodos()
+	if err != nil {
+		return err
+	}
+	c.todos = todos
+	c.loaded = true
+	return nil
+}
+
+func (c *collection) save() error {
+	return c.storage.SaveTodos(c.todos)
+}
+
+// add appends todos to the collection and saves it. The collection is left
+// as it was if saving fails.
+func (c *collection) add(todos ...models.Todo) error {
+	if err := c.load(); err != nil {
+		return err
+	}
+	n := len(c.todos)
+	c.todos = append(c.todos, todos...)
+	if err := c.save(); err != nil {
+		c.todos = c.todos[:n]
+		return err
+	}
+	return nil
+}
+
+// remove takes the todos with the given IDs out of the collection and saves
+// it, returning the removed todos.
+func (c *collection) remove(ids ...string) ([]models.Todo, error) {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return c.removeFunc(func(todo models.Todo) bool { return wanted[todo.ID] })
+}
+
+// removeFunc takes the todos for which f returns true out of the collection,
+// saving it if any were removed. The collection is left as it was if saving
+// fails.
+func (c *collection) removeFunc(f func(todo models.Todo) bool) ([]models.Todo, error) {
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	var removed, kept []models.Todo
+	for _, todo := range c.todos {
+		if f(todo) {
+			removed = append(removed, todo)
+		} else {
+			kept = append(kept, todo)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	todos := c.todos
+	c.todos = kept
+	if err := c.save(); err != nil {
+		c.todos = todos
+		return nil, err
+	}
+	return removed, nil
+}
+
+// collectionView is a modal panel listing the todos of a collection, most
+// recently added first, with buttons to restore or delete each one.
+type collectionView struct {
+	title        string
+	emptyMessage string
+	deleteHint   string                         // What Delete does, for the hint line
+	detail       func(todo *models.Todo) string // Shown dimmed on each row
+	onRestore    func(id string)
+	onDelete     func(id string)
+
+	coll        *collection
+	visible     bool
+	todos       []models.Todo // The collection in display order
+	list        *ui.ListView
+	selectedID  string
+	panelRect   image.Rectangle
+	titleRect   image.Rectangle
+	closeButton *ui.Button
+}
+
+func newCollectionView(coll *collection) *collectionView {
+	v := &collectionView{coll: coll}
+	v.list = ui.NewListView(0, 0, 0, 0)
+	v.list.RowHeight = func(int) int { return TodoHeight }
+	v.list.NewRow = v.newRow
+	v.closeButton = ui.NewButton(0, 0, 0, 0, "Close", func() {
+		v.visible = false
+	})
+	v.closeButton.SetVariant(ui.ButtonSecondary)
+	return v
+}
+
+// node returns the layout of the panel inside the window.
+func (v *collectionView) node() layout.Node {
+	titleRow := layout.Row(
+		layout.Flex(1, layout.Rect(&v.titleRect)),
+		layout.Fixed(70, layout.Leaf(70, 30, v.closeButton.SetBounds)),
+	)
+	column := layout.Column(
+		layout.Fixed(30, titleRow),
+		layout.Flex(1, layout.Leaf(0, 0, v.list.SetBounds)),
+	)
+	column.Padding = layout.Uniform(10)
+	column.Gap = 10
+	return layout.NewStack(
+		layout.Anchored(layout.Fill, layout.Rect(&v.panelRect)),
+		layout.Anchored(layout.Fill, column),
+	)
+}
+
+// open loads the collection and shows the panel with the newest todo
+// selected.
+func (v *collectionView) open() error {
+	if err := v.coll.load(); err != nil {
+		return err
+	}
+	v.visible = true
+	v.selectedID = ""
+	v.refresh()
+	v.list.SetScrollOffset(0)
+	v.selectRow(0)
+	return nil
+}
+
+// refresh rebuilds the rows from the collection, newest first.
+func (v *collectionView) refresh() {
+	v.todos = make([]models.Todo, 0, len(v.coll.todos))
+	for i := len(v.coll.todos) - 1; i >= 0; i-- {
+		v.todos = append(v.todos, v.coll.todos[i])
+	}
+	v.list.SetCount(len(v.todos))
+	if v.selectedIndex() < 0 && len(v.todos) > 0 {
+		v.selectRow(0)
+	}
+}
+
+// newRow creates the row for the i-th todo.
+func (v *collectionView) newRow(i int) ui.ListRow {
+	todo := &v.todos[i]
+	item := ui.NewRestorableItem(todo, v.detail(todo), 0, 0, 0, TodoHeight)
+	item.RestoreBtn.OnClick = func(todoID string) func() {
+		return func() {
+			v.onRestore(todoID)
+		}
+	}(todo.ID)
+	item.DeleteBtn.OnClick = func(todoID string) func() {
+		return func() {
+			v.onDelete(todoID)
+		}
+	}(todo.ID)
+	item.Selected = todo.ID == v.selectedID
+	return item
+}
+
+func (v *collectionView) selectRow(i int) {
+	if i < 0 || i >= len(v.todos) {
+		return
+	}
+	v.selectedID = v.todos[i].ID
+	v.list.ForEachRow(func(j int, row ui.ListRow) {
+		row.(*ui.RestorableItem).Selected = j == i
+	})
+	v.list.ScrollToRow(i)
+}
+
+func (v *collectionView) selectedIndex() int {
+	for i, todo := range v.todos {
+		if todo.ID == v.selectedID {
+			return i
+		}
+	}
+	return -1
+}
+
+// update handles input while the panel is open. Like the help overlay it
+// is modal: Escape closes it, the arrow keys select a row, Enter restores
+// it and Delete deletes it.
+func (v *collectionView) update(in ui.InputSource, kb *ui.Keyboard) {
+	if kb.IsJustPressed(ebiten.KeyEscape) {
+		v.visible = false
+		return
+	}
+	v.closeButton.Update(in)
+	if !v.visible {
+		return
+	}
+
+	if in.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		if i, ok := v.list.RowAt(ui.CursorPosition(in)); ok {
+			v.selectRow(i)
+		}
+	}
+	v.list.Update(in)
+
+	count := len(v.todos)
+	if count == 0 {
+		return
+	}
+	i := v.selectedIndex()
+	switch {
+	case kb.IsTriggered(ebiten.KeyArrowUp):
+		v.selectRow(max(i-1, 0))
+	case kb.IsTriggered(ebiten.KeyArrowDown):
+		v.selectRow(min(i+1, count-1))
+	case kb.IsJustPressed(ebiten.KeyHome):
+		v.selectRow(0)
+	case kb.IsJustPressed(ebiten.KeyEnd):
+		v.selectRow(count - 1)
+	case i < 0:
+		return
+	case kb.IsJustPressed(ebiten.KeyEnter):
+		v.onRestore(v.selectedID)
+		v.selectRow(min(i, len(v.todos)-1))
+	case kb.IsJustPressed(ebiten.KeyDelete):
+		v.onDelete(v.selectedID)
+		v.selectRow(min(i, len(v.todos)-1))
+	}
+}
+
+func (v *collectionView) draw(screen *ebiten.Image, windowWidth, windowHeight int) {
+	theme := ui.CurrentTheme()
+
+	// Dim the window behind the panel
+	ui.DrawRect(screen, 0, 0, float64(windowWidth), float64(windowHeight), theme.Overlay)
+
+	panel := v.panelRect
+	ui.DrawRect(screen, float64(panel.Min.X), float64(panel.Min.Y), float64(panel.Dx()), float64(panel.Dy()), theme.Border)
+	ui.DrawRect(screen, float64(panel.Min.X+1), float64(panel.Min.Y+1), float64(panel.Dx()-2), float64(panel.Dy()-2), theme.Surface)
+
+	title := fmt.Sprintf("%s (%d)", v.title, len(v.todos))
+	baseline := v.titleRect.Min.Y + (v.titleRect.Dy()+10)/2
+	ui.DrawText(screen, title, v.titleRect.Min.X, baseline, theme.Text)
+	hint := "Enter: restore  Delete: " + v.deleteHint + "  Esc: close"
+	ui.DrawText(screen, hint, v.titleRect.Max.X-10-len(hint)*7, baseline, theme.TextMuted)
+	v.closeButton.Draw(screen)
+
+	v.list.Draw(screen)
+	if len(v.todos) == 0 {
+		list := v.list.Bounds()
+		ui.DrawText(screen, v.emptyMessage, list.Min.X+10, list.Min.Y+30, theme.TextMuted)
+	}
+}
+
+// openCollectionView returns the collection panel that is open, if any.
+func (g *Game) openCollectionView() *collectionView {
+	for _, v := range []*collectionView{g.uiManager.archive, g.uiManager.trash} {
+		if v.visible {
+			return v
+		}
+	}
+	return nil
+}
+
+// restoreFrom moves a todo from a collection back to the end of the todo
+// list, clearing the mark the collection gave it. The todos are saved
+// first so that a failure leaves the todo in the collection, and the todo
+// is taken back out of the list if the collection then fails to save, so
+// that it is never in both files.
+func (g *Game) restoreFrom(coll *collection, id string, unmark func(todo *models.Todo)) {
+	n := len(g.todos.Todos)
+	for _, todo := range coll.todos {
+		if todo.ID == id {
+			unmark(&todo)
+			g.todos.Todos = append(g.todos.Todos, todo)
+		}
+	}
+	g.error = ""
+	err := g.saveTodosNow()
+	if err == nil {
+		_, err = coll.remove(id)
+	}
+	if err != nil {
+		// The first error is the one shown; writing the list back is
+		// retried by the background saver if it fails too
+		g.todos.Todos = g.todos.Todos[:n]
+		g.saveTodosNow()
+		g.error = fmt.Sprintf("Failed to save: %v", err)
+	}
+	g.updateTodoItems()
+}
@@ -0,0 +1,218 @@
+package game
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/layout"
+	"github.com/lapis2411/todo/internal/models"
+	"github.com/lapis2411/todo/internal/stats"
+	"github.com/lapis2411/todo/internal/ui"
+)
+
+// dashboardView is the modal panel with statistics about the todos: the
+// counts, the average time to complete and streaks above charts of the
+// completions per day and per week and of the open and done counts.
+type dashboardView struct {
+	visible     bool
+	summary     stats.Summary
+	panelRect   image.Rectangle
+	titleRect   image.Rectangle
+	summaryRect image.Rectangle
+	dailyRect   image.Rectangle
+	weeklyRect  image.Rectangle
+	historyRect image.Rectangle
+	closeButton *ui.Button
+}
+
+// chartSeries is one stacked part of the bars of a chart.
+type chartSeries struct {
+	label  string
+	values []int
+	color  color.Color
+}
+
+func newDashboardView() *dashboardView {
+	v := &dashboardView{}
+	v.closeButton = ui.NewButton(0, 0, 0, 0, "Close", func() {
+		v.visible = false
+	})
+	v.closeButton.SetVariant(ui.ButtonSecondary)
+	return v
+}
+
+// node returns the layout of the panel inside the window.
+func (v *dashboardView) node() layout.Node {
+	titleRow := layout.Row(
+		layout.Flex(1, layout.Rect(&v.titleRect)),
+		layout.Fixed(70, layout.Leaf(70, 30, v.closeButton.SetBounds)),
+	)
+	completions := layout.Row(
+		layout.Flex(1, layout.Rect(&v.dailyRect)),
+		layout.Flex(1, layout.Rect(&v.weeklyRect)),
+	)
+	completions.Gap = 20
+	column := layout.Column(
+		layout.Fixed(30, titleRow),
+		layout.Fixed(40, layout.Rect(&v.summaryRect)),
+		layout.Flex(1, completions),
+		layout.Flex(1, layout.Rect(&v.historyRect)),
+	)
+	column.Padding = layout.Uniform(10)
+	column.Gap = 10
+	return layout.NewStack(
+		layout.Anchored(layout.Fill, layout.Rect(&v.panelRect)),
+		layout.Anchored(layout.Fill, column),
+	)
+}
+
+// openDashboard computes the statistics of the todos and the archive and
+// shows them. Trashed todos are left out.
+func (g *Game) openDashboard() {
+	if err := g.archive.load(); err != nil {
+		g.error = fmt.Sprintf("Failed to load archive: %v", err)
+		return
+	}
+	todos := append(append([]models.Todo(nil), g.todos.Todos...), g.archive.todos...)
+	v := g.uiManager.dashboard
+	v.summary = stats.Compute(todos, g.clock.Now())
+	v.visible = true
+}
+
+// update handles input while the panel is open; Escape closes it.
+func (v *dashboardView) update(in ui.InputSource, kb *ui.Keyboard) {
+	if kb.IsJustPressed(ebiten.KeyEscape) {
+		v.visible = false
+		return
+	}
+	v.closeButton.Update(in)
+}
+
+func (v *dashboardView) draw(screen *ebiten.Image, windowWidth, windowHeight int) {
+	theme := ui.CurrentTheme()
+
+	// Dim the window behind the panel
+	ui.DrawRect(screen, 0, 0, float64(windowWidth), float64(windowHeight), theme.Overlay)
+
+	panel := v.panelRect
+	ui.DrawRect(screen, float64(panel.Min.X), float64(panel.Min.Y), float64(panel.Dx()), float64(panel.Dy()), theme.Border)
+	ui.DrawRect(screen, float64(panel.Min.X+1), float64(panel.Min.Y+1), float64(panel.Dx()-2), float64(panel.Dy()-2), theme.Surface)
+
+	baseline := v.titleRect.Min.Y + (v.titleRect.Dy()+10)/2
+	ui.DrawText(screen, "Statistics", v.titleRect.Min.X, baseline, theme.Text)
+	v.closeButton.Draw(screen)
+
+	s := v.summary
+	counts := fmt.Sprintf("Open %d   Done %d   Overdue %d", s.Open, s.Done, s.Overdue)
+	ui.DrawText(screen, counts, v.summaryRect.Min.X, v.summaryRect.Min.Y+13, theme.Text)
+	streak := fmt.Sprintf("Average time to complete %s   Streak %s (best %s)",
+		formatDuration(s.AverageTimeToComplete), pluralDays(s.CurrentStreak), pluralDays(s.LongestStreak))
+	ui.DrawText(screen, streak, v.summaryRect.Min.X, v.summaryRect.Min.Y+33, theme.Text)
+
+	daily := make([]int, len(s.PerDay))
+	dayLabels := make([]string, len(s.PerDay))
+	for i, b := range s.PerDay {
+		daily[i] = b.Count
+		dayLabels[i] = b.Start.Format("1/2")
+	}
+	drawBarChart(screen, v.dailyRect, "Completed per day", dayLabels, chartSeries{values: daily, color: theme.Success})
+
+	weekly := make([]int, len(s.PerWeek))
+	weekLabels := make([]string, len(s.PerWeek))
+	for i, b := range s.PerWeek {
+		weekly[i] = b.Count
+		weekLabels[i] = b.Start.Format("1/2")
+	}
+	drawBarChart(screen, v.weeklyRect, "Completed per week", weekLabels, chartSeries{values: weekly, color: theme.Success})
+
+	done := make([]int, len(s.OverTime))
+	open := make([]int, len(s.OverTime))
+	for i, p := range s.OverTime {
+		done[i] = p.Done
+		open[i] = p.Open
+	}
+	drawBarChart(screen, v.historyRect, "Open and done", dayLabels,
+		chartSeries{label: "done", values: done, color: theme.Success},
+		chartSeries{label: "open", values: open, color: theme.Accent})
+}
+
+// drawBarChart draws a bar chart of stacked series into r, with the title
+// and a legend above and the labels of the bars below. Labels are skipped
+// where they would overlap.
+func drawBarChart(screen *ebiten.Image, r image.Rectangle, title string, labels []string, series ...chartSeries) {
+	theme := ui.CurrentTheme()
+	if r.Dy() < 50 || len(labels) == 0 {
+		return
+	}
+
+	ui.DrawText(screen, title, r.Min.X, r.Min.Y+13, theme.Text)
+	legendX := r.Min.X + len(title)*7 + 20
+	for _, s := range series {
+		if s.label == "" {
+			continue
+		}
+		ui.DrawRect(screen, float64(legendX), float64(r.Min.Y+4), 9, 9, s.color)
+		ui.DrawText(screen, s.label, legendX+13, r.Min.Y+13, theme.TextMuted)
+		legendX += 13 + len(s.label)*7 + 15
+	}
+
+	// The bars fill the space between the title and the labels, scaled to
+	// the highest stack
+	area := image.Rect(r.Min.X, r.Min.Y+24, r.Max.X, r.Max.Y-18)
+	highest := 1
+	for i := range labels {
+		total := 0
+		for _, s := range series {
+			total += s.values[i]
+		}
+		highest = max(highest, total)
+	}
+	maxLabel := fmt.Sprint(highest)
+	ui.DrawText(screen, maxLabel, r.Max.X-len(maxLabel)*7, r.Min.Y+13, theme.TextMuted)
+
+	slot := float64(area.Dx()) / float64(len(labels))
+	barWidth := max(slot-4, 1)
+	labelEvery := 1
+	for float64(labelEvery)*slot < float64(len("12/31")*7+4) {
+		labelEvery++
+	}
+	for i, label := range labels {
+		x := float64(area.Min.X) + float64(i)*slot + (slot-barWidth)/2
+		y := float64(area.Max.Y)
+		for _, s := range series {
+			h := float64(s.values[i]) * float64(area.Dy()) / float64(highest)
+			y -= h
+			ui.DrawRect(screen, x, y, barWidth, h, s.color)
+		}
+		if (len(labels)-1-i)%labelEvery == 0 {
+			labelX := int(x+barWidth/2) - len(label)*7/2
+			ui.DrawText(screen, label, labelX, r.Max.Y-3, theme.TextMuted)
+		}
+	}
+	ui.DrawRect(screen, float64(area.Min.X), float64(area.Max.Y), float64(area.Dx()), 1, theme.Border)
+}
+
+// formatDuration formats a duration coarsely, such as "2d 3h" or "45m".
+func formatDuration(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", max(int(d.Minutes()), 1))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd %dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
+
+func pluralDays(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
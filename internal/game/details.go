@@ -0,0 +1,229 @@
+package game
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/layout"
+	"github.com/lapis2411/todo/internal/models"
+	"github.com/lapis2411/todo/internal/ui"
+)
+
+const (
+	detailsLineHeight = 18
+	detailsTimeFormat = "2006-01-02 15:04"
+)
+
+// detailsView is the modal panel showing the fields and change history of
+// a todo.
+type detailsView struct {
+	visible     bool
+	todoID      string
+	lines       []detailsLine
+	scroll      int // Index of the first line shown
+	panelRect   image.Rectangle
+	titleRect   image.Rectangle
+	bodyRect    image.Rectangle
+	closeButton *ui.Button
+}
+
+type detailsLine struct {
+	text  string
+	muted bool
+}
+
+func newDetailsView() *detailsView {
+	v := &detailsView{}
+	v.closeButton = ui.NewButton(0, 0, 0, 0, "Close", func() {
+		v.visible = false
+	})
+	v.closeButton.SetVariant(ui.ButtonSecondary)
+	return v
+}
+
+// node returns the layout of the panel inside the window.
+func (v *detailsView) node() layout.Node {
+	titleRow := layout.Row(
+		layout.Flex(1, layout.Rect(&v.titleRect)),
+		layout.Fixed(70, layout.Leaf(70, 30, v.closeButton.SetBounds)),
+	)
+	column := layout.Column(
+		layout.Fixed(30, titleRow),
+		layout.Flex(1, layout.Rect(&v.bodyRect)),
+	)
+	column.Padding = layout.Uniform(10)
+	column.Gap = 10
+	return layout.NewStack(
+		layout.Anchored(layout.Fill, layout.Rect(&v.panelRect)),
+		layout.Anchored(layout.Fill, column),
+	)
+}
+
+// showDetails opens the details of the selected todo.
+func (g *Game) showDetails() {
+	todo := g.todos.FindTodo(g.uiManager.selectedID)
+	if todo == nil {
+		g.error = "Select a todo to see its details"
+		return
+	}
+	v := g.uiManager.details
+	v.todoID = todo.ID
+	v.lines = detailsLines(todo)
+	v.scroll = 0
+	v.visible = true
+}
+
+// detailsLines describes a todo's fields followed by its history, newest
+// change first.
+func detailsLines(todo *models.Todo) []detailsLine {
+	lines := []detailsLine{
+		{text: "Text: " + todo.Text},
+		{text: "Created: " + detailsTime(todo.CreatedAt)},
+		{text: "Updated: " + detailsTime(todo.LastUpdated())},
+	}
+	if todo.Completed {
+		completed := "Completed: yes"
+		if todo.CompletedAt != nil {
+			completed = "Completed: " + detailsTime(*todo.CompletedAt)
+		}
+		lines = append(lines, detailsLine{text: completed})
+	} else {
+		lines = append(lines, detailsLine{text: "Completed: no"})
+	}
+	if todo.Due != nil {
+		lines = append(lines, detailsLine{text: "Due: " + detailsTime(*todo.Due)})
+	}
+	if todo.Recurrence != nil {
+		lines = append(lines, detailsLine{text: "Repeats: " + todo.Recurrence.String()})
+	}
+	lines = append(lines, detailsLine{text: "Priority: " + todo.Priority.String()})
+	if len(todo.Tags) > 0 {
+		lines = append(lines, detailsLine{text: "Tags: #" + strings.Join(todo.Tags, " #")})
+	}
+
+	lines = append(lines, detailsLine{}, detailsLine{text: "History"})
+	if len(todo.History) == 0 {
+		lines = append(lines, detailsLine{text: "No changes recorded", muted: true})
+	}
+	for i := len(todo.History) - 1; i >= 0; i-- {
+		change := todo.History[i]
+		lines = append(lines, detailsLine{
+			text: detailsTime(change.At) + "  " + describeChange(change),
+		})
+	}
+	return lines
+}
+
+// describeChange returns a short description of a change for the history.
+func describeChange(change models.Change) string {
+	switch change.Field {
+	case models.FieldCompleted:
+		if change.To == "true" {
+			return "Completed"
+		}
+		return "Reopened"
+	case models.FieldNotes:
+		return "Edited notes"
+	case models.FieldText:
+		return fmt.Sprintf("Text: %q -> %q", change.From, change.To)
+	case models.FieldPriority:
+		return fmt.Sprintf("Priority: %s -> %s", change.From, change.To)
+	case models.FieldTags:
+		return fmt.Sprintf("Tags: %s -> %s", tagsText(change.From), tagsText(change.To))
+	case models.FieldStatus:
+		return "Moved to " + change.To
+	case models.FieldDue:
+		return fmt.Sprintf("Due: %s -> %s", orNone(change.From), orNone(change.To))
+	case models.FieldRecurrence:
+		return fmt.Sprintf("Repeats: %s -> %s", orNone(change.From), orNone(change.To))
+	default:
+		return fmt.Sprintf("%s: %q -> %q", change.Field, change.From, change.To)
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// tagsText formats the space-separated tags of a change as "#tag" words.
+func tagsText(tags string) string {
+	if tags == "" {
+		return "(none)"
+	}
+	return "#" + strings.Join(strings.Fields(tags), " #")
+}
+
+// visibleLines returns how many lines fit in the body of the panel.
+func (v *detailsView) visibleLines() int {
+	return max(v.bodyRect.Dy()/detailsLineHeight, 1)
+}
+
+func (v *detailsView) scrollBy(lines int) {
+	v.scroll = max(0, min(v.scroll+lines, len(v.lines)-v.visibleLines()))
+}
+
+// update handles input while the panel is open. Like the help overlay it
+// is modal: Escape closes it and the arrow keys and the wheel scroll.
+func (v *detailsView) update(in ui.InputSource, kb *ui.Keyboard) {
+	if kb.IsJustPressed(ebiten.KeyEscape) {
+		v.visible = false
+		return
+	}
+	v.closeButton.Update(in)
+
+	switch {
+	case kb.IsTriggered(ebiten.KeyArrowUp):
+		v.scrollBy(-1)
+	case kb.IsTriggered(ebiten.KeyArrowDown):
+		v.scrollBy(1)
+	case kb.IsTriggered(ebiten.KeyPageUp):
+		v.scrollBy(-v.visibleLines())
+	case kb.IsTriggered(ebiten.KeyPageDown):
+		v.scrollBy(v.visibleLines())
+	}
+	if _, dy := in.Wheel(); dy != 0 {
+		v.scrollBy(-int(dy * 3))
+	}
+}
+
+func (v *detailsView) draw(screen *ebiten.Image, windowWidth, windowHeight int) {
+	theme := ui.CurrentTheme()
+
+	// Dim the window behind the panel
+	ui.DrawRect(screen, 0, 0, float64(windowWidth), float64(windowHeight), theme.Overlay)
+
+	panel := v.panelRect
+	ui.DrawRect(screen, float64(panel.Min.X), float64(panel.Min.Y), float64(panel.Dx()), float64(panel.Dy()), theme.Border)
+	ui.DrawRect(screen, float64(panel.Min.X+1), float64(panel.Min.Y+1), float64(panel.Dx()-2), float64(panel.Dy()-2), theme.Surface)
+
+	baseline := v.titleRect.Min.Y + (v.titleRect.Dy()+10)/2
+	ui.DrawText(screen, "Todo details", v.titleRect.Min.X, baseline, theme.Text)
+	v.closeButton.Draw(screen)
+
+	// Long lines are clipped at the edge of the panel
+	if v.bodyRect.Empty() {
+		return
+	}
+	body := ui.Clip(screen, v.bodyRect)
+	for i := v.scroll; i < len(v.lines) && i < v.scroll+v.visibleLines(); i++ {
+		line := v.lines[i]
+		color := theme.Text
+		if line.muted {
+			color = theme.TextMuted
+		}
+		y := v.bodyRect.Min.Y + (i-v.scroll)*detailsLineHeight + 13
+		ui.DrawText(body, line.text, v.bodyRect.Min.X, y, color)
+	}
+}
+
+// detailsTime formats a time for the details panel in local time.
+func detailsTime(t time.Time) string {
+	return t.Local().Format(detailsTimeFormat)
+}
@@ -0,0 +1,92 @@
+package game
+
+import (
+	"github.com/lapis2411/todo/internal/storage"
+	"github.com/lapis2411/todo/internal/ui"
+)
+
+// Row widgets come and go as the list is refreshed and scrolled, so the
+// unsaved edit of a row is kept by todo ID when its widget is dropped and
+// put back into the next widget made for the todo. The edits are kept
+// whether the list or the board is shown, and are what quitting saves as
+// drafts.
+
+// todoEdit is an unsaved edit of a todo whose row widget was dropped.
+type todoEdit struct {
+	storage.TodoDraft
+	textFocused  bool // The text editor had focus
+	notesFocused bool // The notes editor had focus
+}
+
+// editOf returns the unsaved edit open in a row. Notes are only kept while
+// their pane stays expanded, so that collapsing it discards them.
+func (g *Game) editOf(item *ui.TodoItem) (todoEdit, bool) {
+	m := g.uiManager
+	edit := todoEdit{TodoDraft: storage.TodoDraft{ID: item.Todo.ID}}
+	if text := item.EditTextBox.GetText(); item.Editing && text != item.Todo.Text {
+		edit.Text = &text
+		edit.textFocused = m.focus.IsFocused(item.EditTextBox)
+	}
+	notes := item.NotesArea.GetText()
+	if item.Expanded && item.EditingNotes && notes != item.Todo.Notes && m.expandedNotes[item.Todo.ID] {
+		edit.Notes = &notes
+		edit.notesFocused = m.focus.IsFocused(item.NotesArea)
+	}
+	return edit, edit.Text != nil || edit.Notes != nil
+}
+
+// keepEdit keeps the unsaved edit of a row widget the list drops.
+func (g *Game) keepEdit(_ int, row ui.ListRow) {
+	if edit, ok := g.editOf(row.(*ui.TodoItem)); ok {
+		g.uiManager.edits[edit.ID] = edit
+	}
+}
+
+// resumeEdit puts the kept edit of a todo back into a new row widget,
+// along with the focus it had.
+func (g *Game) resumeEdit(item *ui.TodoItem) {
+	m := g.uiManager
+	edit, ok := m.edits[item.Todo.ID]
+	if !ok {
+		return
+	}
+	delete(m.edits, item.Todo.ID)
+	if edit.Notes != nil {
+		item.EditNotes(*edit.Notes)
+	}
+	if edit.Text != nil {
+		item.ResumeEditing(*edit.Text)
+	}
+	if m.board.visible {
+		return
+	}
+	if edit.textFocused {
+		m.focus.Focus(item.EditTextBox)
+	} else if edit.notesFocused {
+		m.focus.Focus(item.NotesArea)
+	}
+}
+
+// drafts returns the unsaved edits of the todos, in list order, and the
+// text of the new todo box.
+func (g *Game) drafts() storage.Drafts {
+	m := g.uiManager
+	live := make(map[string]todoEdit)
+	m.list.ForEachRow(func(_ int, row ui.ListRow) {
+		if edit, ok := g.editOf(row.(*ui.TodoItem)); ok {
+			live[edit.ID] = edit
+		}
+	})
+
+	drafts := storage.Drafts{Input: m.inputBox.GetText()}
+	for _, todo := range g.todos.Todos {
+		edit, ok := live[todo.ID]
+		if !ok {
+			edit, ok = m.edits[todo.ID]
+		}
+		if ok {
+			drafts.Todos = append(drafts.Todos, edit.TodoDraft)
+		}
+	}
+	return drafts
+}
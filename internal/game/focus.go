@@ -0,0 +1,174 @@
+package game
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/models"
+	"github.com/lapis2411/todo/internal/ui"
+)
+
+// todoList is the focus target for the rows of the list. It takes a single
+// tab stop; the arrow keys then move the selection between rows.
+type todoList struct {
+	g *Game
+}
+
+func (l *todoList) SetFocus(focused bool) {
+	if focused && l.g.selectedIndex() < 0 {
+		l.g.selectTodo(0)
+	}
+}
+
+func (l *todoList) Contains(x, y int) bool {
+	_, ok := l.g.uiManager.list.RowAt(x, y)
+	return ok
+}
+
+// Bounds is the visible part of the selected row, so the focus ring
+// follows the selection.
+func (l *todoList) Bounds() image.Rectangle {
+	list := l.g.uiManager.list
+	return list.RowBounds(l.g.selectedIndex()).Intersect(list.Bounds())
+}
+
+// updateFocusOrder registers the focusable widgets in tab order: the header
+// inputs, the list, the editors of visible rows, the bulk toolbar, then the
+// footer buttons. The board takes the place of the list and its rows when
+// shown.
+// Focus left on a widget that has gone away falls back to the list.
+func (g *Game) updateFocusOrder() {
+	m := g.uiManager
+	widgets := []ui.Focusable{m.inputBox, m.addButton, m.searchBox, m.viewButton}
+	todos := ui.Focusable(m.todoList)
+	if m.board.visible {
+		todos = m.board
+	}
+	if m.list.Count() > 0 {
+		widgets = append(widgets, todos)
+	}
+	if !m.board.visible {
+		first, last := m.list.VisibleRange()
+		for i := first; i < last; i++ {
+			widgets = append(widgets, g.todoItem(i).FocusWidgets()...)
+		}
+	}
+	widgets = append(widgets, m.bulkBar.widgets()...)
+	for _, filter := range []models.FilterType{models.FilterAll, models.FilterActive, models.FilterCompleted} {
+		widgets = append(widgets, m.filterButtons[filter])
+	}
+	widgets = append(widgets, m.clearButton, m.archiveButton, m.trashButton, m.statsButton)
+	m.focus.SetWidgets(widgets...)
+
+	if focused := m.focus.Focused(); focused != nil && !m.focus.HasWidget(focused) {
+		if m.list.Count() > 0 {
+			m.focus.Focus(todos)
+		} else {
+			m.focus.Focus(nil)
+		}
+	}
+}
+
+// leaveTextField moves focus from a header field to the todos, or nowhere
+// when there are none.
+func (g *Game) leaveTextField() {
+	m := g.uiManager
+	switch {
+	case m.list.Count() == 0:
+		m.focus.Focus(nil)
+	case m.board.visible:
+		m.focus.Focus(m.board)
+	default:
+		m.focus.Focus(m.todoList)
+	}
+}
+
+// handleFocusClick gives focus to the widget under a mouse click and
+// selects the clicked row. Ctrl-click adds or removes the row and
+// Shift-click selects the range from the last clicked row.
+func (g *Game) handleFocusClick(x, y int, kb *ui.Keyboard) {
+	g.uiManager.focus.HandleClick(x, y)
+	i, ok := g.uiManager.list.RowAt(x, y)
+	switch {
+	case !ok || g.uiManager.board.visible:
+	case kb.IsShortcutModifierPressed():
+		g.toggleSelection(i)
+	case kb.IsShiftPressed():
+		g.extendSelection(i)
+	default:
+		g.selectTodo(i)
+	}
+}
+
+// handleListKeys handles the keys that act on the selected row while the
+// list has focus. Shift with the arrow keys extends the selection, and
+// Space and Delete act on every selected row. It runs after the widgets
+// have updated so that the key that starts editing is not also seen by the
+// editor.
+func (g *Game) handleListKeys(kb *ui.Keyboard) {
+	m := g.uiManager
+	count := m.list.Count()
+	if !m.focus.IsFocused(m.todoList) || count == 0 {
+		return
+	}
+
+	i := g.selectedIndex()
+	switch {
+	case kb.IsShortcutModifierPressed() && kb.IsJustPressed(ebiten.KeyA):
+		g.selectAll()
+	case kb.IsShiftPressed() && kb.IsTriggered(ebiten.KeyArrowUp):
+		g.extendSelection(max(i-1, 0))
+	case kb.IsShiftPressed() && kb.IsTriggered(ebiten.KeyArrowDown):
+		g.extendSelection(min(i+1, count-1))
+	case kb.IsTriggered(ebiten.KeyArrowUp):
+		g.selectTodo(max(i-1, 0))
+	case kb.IsTriggered(ebiten.KeyArrowDown):
+		g.selectTodo(min(i+1, count-1))
+	case kb.IsTriggered(ebiten.KeyPageUp):
+		g.selectTodo(max(i-g.rowsPerPage(), 0))
+	case kb.IsTriggered(ebiten.KeyPageDown):
+		g.selectTodo(min(i+g.rowsPerPage(), count-1))
+	case kb.IsJustPressed(ebiten.KeyHome):
+		g.selectTodo(0)
+	case kb.IsJustPressed(ebiten.KeyEnd):
+		g.selectTodo(count - 1)
+	case i < 0:
+		return
+	case kb.IsJustPressed(ebiten.KeyEscape) && g.isMultiSelect():
+		g.clearSelection()
+	case kb.IsJustPressed(ebiten.KeySpace) && g.isMultiSelect():
+		g.bulkToggle()
+		if g.selectedIndex() < 0 && m.list.Count() > 0 {
+			g.selectTodo(min(i, m.list.Count()-1))
+		}
+	case kb.IsJustPressed(ebiten.KeyDelete) && g.isMultiSelect():
+		g.bulkDelete()
+		if m.list.Count() > 0 {
+			g.selectTodo(min(i, m.list.Count()-1))
+		}
+	case kb.IsJustPressed(ebiten.KeySpace):
+		g.toggleTodo(m.selectedID)
+		// The row may have left the filtered list
+		if g.selectedIndex() < 0 && m.list.Count() > 0 {
+			g.selectTodo(min(i, m.list.Count()-1))
+		}
+	case kb.IsJustPressed(ebiten.KeyEnter) || kb.IsJustPressed(ebiten.KeyF2):
+		g.todoItem(i).StartEditing()
+	case kb.IsJustPressed(ebiten.KeyDelete):
+		g.deleteTodo(m.selectedID)
+		// Keep a row selected so repeated deletes walk down the list
+		if m.list.Count() > 0 {
+			g.selectTodo(min(i, m.list.Count()-1))
+		}
+	}
+}
+
+func (g *Game) selectedIndex() int {
+	return g.indexOfTodo(g.uiManager.selectedID)
+}
+
+// rowsPerPage returns how many rows of the default height fit in the list.
+func (g *Game) rowsPerPage() int {
+	return max(g.uiManager.list.Height/TodoHeight, 1)
+}
@@ -2,15 +2,21 @@ package game
 
 import (
 	"fmt"
-	"image/color"
+	"image"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/image/font/basicfont"
 
+	"github.com/lapis2411/todo/internal/calendar"
+	"github.com/lapis2411/todo/internal/keymap"
+	"github.com/lapis2411/todo/internal/layout"
+	"github.com/lapis2411/todo/internal/markdown"
 	"github.com/lapis2411/todo/internal/models"
+	"github.com/lapis2411/todo/internal/quickadd"
 	"github.com/lapis2411/todo/internal/storage"
 	"github.com/lapis2411/todo/internal/ui"
 )
@@ -20,33 +26,107 @@ const (
 	WindowHeight = 600
 	HeaderHeight = 80
 	FooterHeight = 60
+	TodoHeight   = 50
+
+	// The window cannot be made narrower than the footer row needs, so that
+	// the todo count fits next to the buttons at the default zoom
+	MinWindowWidth  = 760
+	MinWindowHeight = 400
+
+	// Todos are saved once changes pause for saveDelay, and at most
+	// maxSaveDelay after the first unsaved change
+	saveDelay    = 250 * time.Millisecond
+	maxSaveDelay = 2 * time.Second
 )
 
 type Game struct {
 	todos         models.TodoList
 	currentFilter models.FilterType
+	searchQuery   string
+	dueFilter     *calendar.Date // Day the list is limited to, if any
 	storage       storage.Storage
+	saver         *storage.AsyncStorage // Writes the todos in the background
 	uiManager     *UIManager
 	error         string
+	openURL       func(url string) error
+	keymap        *keymap.Keymap
+	showHelp      bool
+	helpScroll    int // First shortcut shown in the help overlay
+	settings      storage.Settings
+	settingsPath  string
+	themes        []*ui.Theme
+	deviceScale   func() float64
+	canvas        *ebiten.Image
+	undoStack     []*undoStep
+	clock         models.Clock
+	ids           models.IDGenerator // Makes the IDs of new todos
+	input         ui.InputSource     // Where Update reads the mouse and keys
+	recorder      *recorder          // Records the session, when recording
+	replay        *replay            // Drives the game, when replaying
+	lifecycle     *lifecycle         // Requests to quit
+	draftsPath    string
+
+	archive *collection // Completed todos cleared from the list
+	trash   *collection // Deleted todos
 }
 
 type UIManager struct {
 	inputBox      *ui.TextBox
 	addButton     *ui.Button
+	searchBox     *ui.TextBox
 	filterButtons map[models.FilterType]*ui.Button
-	todoItems     []*ui.TodoItem
-	scrollOffset  int
+	todos         []models.Todo // The todos shown in the list
+	list          *ui.ListView
+	expandedNotes map[string]bool
+	edits         map[string]todoEdit // Unsaved edits of dropped rows, by todo ID
+	focus         *ui.FocusManager
+	palette       *ui.CommandPalette
+	todoList      *todoList
+	selectedID    string          // The cursor row of the selection
+	selectedIDs   map[string]bool // All selected rows, including the cursor
+	anchorID      string          // Where Shift ranges start
+	bulkBar       *bulkBar
+	archive       *collectionView
+	trash         *collectionView
+	clearButton   *ui.Button
+	archiveButton *ui.Button
+	trashButton   *ui.Button
+	details       *detailsView
+	statsButton   *ui.Button
+	dashboard     *dashboardView
+	board         *boardView
+	calendar      *calendarView
+	viewButton    *ui.Button
+	preview       quickadd.Result // Parsed from the input as it is typed
 	windowWidth   int
 	windowHeight  int
+	layout        layout.Node
+	headerRect    image.Rectangle
+	contentRect   image.Rectangle // Between the header and the footer
+	footerRect    image.Rectangle
+	countRect     image.Rectangle // The todo count in the footer
 }
 
 func NewGame(storagePath string) (*Game, error) {
-	fileStorage := storage.NewFileStorage(storagePath)
+	return newGame(storagePath, models.SystemClock, models.UUIDs)
+}
+
+// newGame creates the game with the clock and the ID generator it runs on,
+// which tests replace with fakes.
+func newGame(storagePath string, clock models.TimerClock, ids models.IDGenerator) (*Game, error) {
+	saver := storage.NewAsyncStorage(storage.NewFileStorage(storagePath), clock, saveDelay, maxSaveDelay)
 	
 	game := &Game{
 		todos:         models.TodoList{Todos: []models.Todo{}},
 		currentFilter: models.FilterAll,
-		storage:       fileStorage,
+		storage:       saver,
+		saver:         saver,
+		openURL:       openInBrowser,
+		deviceScale:   deviceScaleFactor,
+		clock:         clock,
+		ids:           ids,
+		input:         ui.EbitenInput,
+		lifecycle:     newLifecycle(),
 	}
 
 	// Load existing todos
@@ -57,8 +137,35 @@ func NewGame(storagePath string) (*Game, error) {
 		game.todos.Todos = todos
 	}
 
+	// Load keyboard shortcuts, with user overrides kept next to the todos
+	dataDir := filepath.Dir(storagePath)
+	game.keymap = game.newKeymap(filepath.Join(dataDir, "keymap.json"))
+
+	// Load settings and apply the saved theme
+	game.settingsPath = filepath.Join(dataDir, "settings.json")
+	if settings, err := storage.LoadSettings(game.settingsPath); err != nil {
+		game.error = fmt.Sprintf("Failed to load settings: %v", err)
+	} else {
+		game.settings = settings
+	}
+	game.loadThemes(filepath.Join(dataDir, "themes"))
+
+	// Archived and deleted todos live in their own files, read when first
+	// needed
+	game.archive = newCollection(storage.NewFileStorage(filepath.Join(dataDir, "archive.json")))
+	game.trash = newCollection(storage.NewFileStorage(filepath.Join(dataDir, "trash.json")))
+	game.autoArchive()
+	game.purgeTrash()
+
 	game.uiManager = game.createUIManager()
+	game.relayout()
 	game.updateTodoItems()
+	game.updateFocusOrder()
+	game.uiManager.focus.Focus(game.uiManager.inputBox)
+
+	// Reopen the edits left open when the game last quit
+	game.draftsPath = filepath.Join(dataDir, draftsFile)
+	game.restoreDrafts()
 
 	return game, nil
 }
@@ -66,18 +173,38 @@ func NewGame(storagePath string) (*Game, error) {
 func (g *Game) createUIManager() *UIManager {
 	uiMgr := &UIManager{
 		filterButtons: make(map[models.FilterType]*ui.Button),
+		expandedNotes: make(map[string]bool),
+		edits:         make(map[string]todoEdit),
+		selectedIDs:   make(map[string]bool),
+		focus:         ui.NewFocusManager(),
 		windowWidth:   WindowWidth,
 		windowHeight:  WindowHeight,
 	}
 
+	uiMgr.todoList = &todoList{g: g}
+
+	// Create the todo list; rows are created as they scroll into view
+	uiMgr.list = ui.NewListView(0, 0, 0, 0)
+	uiMgr.list.RowHeight = g.todoRowHeight
+	uiMgr.list.NewRow = g.newTodoItem
+	uiMgr.list.DropRow = g.keepEdit
+
+	// Widgets are created at the origin; buildLayout positions them
+
+	// Create command palette (initially hidden)
+	uiMgr.palette = ui.NewCommandPalette(0, 0, 500)
+
 	// Create input textbox
-	uiMgr.inputBox = ui.NewTextBox(20, 20, 500, 35, "Add a new todo...")
+	uiMgr.inputBox = ui.NewTextBox(0, 0, 0, 0, "Add a new todo...")
 
 	// Create add button
-	uiMgr.addButton = ui.NewButton(540, 20, 100, 35, "Add", func() {
+	uiMgr.addButton = ui.NewButton(0, 0, 0, 0, "Add", func() {
 		g.addTodo()
 	})
 
+	// Create search textbox
+	uiMgr.searchBox = ui.NewTextBox(0, 0, 0, 0, "Search...")
+
 	// Create filter buttons
 	filterLabels := map[models.FilterType]string{
 		models.FilterAll:       "All",
@@ -85,48 +212,102 @@ func (g *Game) createUIManager() *UIManager {
 		models.FilterCompleted: "Completed",
 	}
 
-	buttonWidth := 80
-	startX := 20
 	for filter, label := range filterLabels {
 		button := ui.NewButton(
-			startX+int(filter)*buttonWidth, WindowHeight-FooterHeight+20, 
-			buttonWidth-5, 25,
+			0, 0, 0, 0,
 			label,
 			func(f models.FilterType) func() {
 				return func() { g.setFilter(f) }
 			}(filter),
 		)
 		
-		// Set initial colors
+		// Highlight the current filter
 		if filter == g.currentFilter {
-			button.SetColors(
-				color.RGBA{0, 123, 255, 255},   // Active blue
-				color.RGBA{0, 86, 179, 255},    // Darker blue
-				color.RGBA{255, 255, 255, 255}, // White text
-			)
+			button.SetVariant(ui.ButtonPrimary)
 		} else {
-			button.SetColors(
-				color.RGBA{108, 117, 125, 255}, // Gray
-				color.RGBA{90, 98, 104, 255},    // Darker gray
-				color.RGBA{255, 255, 255, 255}, // White text
-			)
+			button.SetVariant(ui.ButtonSecondary)
 		}
 		
 		uiMgr.filterButtons[filter] = button
 	}
 
+	// Create the toolbar for actions on several selected todos
+	uiMgr.bulkBar = g.newBulkBar()
+
+	// Create the archive and trash buttons and panels (initially hidden)
+	uiMgr.clearButton = ui.NewButton(0, 0, 0, 0, "Clear completed", func() {
+		g.clearCompleted()
+	})
+	uiMgr.clearButton.SetVariant(ui.ButtonSecondary)
+	uiMgr.archiveButton = ui.NewButton(0, 0, 0, 0, "Archive", func() {
+		g.openArchive()
+	})
+	uiMgr.archiveButton.SetVariant(ui.ButtonSecondary)
+	uiMgr.trashButton = ui.NewButton(0, 0, 0, 0, "Trash", func() {
+		g.openTrash()
+	})
+	uiMgr.trashButton.SetVariant(ui.ButtonSecondary)
+	uiMgr.archive = g.newArchiveView()
+	uiMgr.trash = g.newTrashView()
+
+	// Create the todo details panel (initially hidden)
+	uiMgr.details = newDetailsView()
+
+	// Create the statistics button and dashboard (initially hidden)
+	uiMgr.statsButton = ui.NewButton(0, 0, 0, 0, "Stats", func() {
+		g.openDashboard()
+	})
+	uiMgr.statsButton.SetVariant(ui.ButtonSecondary)
+	uiMgr.dashboard = newDashboardView()
+
+	// Create the board, shown instead of the list when chosen, and the
+	// button switching between them
+	uiMgr.board = g.newBoardView()
+	viewLabel := "Board"
+	if uiMgr.board.visible {
+		viewLabel = "List"
+	}
+	uiMgr.viewButton = ui.NewButton(0, 0, 0, 0, viewLabel, func() {
+		g.setBoardVisible(!g.uiManager.board.visible)
+	})
+	uiMgr.viewButton.SetVariant(ui.ButtonSecondary)
+
+	// Create the calendar of due dates (initially hidden)
+	uiMgr.calendar = g.newCalendarView()
+
+	// Text boxes blink their cursors by the game's clock
+	for _, box := range []*ui.TextBox{uiMgr.inputBox, uiMgr.searchBox, uiMgr.palette.Input, uiMgr.bulkBar.tagBox} {
+		box.Clock = g.clock
+	}
+
+	uiMgr.layout = buildLayout(uiMgr)
+
 	return uiMgr
 }
 
+// addTodo adds the todo typed into the input, with the due time,
+// priority, tags and recurrence parsed out of the text.
 func (g *Game) addTodo() {
-	text := strings.TrimSpace(g.uiManager.inputBox.GetText())
-	if text == "" {
+	parsed := quickadd.Parse(g.uiManager.inputBox.GetText(), g.clock.Now())
+	if parsed.Text == "" {
 		g.error = "Todo text cannot be empty"
 		return
 	}
 
-	g.todos.AddTodo(text)
+	g.todos.AddTodo(parsed.Text, g.clock, g.ids)
+	todo := &g.todos.Todos[len(g.todos.Todos)-1]
+	todo.Due = parsed.Due
+	todo.Priority = parsed.Priority
+	todo.Tags = parsed.Tags
+	todo.Recurrence = parsed.Recurrence
+	if todo.Due == nil && g.dueFilter != nil {
+		// Give the todo the day shown, so that it stays in the list
+		due := g.dueFilter.At(23, 59, time.Local)
+		todo.Due = &due
+	}
+	g.pushUndo(&undoStep{added: []string{todo.ID}})
 	g.uiManager.inputBox.Clear()
+	g.uiManager.preview = quickadd.Result{}
 	g.error = ""
 	
 	if err := g.saveTodos(); err != nil {
@@ -136,19 +317,33 @@ func (g *Game) addTodo() {
 	g.updateTodoItems()
 }
 
-func (g *Game) deleteTodo(id string) {
-	if g.todos.DeleteTodo(id) {
-		g.error = ""
-		if err := g.saveTodos(); err != nil {
-			g.error = fmt.Sprintf("Failed to save: %v", err)
+// repeatCompleted adds the next occurrence of each recurring todo among
+// ids that is now completed, and records it in step so that undo takes it
+// back out.
+func (g *Game) repeatCompleted(step *undoStep, ids ...string) {
+	for _, id := range ids {
+		todo := g.todos.FindTodo(id)
+		if todo == nil || !todo.Completed {
+			continue
+		}
+		if next, ok := todo.Repeat(g.clock, g.ids); ok {
+			g.todos.Todos = append(g.todos.Todos, next)
+			step.added = append(step.added, next.ID)
 		}
-		g.updateTodoItems()
 	}
 }
 
+// deleteTodo moves a todo to the trash.
+func (g *Game) deleteTodo(id string) {
+	g.deleteTodos(id)
+}
+
 func (g *Game) toggleTodo(id string) {
 	if todo := g.todos.FindTodo(id); todo != nil {
-		todo.Toggle()
+		step := g.captureUndo(id)
+		todo.Toggle(g.clock.Now())
+		g.repeatCompleted(step, id)
+		g.pushUndo(step)
 		g.error = ""
 		if err := g.saveTodos(); err != nil {
 			g.error = fmt.Sprintf("Failed to save: %v", err)
@@ -164,7 +359,30 @@ func (g *Game) editTodo(id, newText string) {
 	}
 	
 	if todo := g.todos.FindTodo(id); todo != nil {
-		todo.SetText(newText)
+		g.pushUndo(g.captureUndo(id))
+		todo.SetText(newText, g.clock.Now())
+		g.error = ""
+		if err := g.saveTodos(); err != nil {
+			g.error = fmt.Sprintf("Failed to save: %v", err)
+		}
+		g.updateTodoItems()
+	}
+}
+
+func (g *Game) toggleNotes(id string) {
+	g.uiManager.expandedNotes[id] = !g.uiManager.expandedNotes[id]
+	g.updateTodoItems()
+	if i := g.indexOfTodo(id); i >= 0 {
+		g.todoItem(i).FocusNotes()
+		g.uiManager.list.ScrollToRow(i)
+	}
+}
+
+func (g *Game) saveNotes(id, notes string) {
+	if todo := g.todos.FindTodo(id); todo != nil {
+		g.pushUndo(g.captureUndo(id))
+		todo.SetNotes(notes, g.clock.Now())
+		delete(g.uiManager.expandedNotes, id)
 		g.error = ""
 		if err := g.saveTodos(); err != nil {
 			g.error = fmt.Sprintf("Failed to save: %v", err)
@@ -173,8 +391,58 @@ func (g *Game) editTodo(id, newText string) {
 	}
 }
 
+// openLink opens a link clicked in a todo. Only links the Markdown renderer
+// considers safe are handed to the system.
+func (g *Game) openLink(url string) {
+	if !markdown.IsSafeURL(url) {
+		g.error = "Refusing to open unsafe link"
+		return
+	}
+	if err := g.openURL(url); err != nil {
+		g.error = fmt.Sprintf("Failed to open link: %v", err)
+		return
+	}
+	g.error = ""
+}
+
+func (g *Game) setSearchQuery(query string) {
+	g.searchQuery = strings.TrimSpace(query)
+	g.updateTodoItems()
+	g.uiManager.list.SetScrollOffset(0)
+}
+
+// visibleTodos returns the todos matching the current filter and search query.
+func (g *Game) visibleTodos() []models.Todo {
+	todos := g.todos.GetFilteredTodos(g.currentFilter)
+	if g.searchQuery == "" && g.dueFilter == nil {
+		return todos
+	}
+
+	var matched []models.Todo
+	for _, todo := range todos {
+		if todo.Matches(g.searchQuery) && g.isDueOnFilter(&todo) {
+			matched = append(matched, todo)
+		}
+	}
+	return matched
+}
+
+// isDueOnFilter reports whether a todo is due on the day picked in the
+// calendar, when there is one.
+func (g *Game) isDueOnFilter(todo *models.Todo) bool {
+	if g.dueFilter == nil {
+		return true
+	}
+	return todo.Due != nil && calendar.DateOf(todo.Due.In(time.Local)) == *g.dueFilter
+}
+
+// setFilter shows all, active or completed todos. Showing all todos also
+// drops the day picked in the calendar.
 func (g *Game) setFilter(filter models.FilterType) {
 	g.currentFilter = filter
+	if filter == models.FilterAll {
+		g.dueFilter = nil
+	}
 	g.updateFilterButtons()
 	g.updateTodoItems()
 }
@@ -182,82 +450,222 @@ func (g *Game) setFilter(filter models.FilterType) {
 func (g *Game) updateFilterButtons() {
 	for filter, button := range g.uiManager.filterButtons {
 		if filter == g.currentFilter {
-			button.SetColors(
-				color.RGBA{0, 123, 255, 255},   // Active blue
-				color.RGBA{0, 86, 179, 255},    // Darker blue
-				color.RGBA{255, 255, 255, 255}, // White text
-			)
+			button.SetVariant(ui.ButtonPrimary)
 		} else {
-			button.SetColors(
-				color.RGBA{108, 117, 125, 255}, // Gray
-				color.RGBA{90, 98, 104, 255},    // Darker gray
-				color.RGBA{255, 255, 255, 255}, // White text
-			)
+			button.SetVariant(ui.ButtonSecondary)
 		}
 	}
 }
 
+// updateTodoItems refreshes the list from the todos. Row widgets are
+// created by newTodoItem only for the rows in view.
 func (g *Game) updateTodoItems() {
-	filteredTodos := g.todos.GetFilteredTodos(g.currentFilter)
-	g.uiManager.todoItems = make([]*ui.TodoItem, 0, len(filteredTodos))
+	g.uiManager.todos = g.visibleTodos()
+	g.uiManager.list.SetCount(len(g.uiManager.todos))
+	g.pruneSelection()
+}
 
-	itemHeight := 50
-	startY := HeaderHeight + 10
-	
-	for i, todo := range filteredTodos {
-		y := startY + i*itemHeight - g.uiManager.scrollOffset
-		
-		todoItem := ui.NewTodoItem(&filteredTodos[i], 20, y, g.uiManager.windowWidth-40, itemHeight)
-		
-		// Setup delete button callback
-		todoItem.GetDeleteButton().OnClick = func(todoID string) func() {
-			return func() {
-				g.deleteTodo(todoID)
-			}
-		}(todo.ID)
-		
-		g.uiManager.todoItems = append(g.uiManager.todoItems, todoItem)
+// newTodoItem creates the widget for the i-th todo in the list.
+func (g *Game) newTodoItem(i int) ui.ListRow {
+	todo := &g.uiManager.todos[i]
+	todoItem := ui.NewTodoItem(todo, 0, 0, 0, TodoHeight)
+	todoItem.SetClock(g.clock)
+
+	// Setup checkbox and edit callbacks so that changes are saved
+	todoItem.Checkbox.OnClick = func(todoID string) func() {
+		return func() {
+			g.toggleTodo(todoID)
+		}
+	}(todo.ID)
+	todoItem.OnEdit = func(todoID string) func(string) {
+		return func(text string) {
+			g.editTodo(todoID, text)
+		}
+	}(todo.ID)
+
+	// Setup delete button callback
+	todoItem.GetDeleteButton().OnClick = func(todoID string) func() {
+		return func() {
+			g.deleteTodo(todoID)
+		}
+	}(todo.ID)
+
+	// Setup notes callbacks
+	todoItem.GetNotesButton().OnClick = func(todoID string) func() {
+		return func() {
+			g.toggleNotes(todoID)
+		}
+	}(todo.ID)
+	todoItem.OnNotesSave = func(todoID string) func(string) {
+		return func(notes string) {
+			g.saveNotes(todoID, notes)
+		}
+	}(todo.ID)
+	todoItem.OnNotesCancel = todoItem.GetNotesButton().OnClick
+	todoItem.OnLinkClick = g.openLink
+	if g.uiManager.expandedNotes[todo.ID] {
+		todoItem.SetExpanded(true)
+	}
+	todoItem.FocusManager = g.uiManager.focus
+	todoItem.Selected = g.uiManager.selectedIDs[todo.ID]
+	g.resumeEdit(todoItem)
+
+	return todoItem
+}
+
+// todoRowHeight returns the height of the i-th row, including the notes
+// pane when it is expanded.
+func (g *Game) todoRowHeight(i int) int {
+	if g.uiManager.expandedNotes[g.uiManager.todos[i].ID] {
+		return TodoHeight + ui.NotesPaneHeight
+	}
+	return TodoHeight
+}
+
+// todoItem returns the widget of the i-th row, creating it if needed.
+func (g *Game) todoItem(i int) *ui.TodoItem {
+	if row := g.uiManager.list.Row(i); row != nil {
+		return row.(*ui.TodoItem)
+	}
+	return nil
+}
+
+// indexOfTodo returns the row of the todo with the given ID, or -1 when
+// it is not in the list.
+func (g *Game) indexOfTodo(id string) int {
+	for i, todo := range g.uiManager.todos {
+		if todo.ID == id {
+			return i
+		}
 	}
+	return -1
 }
 
+// saveTodos schedules the todos to be saved in the background. Failures
+// show up in a later frame through checkSaveErrors.
 func (g *Game) saveTodos() error {
 	return g.storage.SaveTodos(g.todos.Todos)
 }
 
+// saveTodosNow saves the todos and waits for the write, for changes that
+// must be on disk before another file is changed.
+func (g *Game) saveTodosNow() error {
+	if err := g.saveTodos(); err != nil {
+		return err
+	}
+	return g.saver.Flush()
+}
+
+// checkSaveErrors shows a failed background save.
+func (g *Game) checkSaveErrors() {
+	select {
+	case err := <-g.saver.Errors():
+		g.error = fmt.Sprintf("Failed to save: %v", err)
+	default:
+	}
+}
+
 func (g *Game) Update() error {
+	if g.quitRequested() {
+		return g.shutdown()
+	}
+	if g.recorder != nil {
+		g.recorder.capture(g)
+	}
+	if g.replay != nil && !g.replay.step(g) {
+		return nil
+	}
+
+	g.checkSaveErrors()
+
+	// Read keyboard state once per frame for all widgets
+	in := g.input
+	ui.DefaultKeyboard.Poll(in, g.clock.Now())
+
+	// The help overlay and the palette take all input while open
+	if g.showHelp {
+		g.updateHelp(in, ui.DefaultKeyboard)
+		return nil
+	}
+	if g.uiManager.palette.Visible {
+		g.uiManager.palette.Update(in)
+		return nil
+	}
+	if v := g.openCollectionView(); v != nil {
+		v.update(in, ui.DefaultKeyboard)
+		return nil
+	}
+	if g.uiManager.details.visible {
+		g.uiManager.details.update(in, ui.DefaultKeyboard)
+		return nil
+	}
+	if g.uiManager.dashboard.visible {
+		g.uiManager.dashboard.update(in, ui.DefaultKeyboard)
+		return nil
+	}
+	if g.uiManager.calendar.visible {
+		g.uiManager.calendar.update(in, ui.DefaultKeyboard)
+		return nil
+	}
+
+	// Move focus on click and Tab before widgets see the input
+	g.updateFocusOrder()
+	if in.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		x, y := ui.CursorPosition(in)
+		g.handleFocusClick(x, y, ui.DefaultKeyboard)
+	}
+	g.uiManager.focus.HandleTab(ui.DefaultKeyboard)
+	// Escape leaves the header fields, so that the shortcuts that act on
+	// the todos apply again
+	if m := g.uiManager; m.inputBox.IsEscapePressed() || m.searchBox.IsEscapePressed() {
+		g.leaveTextField()
+	}
+	g.handleShortcuts(ui.DefaultKeyboard)
+
 	// Handle adding todo with Enter key
 	if g.uiManager.inputBox.IsEnterPressed() {
 		g.addTodo()
 	}
 
 	// Update UI components
-	g.uiManager.inputBox.Update()
-	g.uiManager.addButton.Update()
+	g.uiManager.inputBox.Update(in)
+	g.uiManager.preview = quickadd.Parse(g.uiManager.inputBox.GetText(), g.clock.Now())
+	g.uiManager.addButton.Update(in)
+	g.uiManager.searchBox.Update(in)
+	g.uiManager.viewButton.Update(in)
+	if query := strings.TrimSpace(g.uiManager.searchBox.GetText()); query != g.searchQuery {
+		g.setSearchQuery(query)
+	}
 	
 	for _, button := range g.uiManager.filterButtons {
-		button.Update()
+		button.Update(in)
 	}
-
-	// Update todo items
-	for _, item := range g.uiManager.todoItems {
-		item.Update()
-	}
-
-	// Handle scroll (simple implementation)
-	if len(g.uiManager.todoItems) > 0 {
-		_, dy := ebiten.Wheel()
-		g.uiManager.scrollOffset -= int(dy * 20)
-		if g.uiManager.scrollOffset < 0 {
-			g.uiManager.scrollOffset = 0
-		}
+	g.uiManager.clearButton.Update(in)
+	g.uiManager.archiveButton.Update(in)
+	g.uiManager.trashButton.Update(in)
+	g.uiManager.statsButton.Update(in)
+	g.updateBulkBar(in)
+
+	// Update the list, which scrolls and updates the rows in view, or the
+	// board shown instead
+	if g.uiManager.board.visible {
+		g.uiManager.board.update(in, ui.DefaultKeyboard)
+	} else {
+		g.uiManager.list.Update(in)
+		g.handleListKeys(ui.DefaultKeyboard)
 	}
 
 	return nil
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
+	g.drawScaled(screen)
+}
+
+// drawUI draws the whole UI in UI pixels.
+func (g *Game) drawUI(screen *ebiten.Image) {
 	// Clear screen with background color
-	screen.Fill(color.RGBA{248, 249, 250, 255})
+	screen.Fill(ui.CurrentTheme().Background)
 
 	// Draw header
 	g.drawHeader(screen)
@@ -268,103 +676,185 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	// Draw footer
 	g.drawFooter(screen)
 
+	// Draw focus ring
+	g.uiManager.focus.Draw(screen)
+
 	// Draw error message if any
 	if g.error != "" {
 		g.drawError(screen)
 	}
+
+	// Draw overlays on top of everything
+	g.uiManager.palette.Draw(screen)
+	if v := g.openCollectionView(); v != nil {
+		v.draw(screen, g.uiManager.windowWidth, g.uiManager.windowHeight)
+	}
+	if g.uiManager.details.visible {
+		g.uiManager.details.draw(screen, g.uiManager.windowWidth, g.uiManager.windowHeight)
+	}
+	if g.uiManager.dashboard.visible {
+		g.uiManager.dashboard.draw(screen, g.uiManager.windowWidth, g.uiManager.windowHeight)
+	}
+	if g.uiManager.calendar.visible {
+		g.uiManager.calendar.draw(screen, g.uiManager.windowWidth, g.uiManager.windowHeight)
+	}
+	if g.showHelp {
+		g.drawHelp(screen)
+	}
 }
 
 func (g *Game) drawHeader(screen *ebiten.Image) {
+	theme := ui.CurrentTheme()
+
+	header := g.uiManager.headerRect
+
 	// Draw header background
-	headerColor := color.RGBA{255, 255, 255, 255}
-	ebitenutil.DrawRect(screen, 0, 0, float64(g.uiManager.windowWidth), HeaderHeight, headerColor)
+	headerColor := theme.Surface
+	ui.DrawRect(screen, float64(header.Min.X), float64(header.Min.Y), float64(header.Dx()), float64(header.Dy()), headerColor)
 
 	// Draw title
 	title := "Todo List"
 	titleBounds := text.BoundString(basicfont.Face7x13, title)
-	titleX := (g.uiManager.windowWidth - (titleBounds.Max.X - titleBounds.Min.X)) / 2
-	titleY := 15
-	text.Draw(screen, title, basicfont.Face7x13, titleX, titleY, color.RGBA{33, 37, 41, 255})
+	titleX := header.Min.X + (header.Dx()-(titleBounds.Max.X-titleBounds.Min.X))/2
+	titleY := header.Min.Y + 15
+	ui.DrawText(screen, title, titleX, titleY, theme.Text)
 
 	// Draw input and add button
 	g.uiManager.inputBox.Draw(screen)
+	g.drawPreview(screen)
 	g.uiManager.addButton.Draw(screen)
+	g.uiManager.searchBox.Draw(screen)
+	g.uiManager.viewButton.Draw(screen)
 
 	// Draw header border
-	borderColor := color.RGBA{200, 200, 200, 255}
-	ebitenutil.DrawRect(screen, 0, HeaderHeight-1, float64(g.uiManager.windowWidth), 1, borderColor)
+	borderColor := theme.Border
+	ui.DrawRect(screen, float64(header.Min.X), float64(header.Max.Y-1), float64(header.Dx()), 1, borderColor)
+}
+
+// drawPreview shows what the input parses into below it, so that dates
+// and tags can be checked before the todo is added.
+func (g *Game) drawPreview(screen *ebiten.Image) {
+	preview := g.uiManager.preview
+	if !preview.HasFields() {
+		return
+	}
+	var parts []string
+	if preview.Due != nil {
+		parts = append(parts, "Due "+preview.Due.Format("Mon 1/2 15:04"))
+	}
+	if preview.Recurrence != nil {
+		parts = append(parts, preview.Recurrence.String())
+	}
+	if preview.Priority != models.PriorityNone {
+		parts = append(parts, preview.Priority.String())
+	}
+	if len(preview.Tags) > 0 {
+		parts = append(parts, "#"+strings.Join(preview.Tags, " #"))
+	}
+	input := g.uiManager.inputBox.Bounds()
+	line := ui.TruncateText(strings.Join(parts, "   "), input.Dx()-4)
+	ui.DrawText(screen, line, input.Min.X+4, input.Max.Y+14, ui.CurrentTheme().TextMuted)
 }
 
 func (g *Game) drawContent(screen *ebiten.Image) {
-	// Draw todo items
-	for _, item := range g.uiManager.todoItems {
-		if item.Y >= HeaderHeight && item.Y < g.uiManager.windowHeight-FooterHeight {
-			item.Draw(screen)
-		}
+	// Draw todo items, as cards on the board when it is shown
+	if g.uiManager.board.visible {
+		g.uiManager.board.draw(screen)
+		return
 	}
+	g.uiManager.list.Draw(screen)
+	g.drawBulkBar(screen)
 
 	// Draw empty state message if no todos
-	filteredTodos := g.todos.GetFilteredTodos(g.currentFilter)
-	if len(filteredTodos) == 0 {
+	if g.uiManager.list.Count() == 0 {
 		message := "No todos yet. Add one above!"
-		if g.currentFilter == models.FilterActive {
+		if g.searchQuery != "" {
+			message = "No todos match your search!"
+		} else if g.dueFilter != nil {
+			message = "No todos due on " + g.dueFilter.String() + "!"
+		} else if g.currentFilter == models.FilterActive {
 			message = "No active todos!"
 		} else if g.currentFilter == models.FilterCompleted {
 			message = "No completed todos!"
 		}
 
 		messageBounds := text.BoundString(basicfont.Face7x13, message)
-		messageX := (g.uiManager.windowWidth - (messageBounds.Max.X - messageBounds.Min.X)) / 2
-		messageY := HeaderHeight + 50
-		text.Draw(screen, message, basicfont.Face7x13, messageX, messageY, color.RGBA{108, 117, 125, 255})
+		content := g.uiManager.contentRect
+		messageX := content.Min.X + (content.Dx()-(messageBounds.Max.X-messageBounds.Min.X))/2
+		messageY := content.Min.Y + 50
+		ui.DrawText(screen, message, messageX, messageY, ui.CurrentTheme().TextMuted)
 	}
 }
 
 func (g *Game) drawFooter(screen *ebiten.Image) {
-	footerY := float64(g.uiManager.windowHeight - FooterHeight)
-	
+	footer := g.uiManager.footerRect
+	theme := ui.CurrentTheme()
+
 	// Draw footer background
-	footerColor := color.RGBA{255, 255, 255, 255}
-	ebitenutil.DrawRect(screen, 0, footerY, float64(g.uiManager.windowWidth), FooterHeight, footerColor)
+	footerColor := theme.Surface
+	ui.DrawRect(screen, float64(footer.Min.X), float64(footer.Min.Y), float64(footer.Dx()), float64(footer.Dy()), footerColor)
 
 	// Draw footer border
-	borderColor := color.RGBA{200, 200, 200, 255}
-	ebitenutil.DrawRect(screen, 0, footerY, float64(g.uiManager.windowWidth), 1, borderColor)
+	borderColor := theme.Border
+	ui.DrawRect(screen, float64(footer.Min.X), float64(footer.Min.Y), float64(footer.Dx()), 1, borderColor)
 
-	// Draw filter buttons
+	// Draw filter and archive buttons
 	for _, button := range g.uiManager.filterButtons {
 		button.Draw(screen)
 	}
+	g.uiManager.clearButton.Draw(screen)
+	g.uiManager.archiveButton.Draw(screen)
+	g.uiManager.trashButton.Draw(screen)
+	g.uiManager.statsButton.Draw(screen)
 
 	// Draw todo count
-	filteredCount := len(g.todos.GetFilteredTodos(g.currentFilter))
+	filteredCount := g.uiManager.list.Count()
 	totalCount := len(g.todos.Todos)
 	countText := fmt.Sprintf("%d of %d todos", filteredCount, totalCount)
-	
-	countX := g.uiManager.windowWidth - 150
-	countY := int(footerY) + 35
-	text.Draw(screen, countText, basicfont.Face7x13, countX, countY, color.RGBA{108, 117, 125, 255})
+	if g.dueFilter != nil {
+		countText = fmt.Sprintf("%d of %d due %d/%d", filteredCount, totalCount, g.dueFilter.Month, g.dueFilter.Day)
+	}
+
+	// Right-align the count in its cell, falling back to the bare numbers
+	// and then to nothing when the window is too narrow
+	cell := g.uiManager.countRect
+	for _, s := range []string{countText, fmt.Sprintf("%d/%d", filteredCount, totalCount)} {
+		width := text.BoundString(basicfont.Face7x13, s).Dx()
+		if width <= cell.Dx() {
+			ui.DrawText(screen, s, cell.Max.X-width, cell.Min.Y+15, theme.TextMuted)
+			break
+		}
+	}
 }
 
 func (g *Game) drawError(screen *ebiten.Image) {
+	theme := ui.CurrentTheme()
+
+	list := g.uiManager.list.Bounds()
+
 	// Draw error background
-	errorY := HeaderHeight + 10
+	errorY := list.Min.Y
 	errorHeight := 30
-	ebitenutil.DrawRect(screen, 20, float64(errorY), float64(g.uiManager.windowWidth-40), float64(errorHeight), color.RGBA{248, 215, 218, 255})
+	ui.DrawRect(screen, float64(list.Min.X), float64(errorY), float64(list.Dx()), float64(errorHeight), theme.ErrorSurface)
 
 	// Draw error border
-	borderColor := color.RGBA{220, 53, 69, 255}
-	ebitenutil.DrawRect(screen, 20, float64(errorY), float64(g.uiManager.windowWidth-40), 1, borderColor)
-	ebitenutil.DrawRect(screen, 20, float64(errorY+errorHeight-1), float64(g.uiManager.windowWidth-40), 1, borderColor)
+	borderColor := theme.Danger
+	ui.DrawRect(screen, float64(list.Min.X), float64(errorY), float64(list.Dx()), 1, borderColor)
+	ui.DrawRect(screen, float64(list.Min.X), float64(errorY+errorHeight-1), float64(list.Dx()), 1, borderColor)
 
 	// Draw error text
-	errorX := 30
+	errorX := list.Min.X + 10
 	errorTextY := errorY + 20
-	text.Draw(screen, g.error, basicfont.Face7x13, errorX, errorTextY, color.RGBA{114, 28, 36, 255})
+	ui.DrawText(screen, g.error, errorX, errorTextY, theme.ErrorText)
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	g.uiManager.windowWidth = outsideWidth
-	g.uiManager.windowHeight = outsideHeight
-	return outsideWidth, outsideHeight
+	if g.recorder != nil {
+		g.recorder.size = image.Pt(outsideWidth, outsideHeight)
+	}
+	// A replay keeps the recorded window size
+	if g.replay != nil && g.replay.size != (image.Point{}) {
+		outsideWidth, outsideHeight = g.replay.size.X, g.replay.size.Y
+	}
+	return g.applyScale(outsideWidth, outsideHeight)
 }
\ No newline at end of file
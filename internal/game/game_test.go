@@ -0,0 +1,1501 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/calendar"
+	"github.com/lapis2411/todo/internal/models"
+	"github.com/lapis2411/todo/internal/storage"
+	"github.com/lapis2411/todo/internal/ui"
+)
+
+// testNow is the time on the clock of test games, a Wednesday morning.
+var testNow = time.Date(2024, time.March, 13, 10, 30, 0, 0, time.Local)
+
+func newTestGame(t *testing.T) *Game {
+	t.Helper()
+	return newTestGameIn(t, t.TempDir())
+}
+
+// newTestGameIn creates a game on the data in dir, with its clock stopped
+// at testNow, todo IDs "todo-1", "todo-2" and so on, and no input until a
+// test scripts some.
+func newTestGameIn(t *testing.T, dir string) *Game {
+	t.Helper()
+	g, err := newGame(filepath.Join(dir, "todos.json"), models.NewFakeClock(testNow), &models.SequentialIDs{Prefix: "todo-"})
+	if err != nil {
+		t.Fatalf("Failed to create game: %v", err)
+	}
+	g.deviceScale = func() float64 { return 1 }
+	g.input = ui.NewScriptedInput()
+	// Write any pending save before the directory is removed
+	t.Cleanup(func() { g.Close() })
+	return g
+}
+
+func TestOpenLink(t *testing.T) {
+	g := newTestGame(t)
+	var opened []string
+	g.openURL = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+
+	g.openLink("https://example.com/ticket/1")
+	g.openLink("javascript:alert(1)")
+
+	if len(opened) != 1 || opened[0] != "https://example.com/ticket/1" {
+		t.Errorf("Expected only the safe link to be opened, got %v", opened)
+	}
+	if g.error == "" {
+		t.Error("Expected an error message for the unsafe link")
+	}
+}
+
+func TestOpenLinkFailure(t *testing.T) {
+	g := newTestGame(t)
+	g.openURL = func(string) error { return errors.New("no browser") }
+
+	g.openLink("https://example.com")
+
+	if g.error == "" {
+		t.Error("Expected an error message when opening fails")
+	}
+}
+
+func TestTodoItemLinkClickRoutesToGame(t *testing.T) {
+	g := newTestGame(t)
+	var opened []string
+	g.openURL = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+
+	g.uiManager.inputBox.SetText("Read [spec](https://example.com/spec)")
+	g.addTodo()
+
+	if g.uiManager.list.Count() != 1 {
+		t.Fatalf("Expected 1 todo item, got %d", g.uiManager.list.Count())
+	}
+	item := g.todoItem(0)
+	item.OnLinkClick("https://example.com/spec")
+
+	if len(opened) != 1 {
+		t.Errorf("Expected the link to be opened through the game, got %v", opened)
+	}
+}
+
+// pressKeys runs one frame with keys held, then a frame with them released.
+func pressKeys(g *Game, keys ...ebiten.Key) {
+	keyFrame(g, keys, func() { g.handleListKeys(ui.DefaultKeyboard) })
+}
+
+func TestKeyboardListNavigation(t *testing.T) {
+	g := newTestGame(t)
+	for _, text := range []string{"first", "second", "third"} {
+		g.uiManager.inputBox.SetText(text)
+		g.addTodo()
+	}
+	g.updateFocusOrder()
+	g.uiManager.focus.Focus(g.uiManager.todoList)
+
+	if g.selectedIndex() != 0 {
+		t.Fatalf("Expected focusing the list to select the first row, got %d", g.selectedIndex())
+	}
+
+	pressKeys(g, ebiten.KeyArrowDown)
+	pressKeys(g, ebiten.KeySpace)
+	if todo := g.todos.FindTodo(g.uiManager.selectedID); todo == nil || todo.Text != "second" || !todo.Completed {
+		t.Fatalf("Expected Space to complete the second todo, got %+v", todo)
+	}
+
+	// The toggle must be saved, not just applied to the row
+	saved, err := g.storage.LoadTodos()
+	if err != nil {
+		t.Fatalf("Failed to load todos: %v", err)
+	}
+	if !saved[1].Completed {
+		t.Error("Expected the toggled todo to be saved")
+	}
+
+	pressKeys(g, ebiten.KeyDelete)
+	if len(g.todos.Todos) != 2 {
+		t.Fatalf("Expected Delete to remove the selected todo, got %d todos", len(g.todos.Todos))
+	}
+	if todo := g.todos.FindTodo(g.uiManager.selectedID); todo == nil || todo.Text != "third" {
+		t.Errorf("Expected the selection to move to the next todo, got %+v", todo)
+	}
+
+	pressKeys(g, ebiten.KeyF2)
+	item := g.todoItem(g.selectedIndex())
+	if !item.Editing || !g.uiManager.focus.IsFocused(item.EditTextBox) {
+		t.Error("Expected F2 to start editing with focus in the edit box")
+	}
+}
+
+func TestFocusFallsBackToListWhenEditorCloses(t *testing.T) {
+	g := newTestGame(t)
+	g.uiManager.inputBox.SetText("first")
+	g.addTodo()
+	g.updateFocusOrder()
+	g.uiManager.focus.Focus(g.uiManager.todoList)
+
+	item := g.todoItem(0)
+	item.StartEditing()
+	item.Editing = false
+	g.updateFocusOrder()
+
+	if !g.uiManager.focus.IsFocused(g.uiManager.todoList) {
+		t.Error("Expected focus to return to the list")
+	}
+	if item.EditTextBox.Focused {
+		t.Error("Expected the closed edit box to lose focus")
+	}
+}
+
+// pressShortcut runs one frame of shortcut handling with keys held.
+func pressShortcut(g *Game, keys ...ebiten.Key) {
+	keyFrame(g, keys, func() {
+		if g.showHelp {
+			g.updateHelp(g.input, ui.DefaultKeyboard)
+		} else {
+			g.handleShortcuts(ui.DefaultKeyboard)
+		}
+	})
+}
+
+// keyFrame runs f in one frame with keys held at the time of the game's
+// clock, then releases them a second later.
+func keyFrame(g *Game, keys []ebiten.Key, f func()) {
+	clock := g.clock.(*models.FakeClock)
+	ui.DefaultKeyboard.Update(clock.Now(), keys, nil)
+	f()
+	clock.Advance(time.Second)
+	ui.DefaultKeyboard.Update(clock.Now(), nil, nil)
+}
+
+func TestShortcuts(t *testing.T) {
+	g := newTestGame(t)
+
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.KeyF)
+	if !g.uiManager.focus.IsFocused(g.uiManager.searchBox) {
+		t.Error("Expected Ctrl+F to focus the search box")
+	}
+
+	// Only shortcuts that move focus or open an overlay fire while typing
+	pressShortcut(g, ebiten.KeyAltLeft, ebiten.Key2)
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.KeyD)
+	if g.currentFilter != models.FilterAll || g.uiManager.dashboard.visible {
+		t.Errorf("Expected Alt+2 and Ctrl+D to be ignored while typing, got filter %v dashboard=%v", g.currentFilter, g.uiManager.dashboard.visible)
+	}
+	box := g.uiManager.searchBox
+	play(g, ui.KeyPress(image.Pt(box.X, box.Y), ebiten.KeyEscape)...)
+	if g.uiManager.focus.IsFocused(box) {
+		t.Fatal("Expected Escape to leave the search box")
+	}
+	pressShortcut(g, ebiten.KeyAltLeft, ebiten.Key2)
+	if g.currentFilter != models.FilterActive {
+		t.Errorf("Expected Alt+2 to show active todos, got filter %v", g.currentFilter)
+	}
+
+	pressShortcut(g, ebiten.KeyF1)
+	if !g.showHelp {
+		t.Fatal("Expected F1 to open the help overlay")
+	}
+	pressShortcut(g, ebiten.KeyEscape)
+	if g.showHelp {
+		t.Error("Expected Escape to close the help overlay")
+	}
+}
+
+func TestHelpFitsTheWindow(t *testing.T) {
+	g := newTestGame(t)
+	g.Layout(MinWindowWidth, MinWindowHeight)
+	pressShortcut(g, ebiten.KeyF1)
+
+	rows := len(g.helpRows())
+	panel, visible := g.helpLayout(rows)
+	if window := image.Rect(0, 0, MinWindowWidth, MinWindowHeight); !panel.In(window) {
+		t.Fatalf("Expected the help panel inside the %v window, got %v", window, panel)
+	}
+	if visible >= rows {
+		t.Fatalf("Expected the %d shortcuts not to fit at once, got %d shown", rows, visible)
+	}
+
+	pressShortcut(g, ebiten.KeyArrowDown)
+	if g.helpScroll != 1 {
+		t.Errorf("Expected Down to scroll one row, got %d", g.helpScroll)
+	}
+	pressShortcut(g, ebiten.KeyEnd)
+	if g.helpScroll != rows-visible {
+		t.Errorf("Expected End to show the last page from row %d, got %d", rows-visible, g.helpScroll)
+	}
+	pressShortcut(g, ebiten.KeyPageUp)
+	pressShortcut(g, ebiten.KeyPageUp)
+	if g.helpScroll != 0 {
+		t.Errorf("Expected Page Up to stop at the first row, got %d", g.helpScroll)
+	}
+	if !g.showHelp {
+		t.Error("Expected scrolling to keep the overlay open")
+	}
+}
+
+func TestKeymapFileOverridesDefaults(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "keymap.json"), []byte(`{"search": ["Ctrl+K"]}`), 0644); err != nil {
+		t.Fatalf("Failed to write keymap: %v", err)
+	}
+	g := newTestGameIn(t, dir)
+
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.KeyF)
+	if g.uiManager.focus.IsFocused(g.uiManager.searchBox) {
+		t.Error("Expected the default Ctrl+F to be replaced")
+	}
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.KeyK)
+	if !g.uiManager.focus.IsFocused(g.uiManager.searchBox) {
+		t.Error("Expected Ctrl+K to focus the search box")
+	}
+}
+
+func TestKeymapConflictIsReported(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "keymap.json"), []byte(`{"search": ["Ctrl+N"]}`), 0644); err != nil {
+		t.Fatalf("Failed to write keymap: %v", err)
+	}
+	g := newTestGameIn(t, dir)
+
+	if g.error == "" {
+		t.Error("Expected the conflicting keymap to be reported")
+	}
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.KeyF)
+	if !g.uiManager.focus.IsFocused(g.uiManager.searchBox) {
+		t.Error("Expected the defaults to stay in effect")
+	}
+}
+
+func TestKeymapCannotTakeWidgetChords(t *testing.T) {
+	for _, chord := range []string{"Ctrl+C", "Ctrl+A", "Delete", "F2", "Space"} {
+		dir := t.TempDir()
+		data := fmt.Sprintf(`{"dashboard": [%q]}`, chord)
+		if err := os.WriteFile(filepath.Join(dir, "keymap.json"), []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write keymap: %v", err)
+		}
+		g := newTestGameIn(t, dir)
+		if !strings.Contains(g.error, "reserved") {
+			t.Errorf("Expected binding %s to be reported as reserved, got %q", chord, g.error)
+		}
+	}
+}
+
+func TestPaletteRevealsTodo(t *testing.T) {
+	g := newTestGame(t)
+	for _, text := range []string{"Buy **milk**", "Walk the dog"} {
+		g.uiManager.inputBox.SetText(text)
+		g.addTodo()
+	}
+	g.toggleTodo(g.todos.Todos[0].ID)
+	g.setFilter(models.FilterActive)
+
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.KeyShiftLeft, ebiten.KeyP)
+	palette := g.uiManager.palette
+	if !palette.Visible || !g.uiManager.focus.IsFocused(palette.Input) {
+		t.Fatal("Expected Ctrl+Shift+P to open the palette with focus in its input")
+	}
+
+	palette.Input.SetText("milk")
+	keyFrame(g, nil, func() { palette.Update(g.input) })
+	if results := palette.Results(); len(results) == 0 || results[0].Label != "Buy milk" {
+		t.Fatalf("Expected the todo to be listed without markup, got %v", results)
+	}
+
+	keyFrame(g, []ebiten.Key{ebiten.KeyEnter}, func() { palette.Update(g.input) })
+
+	if g.currentFilter != models.FilterAll {
+		t.Error("Expected the filter to be reset to show the completed todo")
+	}
+	if !g.uiManager.focus.IsFocused(g.uiManager.todoList) {
+		t.Error("Expected focus to move to the list")
+	}
+	if todo := g.todos.FindTodo(g.uiManager.selectedID); todo == nil || todo.Text != "Buy **milk**" {
+		t.Errorf("Expected the todo to be selected, got %+v", todo)
+	}
+}
+
+func TestSwitchThemePersists(t *testing.T) {
+	t.Cleanup(func() { ui.SetTheme(ui.LightTheme()) })
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "themes"), 0755); err != nil {
+		t.Fatalf("Failed to create themes dir: %v", err)
+	}
+	custom := `{"name": "Ocean", "base": "Dark", "colors": {"accent": "#268bd2"}}`
+	if err := os.WriteFile(filepath.Join(dir, "themes", "ocean.json"), []byte(custom), 0644); err != nil {
+		t.Fatalf("Failed to write theme: %v", err)
+	}
+
+	g := newTestGameIn(t, dir)
+	if g.error != "" {
+		t.Fatalf("Unexpected error: %s", g.error)
+	}
+	if name := ui.CurrentTheme().Name; name != "Light" {
+		t.Errorf("Expected the Light theme by default, got %q", name)
+	}
+
+	g.leaveTextField()
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.KeyT)
+	if name := ui.CurrentTheme().Name; name != "Dark" {
+		t.Errorf("Expected Ctrl+T to switch to Dark, got %q", name)
+	}
+	g.setTheme("ocean")
+
+	// The choice survives a restart
+	ui.SetTheme(ui.LightTheme())
+	if _, err := NewGame(filepath.Join(dir, "todos.json")); err != nil {
+		t.Fatalf("Failed to create game: %v", err)
+	}
+	if name := ui.CurrentTheme().Name; name != "Ocean" {
+		t.Errorf("Expected the saved Ocean theme, got %q", name)
+	}
+}
+
+func TestLayoutFollowsWindowSize(t *testing.T) {
+	g := newTestGame(t)
+	g.uiManager.inputBox.SetText("Buy milk")
+	g.addTodo()
+
+	g.Layout(1000, 700)
+	m := g.uiManager
+
+	if got := m.viewButton.Bounds().Max.X; got != 980 {
+		t.Errorf("Expected the view button to stay 20px from the right edge, got %d", got)
+	}
+	if got := m.searchBox.Bounds().Max.X; got != 900 {
+		t.Errorf("Expected the search box to move with the view button, got %d", got)
+	}
+	if got := m.addButton.Bounds().Min.X; got != 660 {
+		t.Errorf("Expected the add button to move with the search box, got %d", got)
+	}
+	if got := m.inputBox.Bounds().Dx(); got != 620 {
+		t.Errorf("Expected the input to take the extra width, got %d", got)
+	}
+	if got := m.filterButtons[models.FilterAll].Bounds().Min.Y; got != 700-FooterHeight+20 {
+		t.Errorf("Expected the filter buttons to move with the footer, got y=%d", got)
+	}
+	if item := g.todoItem(0); item.Width != 960 {
+		t.Errorf("Expected todo items to be resized, got width %d", item.Width)
+	}
+	if got := m.countRect; got.Min.X != m.statsButton.Bounds().Max.X+5 || got.Max.X != 980 {
+		t.Errorf("Expected the count to fill the footer after the buttons, got %v", got)
+	}
+	if got := m.palette.X; got != 250 {
+		t.Errorf("Expected the palette to stay centered, got x=%d", got)
+	}
+}
+
+func TestZoomAndDeviceScale(t *testing.T) {
+	t.Cleanup(func() { ui.SetScale(1) })
+	dir := t.TempDir()
+	g := newTestGameIn(t, dir)
+	g.deviceScale = func() float64 { return 2 }
+
+	// The screen uses device pixels while the UI keeps its size
+	if w, h := g.Layout(800, 600); w != 1600 || h != 1200 {
+		t.Errorf("Expected a 1600x1200 screen, got %dx%d", w, h)
+	}
+	if g.uiManager.windowWidth != 800 || ui.Scale() != 2 {
+		t.Errorf("Expected an 800px wide UI at scale 2, got %d at %v", g.uiManager.windowWidth, ui.Scale())
+	}
+
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.KeyEqual)
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.KeyEqual)
+	g.Layout(800, 600)
+	if g.zoom() != 1.25 || ui.Scale() != 2.5 {
+		t.Errorf("Expected zoom 1.25 at scale 2.5, got %v at %v", g.zoom(), ui.Scale())
+	}
+	if g.uiManager.windowWidth != 640 {
+		t.Errorf("Expected the UI to be laid out 640px wide, got %d", g.uiManager.windowWidth)
+	}
+
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.KeyMinus)
+	if g.zoom() != 1.1 {
+		t.Errorf("Expected Ctrl+- to zoom out to 1.1, got %v", g.zoom())
+	}
+
+	// The zoom level survives a restart
+	g = newTestGameIn(t, dir)
+	if g.zoom() != 1.1 {
+		t.Errorf("Expected the saved zoom 1.1, got %v", g.zoom())
+	}
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.Key0)
+	if g.zoom() != 1 {
+		t.Errorf("Expected Ctrl+0 to reset the zoom, got %v", g.zoom())
+	}
+}
+
+func TestSavedZoomIsClamped(t *testing.T) {
+	t.Cleanup(func() { ui.SetScale(1) })
+	for _, tt := range []struct{ saved, want float64 }{{40, 3}, {0.01, 0.5}, {1.5, 1.5}} {
+		dir := t.TempDir()
+		if err := storage.SaveSettings(filepath.Join(dir, "settings.json"), storage.Settings{Zoom: tt.saved}); err != nil {
+			t.Fatalf("Failed to save settings: %v", err)
+		}
+		g := newTestGameIn(t, dir)
+		g.Layout(800, 600)
+		if g.zoom() != tt.want || ui.Scale() != tt.want {
+			t.Errorf("Expected a saved zoom of %v to start at %v, got %v at scale %v", tt.saved, tt.want, g.zoom(), ui.Scale())
+		}
+	}
+}
+
+// addManyTodos fills the list with n todos without saving each one.
+func addManyTodos(g *Game, n int) {
+	for i := 0; i < n; i++ {
+		g.todos.Todos = append(g.todos.Todos, models.NewTodo(fmt.Sprintf("Todo %d", i), g.clock, g.ids))
+	}
+	g.updateTodoItems()
+}
+
+func TestListCreatesRowsOnlyInView(t *testing.T) {
+	g := newTestGame(t)
+	addManyTodos(g, 5000)
+	m := g.uiManager
+
+	rows := 0
+	m.list.ForEachRow(func(int, ui.ListRow) { rows++ })
+	if rows == 0 || rows > 20 {
+		t.Errorf("Expected only the rows in view to be created, got %d", rows)
+	}
+
+	// Selecting the last row scrolls it into view
+	g.updateFocusOrder()
+	m.focus.Focus(m.todoList)
+	pressKeys(g, ebiten.KeyEnd)
+	for i := 0; i < 200 && m.list.IsScrolling(); i++ {
+		m.list.Update(g.input)
+	}
+	if m.list.ScrollOffset() != m.list.MaxScroll() {
+		t.Errorf("Expected the list to scroll to the end, got %d of %d", m.list.ScrollOffset(), m.list.MaxScroll())
+	}
+	if !g.todoItem(4999).Selected || !m.list.Bounds().Overlaps(m.todoList.Bounds()) {
+		t.Error("Expected the last row to be selected and visible")
+	}
+}
+
+func BenchmarkUpdateTodoItems50k(b *testing.B) {
+	g, err := NewGame(filepath.Join(b.TempDir(), "todos.json"))
+	if err != nil {
+		b.Fatalf("Failed to create game: %v", err)
+	}
+	b.Cleanup(func() { g.Close() })
+	addManyTodos(g, 50000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.updateTodoItems()
+	}
+}
+
+// countingStorage counts the saves made through it.
+type countingStorage struct {
+	storage.Storage
+	saves int
+}
+
+func (s *countingStorage) SaveTodos(todos []models.Todo) error {
+	s.saves++
+	return s.Storage.SaveTodos(todos)
+}
+
+// clickRow clicks the middle of the i-th row while holding keys.
+func clickRow(g *Game, i int, keys ...ebiten.Key) {
+	center := g.uiManager.list.RowBounds(i)
+	keyFrame(g, keys, func() {
+		g.handleFocusClick((center.Min.X+center.Max.X)/2, (center.Min.Y+center.Max.Y)/2, ui.DefaultKeyboard)
+	})
+}
+
+func TestMultiSelect(t *testing.T) {
+	g := newTestGame(t)
+	addManyTodos(g, 6)
+	m := g.uiManager
+	g.updateFocusOrder()
+
+	clickRow(g, 1)
+	clickRow(g, 3, ebiten.KeyControlLeft)
+	if got := len(g.selectedTodoIDs()); got != 2 || !g.todoItem(1).Selected || !g.todoItem(3).Selected {
+		t.Fatalf("Expected Ctrl-click to add the row to the selection, got %d selected", got)
+	}
+	if !m.bulkBar.visible || m.list.Bounds().Max.Y >= m.contentRect.Max.Y {
+		t.Error("Expected the bulk toolbar to take room below the list")
+	}
+
+	// Shift-click selects from the last clicked row
+	clickRow(g, 5, ebiten.KeyShiftLeft)
+	if got := g.selectedTodoIDs(); len(got) != 3 || got[0] != m.todos[3].ID {
+		t.Errorf("Expected Shift-click to select rows 3 to 5, got %v", got)
+	}
+	pressKeys(g, ebiten.KeyShiftLeft, ebiten.KeyArrowUp)
+	if got := len(g.selectedTodoIDs()); got != 2 {
+		t.Errorf("Expected Shift+Up to shrink the range to 2 rows, got %d", got)
+	}
+
+	pressKeys(g, ebiten.KeyControlLeft, ebiten.KeyA)
+	if got := len(g.selectedTodoIDs()); got != 6 {
+		t.Errorf("Expected Ctrl+A to select all 6 rows, got %d", got)
+	}
+	pressKeys(g, ebiten.KeyEscape)
+	if got := len(g.selectedTodoIDs()); got != 1 || m.bulkBar.visible {
+		t.Errorf("Expected Escape to collapse the selection, got %d selected", got)
+	}
+
+	// Rows that leave the list leave the selection
+	g.selectAll()
+	g.setSearchQuery("Todo 2")
+	if got := g.selectedTodoIDs(); len(got) != 1 || got[0] != m.todos[0].ID {
+		t.Errorf("Expected only the matching todo to stay selected, got %v", got)
+	}
+}
+
+func TestBulkActionsSaveOnceAndUndo(t *testing.T) {
+	g := newTestGame(t)
+	addManyTodos(g, 4)
+	counter := &countingStorage{Storage: g.storage}
+	g.storage = counter
+	m := g.uiManager
+	g.updateFocusOrder()
+
+	clickRow(g, 0)
+	clickRow(g, 2, ebiten.KeyShiftLeft)
+	g.bulkSetCompleted(true)
+	g.bulkAddTag("#work")
+	g.bulkSetPriority(models.PriorityHigh)
+	if counter.saves != 3 {
+		t.Errorf("Expected one save per bulk action, got %d", counter.saves)
+	}
+	for i, todo := range g.todos.Todos {
+		selected := i < 3
+		if todo.Completed != selected || todo.HasTag("work") != selected || (todo.Priority == models.PriorityHigh) != selected {
+			t.Errorf("Expected todo %d changed = %v, got %+v", i, selected, todo)
+		}
+	}
+
+	m.focus.Focus(m.todoList)
+	pressKeys(g, ebiten.KeyDelete)
+	if len(g.todos.Todos) != 1 || counter.saves != 4 {
+		t.Fatalf("Expected Delete to remove the 3 selected todos in one save, got %d todos and %d saves", len(g.todos.Todos), counter.saves)
+	}
+
+	// Each bulk action undoes as a single step
+	g.undo()
+	if len(g.todos.Todos) != 4 || g.todos.Todos[1].Text != "Todo 1" || g.todos.Todos[3].Text != "Todo 3" {
+		t.Fatalf("Expected undo to restore the deleted todos in place, got %+v", g.todos.Todos)
+	}
+	g.undo()
+	if g.todos.Todos[0].Priority != models.PriorityNone || !g.todos.Todos[0].HasTag("work") {
+		t.Errorf("Expected undo to revert only the priority, got %+v", g.todos.Todos[0])
+	}
+	g.undo()
+	g.undo()
+	for _, todo := range g.todos.Todos {
+		if todo.Completed || len(todo.Tags) > 0 {
+			t.Errorf("Expected undo to revert every bulk action, got %+v", todo)
+		}
+	}
+
+	saved, err := g.storage.LoadTodos()
+	if err != nil {
+		t.Fatalf("Failed to load todos: %v", err)
+	}
+	if len(saved) != 4 || saved[0].Completed {
+		t.Errorf("Expected the undone state to be saved, got %+v", saved)
+	}
+}
+
+func TestUndoShortcut(t *testing.T) {
+	g := newTestGame(t)
+	g.uiManager.inputBox.SetText("first")
+	g.addTodo()
+
+	// Typing in a text field never undoes todos
+	g.uiManager.focus.Focus(g.uiManager.inputBox)
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.KeyZ)
+	if len(g.todos.Todos) != 1 {
+		t.Fatal("Expected Ctrl+Z in a text field to leave the todos alone")
+	}
+
+	g.uiManager.focus.Focus(g.uiManager.todoList)
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.KeyZ)
+	if len(g.todos.Todos) != 0 {
+		t.Errorf("Expected Ctrl+Z to undo adding the todo, got %d todos", len(g.todos.Todos))
+	}
+}
+
+func TestClearCompletedArchivesAndRestores(t *testing.T) {
+	dir := t.TempDir()
+	g := newTestGameIn(t, dir)
+	addManyTodos(g, 3)
+	g.toggleTodo(g.todos.Todos[0].ID)
+	g.toggleTodo(g.todos.Todos[2].ID)
+
+	g.clearCompleted()
+	if len(g.todos.Todos) != 1 || g.todos.Todos[0].Text != "Todo 1" {
+		t.Fatalf("Expected only the active todo to stay, got %+v", g.todos.Todos)
+	}
+
+	// The archive is kept in its own file
+	archived, err := storage.NewFileStorage(filepath.Join(dir, "archive.json")).LoadTodos()
+	if err != nil {
+		t.Fatalf("Failed to load archive: %v", err)
+	}
+	if len(archived) != 2 || archived[0].ArchivedAt == nil {
+		t.Fatalf("Expected the completed todos in the archive, got %+v", archived)
+	}
+	saved, err := g.storage.LoadTodos()
+	if err != nil {
+		t.Fatalf("Failed to load todos: %v", err)
+	}
+	if len(saved) != 1 {
+		t.Errorf("Expected the archived todos to leave the todo file, got %d todos", len(saved))
+	}
+
+	// The panel lists the newest first and restores with Enter
+	g.openArchive()
+	v := g.uiManager.archive
+	if !v.visible || len(v.todos) != 2 || v.todos[0].Text != "Todo 2" {
+		t.Fatalf("Expected the archive panel to list the archived todos, got %+v", v.todos)
+	}
+	keyFrame(g, []ebiten.Key{ebiten.KeyEnter}, func() { v.update(g.input, ui.DefaultKeyboard) })
+	if len(g.archive.todos) != 1 || g.todos.FindTodo(archived[1].ID) == nil {
+		t.Fatalf("Expected Enter to restore the selected todo, got %d archived", len(g.archive.todos))
+	}
+	if todo := g.todos.FindTodo(archived[1].ID); todo.ArchivedAt != nil {
+		t.Error("Expected a restored todo to no longer be marked archived")
+	}
+	g.trashArchived(g.archive.todos[0].ID)
+	if len(g.archive.todos) != 0 || len(v.todos) != 0 {
+		t.Errorf("Expected the todo to leave the archive, got %d", len(g.archive.todos))
+	}
+	if len(g.trash.todos) != 1 || g.trash.todos[0].DeletedAt == nil {
+		t.Errorf("Expected the todo deleted from the archive to go to the trash, got %+v", g.trash.todos)
+	}
+}
+
+func TestRestoreKeepsTodoInOneFileWhenCollectionFails(t *testing.T) {
+	dir := t.TempDir()
+	g := newTestGameIn(t, dir)
+	addManyTodos(g, 1)
+	archived := models.Todo{ID: "archived", Text: "Archived", Completed: true, ArchivedAt: &testNow}
+	g.archive = &collection{storage: failingStorage{}, todos: []models.Todo{archived}, loaded: true}
+
+	g.restoreArchived("archived")
+	if len(g.todos.Todos) != 1 || g.todos.FindTodo("archived") != nil {
+		t.Errorf("Expected the todo to be taken back out of the list, got %+v", g.todos.Todos)
+	}
+	if len(g.archive.todos) != 1 {
+		t.Errorf("Expected the todo to stay in the archive, got %+v", g.archive.todos)
+	}
+	saved, err := storage.NewFileStorage(filepath.Join(dir, "todos.json")).LoadTodos()
+	if err != nil {
+		t.Fatalf("Failed to load todos: %v", err)
+	}
+	if len(saved) != 1 {
+		t.Errorf("Expected the todo file without the restored todo, got %+v", saved)
+	}
+	if !strings.Contains(g.error, "disk full") {
+		t.Errorf("Expected the save error to be shown, got %q", g.error)
+	}
+}
+
+func TestUndoClearCompleted(t *testing.T) {
+	g := newTestGame(t)
+	addManyTodos(g, 2)
+	g.toggleTodo(g.todos.Todos[0].ID)
+
+	g.clearCompleted()
+	g.undo()
+	if len(g.todos.Todos) != 2 || !g.todos.Todos[0].Completed || g.todos.Todos[0].ArchivedAt != nil {
+		t.Errorf("Expected undo to bring the archived todo back in place, got %+v", g.todos.Todos)
+	}
+	if len(g.archive.todos) != 0 {
+		t.Errorf("Expected undo to take the todo out of the archive, got %d archived", len(g.archive.todos))
+	}
+}
+
+func TestUndoClearCompletedSavesTodosFirst(t *testing.T) {
+	dir := t.TempDir()
+	g := newTestGameIn(t, dir)
+	addManyTodos(g, 2)
+	g.toggleTodo(g.todos.Todos[0].ID)
+	g.clearCompleted()
+
+	// The restored todo is on disk by the time it leaves the archive
+	g.undo()
+	saved, err := storage.NewFileStorage(filepath.Join(dir, "todos.json")).LoadTodos()
+	if err != nil {
+		t.Fatalf("Failed to load todos: %v", err)
+	}
+	if len(saved) != 2 {
+		t.Errorf("Expected both todos saved by undo, got %+v", saved)
+	}
+
+	// A failed write of the todo file leaves the todo in the archive
+	g.clearCompleted()
+	g.saver = storage.NewAsyncStorage(failingStorage{}, g.clock.(*models.FakeClock), time.Hour, time.Hour)
+	g.storage = g.saver
+	g.undo()
+	if len(g.archive.todos) != 1 {
+		t.Errorf("Expected the todo to stay in the archive, got %+v", g.archive.todos)
+	}
+	if !strings.Contains(g.error, "disk full") {
+		t.Errorf("Expected the save error to be shown, got %q", g.error)
+	}
+}
+
+func TestClearCompletedKeepsTodosWhenArchiveFails(t *testing.T) {
+	g := newTestGame(t)
+	addManyTodos(g, 2)
+	g.toggleTodo(g.todos.Todos[0].ID)
+	g.archive = newCollection(failingStorage{})
+	steps := len(g.undoStack)
+
+	g.clearCompleted()
+	if len(g.todos.Todos) != 2 || g.todos.Todos[0].ArchivedAt != nil {
+		t.Errorf("Expected the todos to stay in the list, got %+v", g.todos.Todos)
+	}
+	if len(g.archive.todos) != 0 {
+		t.Errorf("Expected nothing in the archive, got %+v", g.archive.todos)
+	}
+	if !strings.Contains(g.error, "disk full") {
+		t.Errorf("Expected the save error to be shown, got %q", g.error)
+	}
+	if len(g.undoStack) != steps {
+		t.Errorf("Expected no undo step for a failed archive, got %d", len(g.undoStack)-steps)
+	}
+}
+
+func TestAutoArchive(t *testing.T) {
+	dir := t.TempDir()
+	old := testNow.AddDate(0, 0, -10)
+	recent := testNow.AddDate(0, 0, -1)
+	todos := []models.Todo{
+		{ID: "old", Text: "Old", Completed: true, CompletedAt: &old},
+		{ID: "recent", Text: "Recent", Completed: true, CompletedAt: &recent},
+	}
+	if err := storage.NewFileStorage(filepath.Join(dir, "todos.json")).SaveTodos(todos); err != nil {
+		t.Fatalf("Failed to save todos: %v", err)
+	}
+	if err := storage.SaveSettings(filepath.Join(dir, "settings.json"), storage.Settings{AutoArchiveDays: 7}); err != nil {
+		t.Fatalf("Failed to save settings: %v", err)
+	}
+
+	g := newTestGameIn(t, dir)
+	if len(g.todos.Todos) != 1 || g.todos.Todos[0].ID != "recent" {
+		t.Errorf("Expected todos completed over a week ago to be archived at start, got %+v", g.todos.Todos)
+	}
+	if len(g.archive.todos) != 1 || g.archive.todos[0].ID != "old" {
+		t.Errorf("Expected the old todo in the archive, got %+v", g.archive.todos)
+	}
+}
+
+func TestDeleteMovesToTrash(t *testing.T) {
+	dir := t.TempDir()
+	g, err := NewGame(filepath.Join(dir, "todos.json"))
+	if err != nil {
+		t.Fatalf("Failed to create game: %v", err)
+	}
+	t.Cleanup(func() { g.Close() })
+	addManyTodos(g, 3)
+	deleted := g.todos.Todos[1]
+
+	g.deleteTodo(deleted.ID)
+	if len(g.todos.Todos) != 2 {
+		t.Fatalf("Expected the todo to leave the list, got %d todos", len(g.todos.Todos))
+	}
+	trashed, err := storage.NewFileStorage(filepath.Join(dir, "trash.json")).LoadTodos()
+	if err != nil {
+		t.Fatalf("Failed to load trash: %v", err)
+	}
+	if len(trashed) != 1 || trashed[0].ID != deleted.ID || trashed[0].DeletedAt == nil {
+		t.Fatalf("Expected the deleted todo in the trash file, got %+v", trashed)
+	}
+
+	// Undo takes it back out of the trash
+	g.undo()
+	if len(g.todos.Todos) != 3 || g.todos.Todos[1].ID != deleted.ID || len(g.trash.todos) != 0 {
+		t.Fatalf("Expected undo to restore the todo in place, got %d todos and %d trashed", len(g.todos.Todos), len(g.trash.todos))
+	}
+
+	g.deleteTodo(deleted.ID)
+	g.openTrash()
+	v := g.uiManager.trash
+	if !v.visible || len(v.todos) != 1 {
+		t.Fatalf("Expected the trash panel to list the deleted todo, got %+v", v.todos)
+	}
+	g.restoreTrashed(deleted.ID)
+	if todo := g.todos.FindTodo(deleted.ID); todo == nil || todo.DeletedAt != nil {
+		t.Errorf("Expected the todo to be restored without a deleted time, got %+v", todo)
+	}
+
+	g.deleteTodo(deleted.ID)
+	g.deleteForever(deleted.ID)
+	if len(g.trash.todos) != 0 || g.todos.FindTodo(deleted.ID) != nil {
+		t.Error("Expected the todo to be gone for good")
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	dir := t.TempDir()
+	old := testNow.AddDate(0, 0, -40)
+	recent := testNow.AddDate(0, 0, -3)
+	trashed := []models.Todo{
+		{ID: "old", Text: "Old", DeletedAt: &old},
+		{ID: "recent", Text: "Recent", DeletedAt: &recent},
+	}
+	if err := storage.NewFileStorage(filepath.Join(dir, "trash.json")).SaveTodos(trashed); err != nil {
+		t.Fatalf("Failed to save trash: %v", err)
+	}
+
+	// The default retention keeps a month
+	g := newTestGameIn(t, dir)
+	if len(g.trash.todos) != 1 || g.trash.todos[0].ID != "recent" {
+		t.Errorf("Expected todos deleted over 30 days ago to be purged, got %+v", g.trash.todos)
+	}
+
+	g.settings.TrashRetentionDays = 1
+	g.purgeTrash()
+	if len(g.trash.todos) != 0 {
+		t.Errorf("Expected a shorter retention to purge more, got %+v", g.trash.todos)
+	}
+
+	// A negative retention keeps everything
+	g.trash.todos = trashed
+	g.settings.TrashRetentionDays = -1
+	g.purgeTrash()
+	if len(g.trash.todos) != 2 {
+		t.Errorf("Expected nothing to be purged, got %+v", g.trash.todos)
+	}
+}
+
+func TestDetailsShowHistory(t *testing.T) {
+	g := newTestGame(t)
+	addManyTodos(g, 1)
+	id := g.todos.Todos[0].ID
+
+	// Without a selection there is nothing to show
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.KeyI)
+	if g.uiManager.details.visible {
+		t.Fatal("Expected no details without a selected todo")
+	}
+
+	g.toggleTodo(id)
+	g.editTodo(id, "Renamed")
+	g.selectTodo(0)
+	g.leaveTextField()
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.KeyI)
+	v := g.uiManager.details
+	if !v.visible || v.todoID != id {
+		t.Fatalf("Expected Ctrl+I to show the selected todo, got visible=%v id=%q", v.visible, v.todoID)
+	}
+	if v.lines[0].text != "Text: Renamed" {
+		t.Errorf("Expected the current text first, got %q", v.lines[0].text)
+	}
+
+	// The history lists the newest change first
+	var history []string
+	for i, line := range v.lines {
+		if line.text == "History" {
+			for _, line := range v.lines[i+1:] {
+				history = append(history, line.text[len(detailsTimeFormat)+2:])
+			}
+		}
+	}
+	want := []string{`Text: "Todo 0" -> "Renamed"`, "Completed"}
+	if fmt.Sprint(history) != fmt.Sprint(want) {
+		t.Errorf("Expected history %q, got %q", want, history)
+	}
+}
+
+func TestDashboardCountsArchivedTodos(t *testing.T) {
+	g := newTestGame(t)
+	addManyTodos(g, 3)
+	g.toggleTodo(g.todos.Todos[0].ID)
+	g.toggleTodo(g.todos.Todos[1].ID)
+	g.clearCompleted()
+	g.toggleTodo(g.todos.Todos[0].ID)
+
+	g.leaveTextField()
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.KeyD)
+	v := g.uiManager.dashboard
+	if !v.visible {
+		t.Fatal("Expected Ctrl+D to open the dashboard")
+	}
+	if v.summary.Open != 0 || v.summary.Done != 3 {
+		t.Errorf("Expected 0 open and 3 done including the archive, got %d and %d", v.summary.Open, v.summary.Done)
+	}
+	if v.summary.CurrentStreak != 1 {
+		t.Errorf("Expected a streak of 1 day, got %d", v.summary.CurrentStreak)
+	}
+
+	for d, want := range map[time.Duration]string{
+		0:                          "-",
+		90 * time.Second:           "1m",
+		3*time.Hour + time.Minute:  "3h 1m",
+		50*time.Hour + time.Minute: "2d 2h",
+	} {
+		if got := formatDuration(d); got != want {
+			t.Errorf("Expected %v to format as %q, got %q", d, want, got)
+		}
+	}
+}
+
+func TestBoardMovesCards(t *testing.T) {
+	dir := t.TempDir()
+	g := newTestGameIn(t, dir)
+	addManyTodos(g, 3)
+	g.setBoardVisible(true)
+	b := g.uiManager.board
+
+	// Dragging the first card onto the Done column completes it
+	card := b.cardRect(0, 0)
+	done := b.columnRect(2)
+	b.press(card.Min.X+5, card.Min.Y+5)
+	b.dragTo(done.Min.X+20, done.Min.Y+60)
+	b.release(done.Min.X+20, done.Min.Y+60)
+	todo := g.todos.Todos[0]
+	if !todo.Completed || todo.Status != models.StatusDone {
+		t.Fatalf("Expected the card to be completed in the Done column, got completed=%v status=%q", todo.Completed, todo.Status)
+	}
+	g.setFilter(models.FilterActive)
+	if len(b.cards()[2]) != 0 || len(b.cards()[0]) != 2 {
+		t.Errorf("Expected the Active filter to hide the Done column's cards, got %v", b.cards())
+	}
+	g.setFilter(models.FilterAll)
+
+	// A click without moving only selects
+	card = b.cardRect(0, 0)
+	b.press(card.Min.X+5, card.Min.Y+5)
+	b.release(card.Min.X+5, card.Min.Y+5)
+	if g.uiManager.selectedID != g.todos.Todos[1].ID || g.todos.Todos[1].Status != "" {
+		t.Errorf("Expected a click to select the card, got selection %q status %q", g.uiManager.selectedID, g.todos.Todos[1].Status)
+	}
+
+	// Shift+Right moves the selected card to In Progress
+	keyFrame(g, []ebiten.Key{ebiten.KeyShiftLeft, ebiten.KeyArrowRight}, func() { b.handleKeys(ui.DefaultKeyboard) })
+	if todo := g.todos.Todos[1]; todo.Status != models.StatusInProgress || todo.Completed {
+		t.Errorf("Expected the card in progress, got status %q completed=%v", todo.Status, todo.Completed)
+	}
+
+	// Undo takes it back, and the statuses and the view survive a restart
+	g.undo()
+	if g.todos.Todos[1].Status != "" {
+		t.Errorf("Expected undo to restore the status, got %q", g.todos.Todos[1].Status)
+	}
+	if err := g.Close(); err != nil {
+		t.Fatalf("Failed to close game: %v", err)
+	}
+	g, err := NewGame(filepath.Join(dir, "todos.json"))
+	if err != nil {
+		t.Fatalf("Failed to reload game: %v", err)
+	}
+	if !g.uiManager.board.visible || g.todos.Todos[0].Status != models.StatusDone {
+		t.Errorf("Expected the board and the statuses to persist, got visible=%v status=%q", g.uiManager.board.visible, g.todos.Todos[0].Status)
+	}
+}
+
+// calendarCell returns the cell of a day shown in the calendar.
+func calendarCell(t *testing.T, v *calendarView, day calendar.Date) image.Rectangle {
+	t.Helper()
+	weeks := v.weeks()
+	for row, week := range weeks {
+		for col, d := range week {
+			if d == day {
+				return v.dayRect(row, col, len(weeks))
+			}
+		}
+	}
+	t.Fatalf("Expected %v to be shown", day)
+	return image.Rectangle{}
+}
+
+func TestCalendarReschedulesAndFilters(t *testing.T) {
+	g := newTestGame(t)
+	addManyTodos(g, 3)
+	// Use days in the middle of this month, which the month view shows
+	now := calendar.DateOf(g.clock.Now())
+	day := calendar.Date{Year: now.Year, Month: now.Month, Day: 14}
+	next := day.AddDays(1)
+	due := day.At(9, 30, time.Local)
+	g.todos.Todos[0].Due = &due
+	later := next.At(18, 0, time.Local)
+	g.todos.Todos[1].Due = &later
+
+	g.leaveTextField()
+	pressShortcut(g, ebiten.KeyControlLeft, ebiten.KeyL)
+	v := g.uiManager.calendar
+	if !v.visible || v.cursor != now {
+		t.Fatalf("Expected Ctrl+L to open the calendar at today, got visible=%v cursor=%v", v.visible, v.cursor)
+	}
+	if todos := v.todosOn(day); len(todos) != 1 || todos[0].ID != g.todos.Todos[0].ID {
+		t.Fatalf("Expected the first todo on the 14th, got %v", todos)
+	}
+
+	// Dragging the todo to the next day keeps its time
+	cell := calendarCell(t, v, day)
+	chips, _ := v.chips(day, cell)
+	target := calendarCell(t, v, next)
+	v.press(chips[0].X+5, chips[0].Y+5)
+	v.dragTo(target.Min.X+10, target.Max.Y-5)
+	v.release(target.Min.X+10, target.Max.Y-5)
+	moved := g.todos.Todos[0].Due.In(time.Local)
+	if calendar.DateOf(moved) != next || moved.Hour() != 9 || moved.Minute() != 30 {
+		t.Errorf("Expected the todo due the next day at 9:30, got %v", moved)
+	}
+
+	// Clicking a day filters the list to it and closes the calendar
+	v.press(target.Min.X+10, target.Max.Y-5)
+	v.release(target.Min.X+10, target.Max.Y-5)
+	if v.visible || g.dueFilter == nil || *g.dueFilter != next {
+		t.Fatalf("Expected the click to filter to the day, got visible=%v filter=%v", v.visible, g.dueFilter)
+	}
+	if g.uiManager.list.Count() != 2 {
+		t.Errorf("Expected the two todos due that day, got %d", g.uiManager.list.Count())
+	}
+
+	// Todos added meanwhile are due that day, and All shows every todo
+	g.uiManager.inputBox.SetText("Added")
+	g.addTodo()
+	if g.uiManager.list.Count() != 3 {
+		t.Errorf("Expected the new todo to stay in the filtered list, got %d", g.uiManager.list.Count())
+	}
+	g.setFilter(models.FilterAll)
+	if g.dueFilter != nil || g.uiManager.list.Count() != 4 {
+		t.Errorf("Expected All to drop the day, got filter %v and %d todos", g.dueFilter, g.uiManager.list.Count())
+	}
+
+	// The calendar reopens at the filtered day, and the week view pages
+	// by weeks
+	g.setDueFilter(&next)
+	g.openCalendar()
+	v.setMode(calendarWeek)
+	v.step(1)
+	if v.cursor != next.AddDays(7) || len(v.weeks()) != 1 {
+		t.Errorf("Expected the next week, got %v and %d weeks", v.cursor, len(v.weeks()))
+	}
+}
+
+func TestQuickAddParsesFieldsAndRepeats(t *testing.T) {
+	g := newTestGame(t)
+	g.uiManager.inputBox.SetText("Pay rent tomorrow 9am !high #home every month")
+	g.addTodo()
+	todo := g.todos.Todos[0]
+	if todo.ID != "todo-1" || todo.Text != "Pay rent" || todo.Priority != models.PriorityHigh || len(todo.Tags) != 1 || todo.Tags[0] != "home" {
+		t.Fatalf("Expected the fields parsed out of the text, got %+v", todo)
+	}
+	if want := time.Date(2024, time.March, 14, 9, 0, 0, 0, time.Local); todo.Due == nil || !todo.Due.Equal(want) {
+		t.Errorf("Expected the todo due tomorrow at 9:00, got %v", todo.Due)
+	}
+	if todo.Recurrence == nil || todo.Recurrence.String() != "every month" {
+		t.Errorf("Expected a monthly recurrence, got %v", todo.Recurrence)
+	}
+
+	// Input made only of fields has no text to add
+	g.uiManager.inputBox.SetText("tomorrow #home")
+	g.addTodo()
+	if len(g.todos.Todos) != 1 || g.error == "" {
+		t.Errorf("Expected an error for a todo without text, got %d todos and %q", len(g.todos.Todos), g.error)
+	}
+
+	// Completing a recurring todo adds the next one, which undo takes back
+	g.toggleTodo(todo.ID)
+	if len(g.todos.Todos) != 2 {
+		t.Fatalf("Expected the next occurrence to be added, got %d todos", len(g.todos.Todos))
+	}
+	if done := g.todos.Todos[0].CompletedAt; done == nil || !done.Equal(testNow) {
+		t.Errorf("Expected the todo completed at %v, got %v", testNow, done)
+	}
+	next := g.todos.Todos[1]
+	want := time.Date(2024, time.April, 14, 9, 0, 0, 0, time.Local)
+	if next.ID != "todo-2" || next.Completed || next.Due == nil || !next.Due.Equal(want) || next.Recurrence == nil {
+		t.Errorf("Expected an open todo due a month later, got %+v", next)
+	}
+	g.undo()
+	if len(g.todos.Todos) != 1 || g.todos.Todos[0].Completed || g.todos.Todos[0].Recurrence == nil {
+		t.Errorf("Expected undo to restore the recurring todo, got %+v", g.todos.Todos)
+	}
+}
+
+// play runs the game loop over frames of scripted input, a 60th of a
+// second apart.
+func play(g *Game, frames ...ui.InputFrame) {
+	in := ui.NewScriptedInput(frames...)
+	g.input = in
+	in.Run(func() {
+		g.Update()
+		g.clock.(*models.FakeClock).Advance(time.Second / 60)
+	})
+}
+
+func TestScriptedTypingAddsTodo(t *testing.T) {
+	g := newTestGame(t)
+	box := g.uiManager.inputBox
+	at := image.Pt(box.X+box.Width/2, box.Y+box.Height/2)
+
+	frames := ui.Click(at.X, at.Y)
+	frames = append(frames, ui.Type(at, "Buy milk !high")...)
+	frames = append(frames, ui.KeyPress(at, ebiten.KeyEnter)...)
+	play(g, frames...)
+
+	if len(g.todos.Todos) != 1 || g.todos.Todos[0].Text != "Buy milk" || g.todos.Todos[0].Priority != models.PriorityHigh {
+		t.Fatalf("Expected a high priority 'Buy milk' todo, got %+v", g.todos.Todos)
+	}
+	if box.GetText() != "" {
+		t.Errorf("Expected the input to be cleared, got %q", box.GetText())
+	}
+}
+
+func TestScriptedDoubleClickEditsTodo(t *testing.T) {
+	g := newTestGame(t)
+	g.uiManager.inputBox.SetText("Buy milk")
+	g.addTodo()
+	item := g.todoItem(0)
+	at := image.Pt(item.X+80, item.Y+item.Height/2)
+
+	frames := ui.DoubleClick(at.X, at.Y)
+	frames = append(frames, ui.Type(at, " and eggs")...)
+	frames = append(frames, ui.KeyPress(at, ebiten.KeyEnter)...)
+	play(g, frames...)
+
+	if got := g.todos.Todos[0].Text; got != "Buy milk and eggs" {
+		t.Errorf("Expected 'Buy milk and eggs', got %q", got)
+	}
+	if g.todos.Todos[0].Completed {
+		t.Error("Expected the double-click not to complete the todo")
+	}
+
+	// Escape leaves the text as it was
+	frames = ui.DoubleClick(at.X, at.Y)
+	frames = append(frames, ui.Type(at, "!!!")...)
+	frames = append(frames, ui.KeyPress(at, ebiten.KeyEscape)...)
+	play(g, frames...)
+
+	if got := g.todos.Todos[0].Text; got != "Buy milk and eggs" {
+		t.Errorf("Expected Escape to keep 'Buy milk and eggs', got %q", got)
+	}
+}
+
+func TestScriptedEscapeClosesPanel(t *testing.T) {
+	g := newTestGame(t)
+	g.openDashboard()
+
+	play(g, ui.KeyPress(image.Pt(0, 0), ebiten.KeyEscape)...)
+
+	if g.uiManager.dashboard.visible {
+		t.Error("Expected Escape to close the dashboard")
+	}
+}
+
+func TestScriptedWheelScrollsList(t *testing.T) {
+	g := newTestGame(t)
+	addManyTodos(g, 100)
+	list := g.uiManager.list
+	bounds := list.Bounds()
+	at := image.Pt(bounds.Min.X+bounds.Dx()/2, bounds.Min.Y+bounds.Dy()/2)
+
+	frames := ui.Scroll(at.X, at.Y, -3)
+	for i := 0; i < 120; i++ {
+		frames = append(frames, ui.InputFrame{Cursor: at})
+	}
+	play(g, frames...)
+
+	if list.IsScrolling() || list.ScrollOffset() <= 0 {
+		t.Errorf("Expected the wheel to scroll the list and settle, got offset %d", list.ScrollOffset())
+	}
+}
+
+// recordSession records a scripted session on a game with one todo, adding
+// "Buy milk !high" and completing the first todo, and returns the saved
+// recording.
+func recordSession(t *testing.T) *Recording {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "todos.json")
+	clock := models.NewFakeClock(testNow)
+	existing := models.NewTodo("Call mom", clock, &models.SequentialIDs{Prefix: "old-"})
+	if err := storage.NewFileStorage(path).SaveTodos([]models.Todo{existing}); err != nil {
+		t.Fatalf("Failed to save todos: %v", err)
+	}
+
+	source := ui.NewScriptedInput()
+	recordingPath := filepath.Join(dir, "session.json")
+	g, err := newRecordingGame(path, recordingPath, source, clock, &models.SequentialIDs{Prefix: "todo-"})
+	if err != nil {
+		t.Fatalf("Failed to create game: %v", err)
+	}
+	t.Cleanup(func() { g.Close() })
+	g.deviceScale = func() float64 { return 1 }
+	run := func(frames ...ui.InputFrame) {
+		source.Add(frames...)
+		for source.Next() {
+			g.Layout(WindowWidth, WindowHeight)
+			g.Update()
+			clock.Advance(time.Second / 60)
+		}
+	}
+
+	box := g.uiManager.inputBox
+	at := image.Pt(box.X+box.Width/2, box.Y+box.Height/2)
+	run(ui.Click(at.X, at.Y)...)
+	run(ui.Type(at, "Buy milk !high")...)
+	run(ui.KeyPress(at, ebiten.KeyEnter)...)
+	checkbox := g.todoItem(0).Checkbox
+	run(ui.Click(checkbox.X+checkbox.Width/2, checkbox.Y+checkbox.Height/2)...)
+
+	if len(g.todos.Todos) != 2 || !g.todos.Todos[0].Completed {
+		t.Fatalf("Expected the session to add a todo and complete the first, got %+v", g.todos.Todos)
+	}
+	if err := g.SaveRecording(); err != nil {
+		t.Fatalf("Failed to save recording: %v", err)
+	}
+	rec, err := LoadRecording(recordingPath)
+	if err != nil {
+		t.Fatalf("Failed to load recording: %v", err)
+	}
+	return rec
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	rec := recordSession(t)
+
+	if len(rec.IDs) != 1 || rec.IDs[0] != "todo-1" {
+		t.Errorf("Expected the new todo's ID to be recorded, got %v", rec.IDs)
+	}
+	if !strings.Contains(rec.Files["todos.json"], "Call mom") {
+		t.Errorf("Expected the starting todos to be recorded, got %q", rec.Files["todos.json"])
+	}
+	if len(rec.Frames) != 7 || rec.Frames[0].Size == nil || rec.Frames[1].Size != nil {
+		t.Errorf("Expected 7 frames with the window size on the first, got %d", len(rec.Frames))
+	}
+	if rec.Frames[6].At != 6*(time.Second/60) {
+		t.Errorf("Expected the last frame 6 frames in, got %v", rec.Frames[6].At)
+	}
+
+	if err := Replay(rec, t.TempDir()); err != nil {
+		t.Errorf("Expected the replay to end with the recorded todos: %v", err)
+	}
+
+	// A replay that ends differently fails
+	rec.Todos[1].Text = "Buy bread"
+	if err := Replay(rec, t.TempDir()); err == nil || !strings.Contains(err.Error(), "Buy bread") {
+		t.Errorf("Expected the replay to report the changed todo, got %v", err)
+	}
+}
+
+// TestRecordings replays the recordings in testdata/recordings, which
+// reproduce fixed bugs.
+func TestRecordings(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "recordings", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			rec, err := LoadRecording(path)
+			if err != nil {
+				t.Fatalf("Failed to load recording: %v", err)
+			}
+			if err := Replay(rec, t.TempDir()); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// failingStorage fails every write.
+type failingStorage struct{}
+
+func (failingStorage) SaveTodos([]models.Todo) error     { return errors.New("disk full") }
+func (failingStorage) LoadTodos() ([]models.Todo, error) { return nil, nil }
+func (failingStorage) ClearTodos() error                 { return errors.New("disk full") }
+
+func TestBackgroundSaveErrorIsShown(t *testing.T) {
+	g := newTestGame(t)
+	clock := g.clock.(*models.FakeClock)
+	g.saver = storage.NewAsyncStorage(failingStorage{}, clock, time.Millisecond, time.Millisecond)
+	g.storage = g.saver
+
+	// The todo is added at once and the write fails later
+	g.uiManager.inputBox.SetText("Buy milk")
+	g.addTodo()
+	if len(g.todos.Todos) != 1 || g.error != "" {
+		t.Fatalf("Expected the todo added without an error, got %d todos and %q", len(g.todos.Todos), g.error)
+	}
+	clock.Advance(time.Millisecond)
+	g.Update()
+	if !strings.Contains(g.error, "disk full") {
+		t.Errorf("Expected the failed save to be shown, got %q", g.error)
+	}
+}
+
+func TestQuitSavesTodosAndDrafts(t *testing.T) {
+	dir := t.TempDir()
+	g := newTestGameIn(t, dir)
+	for _, text := range []string{"Buy milk", "Call mom", "Pay rent"} {
+		g.uiManager.inputBox.SetText(text)
+		g.addTodo()
+	}
+
+	// Leave notes, a todo text and a new todo half written
+	g.toggleNotes(g.todos.Todos[1].ID)
+	g.todoItem(g.indexOfTodo(g.todos.Todos[1].ID)).NotesArea.SetText("Ask about")
+	item := g.todoItem(g.indexOfTodo(g.todos.Todos[0].ID))
+	item.StartEditing()
+	item.EditTextBox.SetText("Buy oat milk")
+	g.uiManager.inputBox.SetText("Water the")
+
+	g.RequestQuit()
+	if err := g.Update(); !errors.Is(err, ebiten.Termination) {
+		t.Fatalf("Expected the game to terminate, got %v", err)
+	}
+	todos, err := storage.NewFileStorage(filepath.Join(dir, "todos.json")).LoadTodos()
+	if err != nil || len(todos) != 3 {
+		t.Fatalf("Expected the pending todos to be written on quit, got %d, %v", len(todos), err)
+	}
+
+	// The next launch reopens the edits
+	g = newTestGameIn(t, dir)
+	if got := g.uiManager.inputBox.GetText(); got != "Water the" {
+		t.Errorf("Expected the new todo draft restored, got %q", got)
+	}
+	item = g.todoItem(g.indexOfTodo(g.todos.Todos[0].ID))
+	if !item.Editing || item.EditTextBox.GetText() != "Buy oat milk" || g.todos.Todos[0].Text != "Buy milk" {
+		t.Errorf("Expected the text draft reopened unsaved, got editing=%v %q", item.Editing, item.EditTextBox.GetText())
+	}
+	item = g.todoItem(g.indexOfTodo(g.todos.Todos[1].ID))
+	if !item.EditingNotes || item.NotesArea.GetText() != "Ask about" || g.todos.Todos[1].Notes != "" {
+		t.Errorf("Expected the notes draft reopened unsaved, got editing=%v %q", item.EditingNotes, item.NotesArea.GetText())
+	}
+
+	// Finishing the edits leaves no drafts behind
+	g.editTodo(g.todos.Todos[0].ID, "Buy oat milk")
+	g.saveNotes(g.todos.Todos[1].ID, "Ask about the lease")
+	g.uiManager.inputBox.SetText("")
+	if err := g.Close(); err != nil {
+		t.Fatalf("Failed to close game: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "drafts.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected the drafts file to be removed, got %v", err)
+	}
+}
+
+func TestEditsOutliveTheirRows(t *testing.T) {
+	dir := t.TempDir()
+	g := newTestGameIn(t, dir)
+	addManyTodos(g, 30)
+	first := g.todos.Todos[0].ID
+
+	item := g.todoItem(0)
+	item.StartEditing()
+	item.EditTextBox.SetText("Todo 1, edited")
+	g.toggleNotes(g.todos.Todos[1].ID)
+	g.todoItem(1).NotesArea.SetText("Half written")
+
+	// The edits survive scrolling out of view, a refresh and the board
+	g.uiManager.list.ScrollToRow(29)
+	settleList(g)
+	if first, _ := g.uiManager.list.VisibleRange(); first < 2 {
+		t.Fatalf("Expected the edited rows to be out of view, got row %d first", first)
+	}
+	g.toggleTodo(g.todos.Todos[20].ID)
+	g.setBoardVisible(true)
+	g.setBoardVisible(false)
+	g.uiManager.list.ScrollToRow(0)
+	settleList(g)
+	item = g.todoItem(0)
+	if !item.Editing || item.EditTextBox.GetText() != "Todo 1, edited" {
+		t.Errorf("Expected the text edit back in its row, got editing=%v %q", item.Editing, item.EditTextBox.GetText())
+	}
+	notes := g.todoItem(1).NotesArea
+	if notes.GetText() != "Half written" {
+		t.Errorf("Expected the notes edit back in its row, got %q", notes.GetText())
+	}
+	if !g.uiManager.focus.IsFocused(notes) {
+		t.Error("Expected the notes editor to get its focus back")
+	}
+
+	// Edits of rows out of view are kept as drafts
+	g.uiManager.list.ScrollToRow(29)
+	settleList(g)
+	if err := g.Close(); err != nil {
+		t.Fatalf("Failed to close game: %v", err)
+	}
+	drafts, err := storage.LoadDrafts(filepath.Join(dir, "drafts.json"))
+	if err != nil {
+		t.Fatalf("Failed to load drafts: %v", err)
+	}
+	if len(drafts.Todos) != 2 || drafts.Todos[0].ID != first || *drafts.Todos[0].Text != "Todo 1, edited" || *drafts.Todos[1].Notes != "Half written" {
+		t.Errorf("Expected both edits kept as drafts, got %+v", drafts.Todos)
+	}
+}
+
+// settleList runs list frames until it stops scrolling.
+func settleList(g *Game) {
+	for i := 0; i < 200 && g.uiManager.list.IsScrolling(); i++ {
+		g.uiManager.list.Update(g.input)
+	}
+}
+
+func TestCloseTwice(t *testing.T) {
+	dir := t.TempDir()
+	g := newTestGameIn(t, dir)
+	g.uiManager.inputBox.SetText("Water the")
+	if err := g.Close(); err != nil {
+		t.Fatalf("Failed to close game: %v", err)
+	}
+
+	// Closing again after quitting does not write the drafts again
+	path := filepath.Join(dir, "drafts.json")
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Expected the drafts to be saved: %v", err)
+	}
+	if err := g.Close(); err != nil {
+		t.Errorf("Expected the second close to succeed, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the second close to do nothing, got %v", err)
+	}
+}
+
+func TestWindowCloseWaitsForSave(t *testing.T) {
+	g := newTestGame(t)
+	closing := false
+	g.lifecycle.windowClosing = func() bool { return closing }
+	g.saver = storage.NewAsyncStorage(failingStorage{}, g.clock.(*models.FakeClock), time.Hour, time.Hour)
+	g.storage = g.saver
+	g.uiManager.inputBox.SetText("Buy milk")
+	g.addTodo()
+
+	if err := g.Update(); err != nil {
+		t.Fatalf("Expected the game to keep running, got %v", err)
+	}
+
+	// A failed save keeps the window open to show why
+	closing = true
+	if err := g.Update(); err != nil || !strings.Contains(g.error, "disk full") {
+		t.Fatalf("Expected the game to stay open with the error, got %v and %q", err, g.error)
+	}
+	if !g.saver.Pending() {
+		t.Error("Expected the failed save to stay pending")
+	}
+
+	// Closing again quits anyway
+	if err := g.Update(); !errors.Is(err, ebiten.Termination) {
+		t.Errorf("Expected the second close to terminate, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package game
+
+import (
+	"image"
+
+	"github.com/lapis2411/todo/internal/layout"
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// buildLayout builds the layout tree of the window: the header with the
+// input row, the todo list with the bulk toolbar or the board in its place,
+// and the footer with the
+// filter, archive, trash and stats buttons and the todo count, and the archive, trash,
+// details, statistics and calendar panels and the command palette
+// floating above them.
+func buildLayout(m *UIManager) layout.Node {
+	inputRow := layout.Row(
+		layout.Flex(1, layout.Leaf(0, 35, m.inputBox.SetBounds)),
+		layout.Fixed(100, layout.Leaf(100, 35, m.addButton.SetBounds)),
+		layout.Fixed(120, layout.Leaf(120, 35, m.searchBox.SetBounds)),
+		layout.Fixed(60, layout.Leaf(60, 35, m.viewButton.SetBounds)),
+	)
+	inputRow.Padding = layout.Insets{Top: 20, Left: 20, Right: 20}
+	inputRow.Gap = 20
+	inputRow.Align = layout.AlignStart
+
+	filterRow := layout.Row()
+	filterRow.Padding = layout.Insets{Top: 20, Left: 20, Right: 20}
+	filterRow.Gap = 5
+	filterRow.Align = layout.AlignStart
+	for _, filter := range []models.FilterType{models.FilterAll, models.FilterActive, models.FilterCompleted} {
+		filterRow.Children = append(filterRow.Children, layout.Fixed(75, layout.Leaf(75, 25, m.filterButtons[filter].SetBounds)))
+	}
+	filterRow.Children = append(filterRow.Children,
+		layout.Fixed(120, layout.Leaf(120, 25, m.clearButton.SetBounds)),
+		layout.Fixed(70, layout.Leaf(70, 25, m.archiveButton.SetBounds)),
+		layout.Fixed(60, layout.Leaf(60, 25, m.trashButton.SetBounds)),
+		layout.Fixed(60, layout.Leaf(60, 25, m.statsButton.SetBounds)),
+		// The count takes the rest of the row, so it cannot run into the
+		// buttons however narrow the window is
+		layout.Flex(1, layout.Leaf(0, 25, func(r image.Rectangle) { m.countRect = r })),
+	)
+
+	header := layout.NewStack(layout.Anchored(layout.Fill, layout.Rect(&m.headerRect)), layout.Anchored(layout.Fill, inputRow))
+	content := layout.NewStack(
+		layout.Anchored(layout.Fill, layout.Rect(&m.contentRect)),
+		// The scrollbar sits in the right margin, so rows line up with the
+		// header. The bulk toolbar takes room below the list while shown.
+		layout.Layer{
+			Node: layout.Column(
+				layout.Flex(1, layout.Leaf(0, 0, m.list.SetBounds)),
+				layout.Auto(m.bulkBar.node()),
+			),
+			Margin: layout.Insets{Top: 10, Left: 20, Right: 8},
+		},
+		layout.Layer{Node: layout.Rect(&m.board.rect), Margin: layout.Insets{Top: 10, Left: 20, Right: 20, Bottom: 10}},
+	)
+	footer := layout.NewStack(layout.Anchored(layout.Fill, layout.Rect(&m.footerRect)), layout.Anchored(layout.Fill, filterRow))
+
+	return layout.NewStack(
+		layout.Anchored(layout.Fill, layout.Column(
+			layout.Fixed(HeaderHeight, header),
+			layout.Flex(1, content),
+			layout.Fixed(FooterHeight, footer),
+		)),
+		layout.Layer{Node: m.archive.node(), Margin: layout.Uniform(40)},
+		layout.Layer{Node: m.trash.node(), Margin: layout.Uniform(40)},
+		layout.Layer{Node: m.details.node(), Margin: layout.Uniform(40)},
+		layout.Layer{Node: m.dashboard.node(), Margin: layout.Uniform(40)},
+		layout.Layer{Node: m.calendar.node(), Margin: layout.Uniform(40)},
+		layout.Layer{
+			Node:   layout.Leaf(500, 0, m.palette.SetBounds),
+			Anchor: layout.Top,
+			Margin: layout.Insets{Top: 60, Left: 20, Right: 20},
+		},
+	)
+}
+
+// relayout arranges the widgets for the current window size.
+func (g *Game) relayout() {
+	m := g.uiManager
+	m.layout.Arrange(image.Rect(0, 0, m.windowWidth, m.windowHeight))
+}
@@ -0,0 +1,143 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/storage"
+)
+
+// The game quits when the window's close button is pressed, when the
+// process is sent one of quitSignals, or on RequestQuit. Each way ends in
+// the next Update, which keeps the edits still open as drafts and writes
+// every pending save before returning ebiten.Termination. The drafts are
+// reopened on the next launch.
+
+// quitSignals are the signals that quit the game cleanly.
+var quitSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// draftsFile is the name of the file the drafts are kept in, next to the
+// todos.
+const draftsFile = "drafts.json"
+
+// lifecycle collects the requests to quit.
+type lifecycle struct {
+	quit chan struct{} // Holds a request to quit until Update sees it
+	// windowClosing reports whether the window's close button was
+	// pressed. Main must call ebiten.SetWindowClosingHandled(true) for
+	// Ebiten to leave the window open until the game quits.
+	windowClosing func() bool
+	// failed is set when saving on the way out failed and the game stayed
+	// open to show why.
+	failed bool
+	closed bool // Everything was saved by Close
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{
+		quit:          make(chan struct{}, 1),
+		windowClosing: ebiten.IsWindowBeingClosed,
+	}
+}
+
+// RequestQuit asks the game to save and quit at the next Update. It is
+// safe to call from any goroutine.
+func (g *Game) RequestQuit() {
+	select {
+	case g.lifecycle.quit <- struct{}{}:
+	default:
+	}
+}
+
+// HandleSignals quits the game cleanly on SIGINT or SIGTERM until stop is
+// called. A second signal is left to the default handling, which kills
+// the process, in case the game is stuck.
+func (g *Game) HandleSignals() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, quitSignals...)
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			g.RequestQuit()
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// quitRequested reports whether the game has been asked to quit since the
+// last frame.
+func (g *Game) quitRequested() bool {
+	if g.lifecycle.windowClosing() {
+		return true
+	}
+	select {
+	case <-g.lifecycle.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// shutdown saves everything and ends the game. When saving fails the game
+// stays open with the error, so that no work is lost without the user
+// knowing; asking to quit again quits anyway, and main reports the error.
+func (g *Game) shutdown() error {
+	err := g.Close()
+	if err == nil || g.lifecycle.failed {
+		return ebiten.Termination
+	}
+	g.lifecycle.failed = true
+	g.error = fmt.Sprintf("Failed to save: %v (close again to quit anyway)", err)
+	return nil
+}
+
+// Close keeps the edits still open as drafts and writes any changes still
+// waiting to be saved. Call it when the game stops running; once it has
+// succeeded, calling it again does nothing.
+func (g *Game) Close() error {
+	if g.lifecycle.closed {
+		return nil
+	}
+	draftsErr := storage.SaveDrafts(g.draftsPath, g.drafts())
+	if err := g.saver.Close(); err != nil {
+		return err
+	}
+	if draftsErr != nil {
+		return draftsErr
+	}
+	g.lifecycle.closed = true
+	return nil
+}
+
+// restoreDrafts reopens the edits kept when the game last quit. Drafts of
+// todos that are gone are dropped.
+func (g *Game) restoreDrafts() {
+	drafts, err := storage.LoadDrafts(g.draftsPath)
+	if err != nil {
+		g.error = fmt.Sprintf("Failed to load drafts: %v", err)
+		return
+	}
+	m := g.uiManager
+	m.inputBox.SetText(drafts.Input)
+
+	for _, draft := range drafts.Todos {
+		if g.todos.FindTodo(draft.ID) == nil {
+			continue
+		}
+		m.edits[draft.ID] = todoEdit{TodoDraft: draft}
+		if draft.Notes != nil {
+			m.expandedNotes[draft.ID] = true
+		}
+	}
+	g.updateTodoItems()
+}
@@ -0,0 +1,104 @@
+package game
+
+import (
+	"strings"
+
+	"github.com/lapis2411/todo/internal/keymap"
+	"github.com/lapis2411/todo/internal/markdown"
+	"github.com/lapis2411/todo/internal/models"
+	"github.com/lapis2411/todo/internal/ui"
+)
+
+// openPalette shows the command palette with every action and todo. Focus
+// returns to where it was when the palette closes, unless the chosen item
+// moves it.
+func (g *Game) openPalette() {
+	m := g.uiManager
+	previous := m.focus.Focused()
+	m.palette.OnClose = func() {
+		m.focus.Focus(previous)
+	}
+	m.palette.Open(g.paletteItems())
+	m.focus.Focus(m.palette.Input)
+}
+
+// paletteItems lists the keymap actions, the themes and then the todos.
+func (g *Game) paletteItems() []ui.PaletteItem {
+	var items []ui.PaletteItem
+	for _, b := range g.keymap.Bindings() {
+		if b.Action == actionPalette {
+			continue
+		}
+		items = append(items, ui.PaletteItem{
+			Label:  b.Description,
+			Detail: chordsText(b.Chords),
+			Run: func(action string) func() {
+				return func() { g.runAction(action) }
+			}(b.Action),
+		})
+	}
+
+	for _, theme := range g.themes {
+		detail := "Theme"
+		if theme == ui.CurrentTheme() {
+			detail = "Current theme"
+		}
+		items = append(items, ui.PaletteItem{
+			Label:  "Theme: " + theme.Name,
+			Detail: detail,
+			Run: func(name string) func() {
+				return func() { g.setTheme(name) }
+			}(theme.Name),
+		})
+	}
+
+	for _, todo := range g.todos.Todos {
+		detail := "Todo"
+		if todo.Completed {
+			detail = "Done"
+		}
+		items = append(items, ui.PaletteItem{
+			Label:  markdown.PlainText(markdown.ParseInline(todo.Text)),
+			Detail: detail,
+			Run: func(todoID string) func() {
+				return func() { g.revealTodo(todoID) }
+			}(todo.ID),
+		})
+	}
+	return items
+}
+
+// revealTodo selects a todo in the list and focuses the list, clearing the
+// search and filter first if they hide it.
+func (g *Game) revealTodo(id string) {
+	if !g.isTodoVisible(id) && g.searchQuery != "" {
+		g.uiManager.searchBox.Clear()
+		g.setSearchQuery("")
+	}
+	if !g.isTodoVisible(id) {
+		g.setFilter(models.FilterAll)
+	}
+
+	if i := g.indexOfTodo(id); i >= 0 {
+		g.selectTodo(i)
+		g.updateFocusOrder()
+		g.uiManager.focus.Focus(g.uiManager.todoList)
+	}
+}
+
+func (g *Game) isTodoVisible(id string) bool {
+	for _, todo := range g.visibleTodos() {
+		if todo.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func chordsText(chords []keymap.Chord) string {
+	names := make([]string, len(chords))
+	for i, c := range chords {
+		names[i] = c.String()
+	}
+	return strings.Join(names, ", ")
+}
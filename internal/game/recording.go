@@ -0,0 +1,341 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lapis2411/todo/internal/models"
+	"github.com/lapis2411/todo/internal/ui"
+)
+
+// A recording captures a session so that it can be replayed exactly: the
+// data files it started from, the input of every frame, when each frame
+// started, the window size and the IDs given to new todos. The clock
+// stands still within a frame, so a replay makes the same changes at the
+// same times and must end with the same todo list. That makes recordings
+// of bugs usable as regression tests.
+
+const recordingVersion = 1
+
+// todoFile is the name the todo file is recorded and replayed under.
+const todoFile = "todos.json"
+
+// dataFiles are the other files in the data directory a session starts
+// from.
+var dataFiles = []string{"settings.json", "keymap.json", "archive.json", "trash.json", draftsFile}
+
+// Recording is a recorded session.
+type Recording struct {
+	Version int       `json:"version"`
+	Start   time.Time `json:"start"`
+	// DeviceScale is the device scale factor of the monitor the session
+	// was recorded on.
+	DeviceScale float64 `json:"device_scale"`
+	// Files holds the data files at the start by name.
+	Files  map[string]string `json:"files"`
+	Frames []RecordedFrame   `json:"frames"`
+	// IDs are the IDs given to new todos, in order.
+	IDs []string `json:"ids,omitempty"`
+	// Todos is the todo list at the end, which a replay must end with.
+	Todos []models.Todo `json:"todos"`
+}
+
+// RecordedFrame is the input of one frame.
+type RecordedFrame struct {
+	ui.InputFrame
+	At time.Duration `json:"at"` // Since the start
+	// Size is the window size, recorded when it changes.
+	Size *image.Point `json:"size,omitempty"`
+}
+
+// LoadRecording reads a recording from path.
+func LoadRecording(path string) (*Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rec Recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("failed to parse recording: %w", err)
+	}
+	if rec.Version != recordingVersion {
+		return nil, fmt.Errorf("unsupported recording version %d", rec.Version)
+	}
+	return &rec, nil
+}
+
+// Save writes the recording to path.
+func (r *Recording) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recording: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// verify compares todos with the recorded end state.
+func (r *Recording) verify(todos []models.Todo) error {
+	if len(todos) != len(r.Todos) {
+		return fmt.Errorf("expected %d todos, got %d", len(r.Todos), len(todos))
+	}
+	for i := range todos {
+		want, got := normalizedJSON(r.Todos[i]), normalizedJSON(todos[i])
+		if !bytes.Equal(want, got) {
+			return fmt.Errorf("todo %d: expected %s, got %s", i+1, want, got)
+		}
+	}
+	return nil
+}
+
+// normalizedJSON returns todo as it is saved and loaded again, so that
+// empty and missing lists compare equal.
+func normalizedJSON(todo models.Todo) []byte {
+	data, _ := json.Marshal(todo)
+	var loaded models.Todo
+	if json.Unmarshal(data, &loaded) == nil {
+		data, _ = json.Marshal(loaded)
+	}
+	return data
+}
+
+// readDataFiles returns the data files next to the todo file at
+// storagePath.
+func readDataFiles(storagePath string) (map[string]string, error) {
+	files := map[string]string{}
+	paths := map[string]string{todoFile: storagePath}
+	for _, name := range dataFiles {
+		paths[name] = filepath.Join(filepath.Dir(storagePath), name)
+	}
+	for name, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		files[name] = string(data)
+	}
+	return files, nil
+}
+
+// recorder captures the input of a game as it runs.
+type recorder struct {
+	rec    Recording
+	path   string
+	source ui.InputSource    // Where the input comes from
+	wall   models.Clock      // Where frame times come from
+	clock  *models.FakeClock // The game clock, set at the start of each frame
+	played *ui.ScriptedInput // The captured frames, which the game reads
+	ids    *recordingIDs
+	// size is the window size from the last Layout, and lastSize the one
+	// last recorded.
+	size, lastSize image.Point
+}
+
+// recordingIDs keeps the IDs its source gives out.
+type recordingIDs struct {
+	source models.IDGenerator
+	issued []string
+}
+
+func (r *recordingIDs) NewID() string {
+	id := r.source.NewID()
+	r.issued = append(r.issued, id)
+	return id
+}
+
+// NewRecordingGame creates a game on the todos at storagePath that records
+// the session, to be saved to recordingPath with SaveRecording.
+func NewRecordingGame(storagePath, recordingPath string) (*Game, error) {
+	return newRecordingGame(storagePath, recordingPath, ui.EbitenInput, models.SystemClock, models.UUIDs)
+}
+
+func newRecordingGame(storagePath, recordingPath string, source ui.InputSource, wall models.Clock, ids models.IDGenerator) (*Game, error) {
+	files, err := readDataFiles(storagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Times are kept without a monotonic reading, so that the start plus
+	// the time of a frame gives back the time the game saw
+	start := wall.Now().Round(0)
+	clock := models.NewFakeClock(start)
+	// Text pasted from other apps cannot be replayed, so the session keeps
+	// its own clipboard, which starts empty as it does in a replay
+	ui.DefaultClipboard = ui.NewMemoryClipboard()
+	issued := &recordingIDs{source: ids}
+	g, err := newGame(storagePath, clock, issued)
+	if err != nil {
+		return nil, err
+	}
+
+	played := ui.NewScriptedInput()
+	g.input = played
+	g.recorder = &recorder{
+		rec:    Recording{Version: recordingVersion, Start: start, Files: files},
+		path:   recordingPath,
+		source: source,
+		wall:   wall,
+		clock:  clock,
+		played: played,
+		ids:    issued,
+	}
+	return g, nil
+}
+
+// capture records the input of a new frame and passes it on to the game.
+func (r *recorder) capture(g *Game) {
+	now := r.wall.Now().Round(0)
+	r.clock.Set(now)
+	if len(r.rec.Frames) == 0 {
+		r.rec.DeviceScale = g.deviceScale()
+	}
+
+	frame := RecordedFrame{InputFrame: ui.CaptureFrame(r.source), At: now.Sub(r.rec.Start)}
+	if r.size != r.lastSize {
+		size := r.size
+		frame.Size = &size
+		r.lastSize = r.size
+	}
+	r.rec.Frames = append(r.rec.Frames, frame)
+	r.played.Add(frame.InputFrame)
+	r.played.Next()
+}
+
+// SaveRecording writes what has been recorded so far, with the current
+// todo list as the end state.
+func (g *Game) SaveRecording() error {
+	r := g.recorder
+	if r == nil {
+		return errors.New("the game is not recording")
+	}
+	r.rec.IDs = r.ids.issued
+	r.rec.Todos = g.todos.Todos
+	return r.rec.Save(r.path)
+}
+
+// replay drives a game from a recording.
+type replay struct {
+	rec   *Recording
+	clock *models.FakeClock
+	input *ui.ScriptedInput
+	frame int         // The next frame
+	size  image.Point // The recorded window size
+	done  bool
+	err   error // Why the end state differs from the recording
+}
+
+// playbackIDs gives out the recorded IDs in order.
+type playbackIDs struct {
+	ids  []string
+	next int
+}
+
+func (p *playbackIDs) NewID() string {
+	p.next++
+	if p.next <= len(p.ids) {
+		return p.ids[p.next-1]
+	}
+	// The replay made more todos than the recording did, which it will
+	// report when it ends
+	return fmt.Sprintf("replay-%d", p.next)
+}
+
+// NewReplayGame creates a game that replays rec one frame per update. The
+// recorded data files are written to dataDir, which should be empty.
+func NewReplayGame(rec *Recording, dataDir string) (*Game, error) {
+	for name, data := range rec.Files {
+		if name != filepath.Base(name) {
+			return nil, fmt.Errorf("invalid data file name %q", name)
+		}
+		if err := os.WriteFile(filepath.Join(dataDir, name), []byte(data), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	// The session started with an empty clipboard
+	ui.DefaultClipboard = ui.NewMemoryClipboard()
+
+	clock := models.NewFakeClock(rec.Start)
+	g, err := newGame(filepath.Join(dataDir, todoFile), clock, &playbackIDs{ids: rec.IDs})
+	if err != nil {
+		return nil, err
+	}
+	scale := rec.DeviceScale
+	if scale <= 0 {
+		scale = 1
+	}
+	g.deviceScale = func() float64 { return scale }
+	g.openURL = func(string) error { return nil }
+
+	frames := make([]ui.InputFrame, len(rec.Frames))
+	for i, frame := range rec.Frames {
+		frames[i] = frame.InputFrame
+	}
+	g.replay = &replay{rec: rec, clock: clock, input: ui.NewScriptedInput(frames...)}
+	g.input = g.replay.input
+	if len(rec.Frames) > 0 && rec.Frames[0].Size != nil {
+		g.replay.size = *rec.Frames[0].Size
+		g.applyScale(g.replay.size.X, g.replay.size.Y)
+	}
+	return g, nil
+}
+
+// step moves the replay to its next frame. At the end it checks the todo
+// list and reports false.
+func (r *replay) step(g *Game) bool {
+	if r.done {
+		return false
+	}
+	if !r.input.Next() {
+		r.done = true
+		r.err = r.rec.verify(g.todos.Todos)
+		if r.err != nil {
+			g.error = fmt.Sprintf("Replay differs from the recording: %v", r.err)
+		} else {
+			g.error = "Replay finished with the recorded todos"
+		}
+		return false
+	}
+
+	frame := r.rec.Frames[r.frame]
+	r.frame++
+	r.clock.Set(r.rec.Start.Add(frame.At))
+	if frame.Size != nil && *frame.Size != r.size {
+		r.size = *frame.Size
+		g.applyScale(r.size.X, r.size.Y)
+	}
+	return true
+}
+
+// ReplayResult reports whether a replay has ended and, if it has, how its
+// todo list differs from the recording.
+func (g *Game) ReplayResult() (done bool, err error) {
+	if g.replay == nil {
+		return false, errors.New("the game is not replaying")
+	}
+	return g.replay.done, g.replay.err
+}
+
+// Replay runs rec to the end without a window, on data files written to
+// dataDir, and reports how its todo list differs from the recording.
+func Replay(rec *Recording, dataDir string) error {
+	g, err := NewReplayGame(rec, dataDir)
+	if err != nil {
+		return err
+	}
+	defer g.Close()
+	for !g.replay.done {
+		if err := g.Update(); err != nil {
+			return err
+		}
+	}
+	return g.replay.err
+}
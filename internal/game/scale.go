@@ -0,0 +1,133 @@
+package game
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/storage"
+	"github.com/lapis2411/todo/internal/ui"
+)
+
+// zoomLevels are the zoom steps offered by zoom in and zoom out.
+var zoomLevels = []float64{0.5, 0.67, 0.75, 0.9, 1, 1.1, 1.25, 1.5, 1.75, 2, 2.5, 3}
+
+func deviceScaleFactor() float64 {
+	if m := ebiten.Monitor(); m != nil {
+		return m.DeviceScaleFactor()
+	}
+	return 1
+}
+
+// applyScale updates the UI scale from the device scale and the zoom level
+// for a window of the given size in device-independent pixels. It returns
+// the screen size in device pixels, and relayouts when the size of the UI
+// changes.
+func (g *Game) applyScale(outsideWidth, outsideHeight int) (int, int) {
+	deviceScale := g.deviceScale()
+	if deviceScale <= 0 {
+		deviceScale = 1
+	}
+	screenWidth := int(math.Ceil(float64(outsideWidth) * deviceScale))
+	screenHeight := int(math.Ceil(float64(outsideHeight) * deviceScale))
+
+	s := deviceScale * g.zoom()
+	width, height := int(float64(screenWidth)/s), int(float64(screenHeight)/s)
+	m := g.uiManager
+	if s != ui.Scale() || width != m.windowWidth || height != m.windowHeight {
+		ui.SetScale(s)
+		m.windowWidth = width
+		m.windowHeight = height
+		g.relayout()
+	}
+	return screenWidth, screenHeight
+}
+
+// drawScaled draws the UI straight onto the screen at the UI scale, so
+// that shapes and text are rendered at the display's resolution. Without
+// the scalable font it falls back to drawing the UI at its own size into
+// an offscreen image and scaling that onto the screen, where whole-number
+// scales keep the bitmap font sharp.
+func (g *Game) drawScaled(screen *ebiten.Image) {
+	s := ui.Scale()
+	if s == 1 || ui.ScalableText() {
+		ui.SetDrawScale(s)
+		g.drawUI(screen)
+		return
+	}
+
+	width, height := g.uiManager.windowWidth, g.uiManager.windowHeight
+	if width <= 0 || height <= 0 {
+		return
+	}
+	if g.canvas == nil || g.canvas.Bounds().Dx() != width || g.canvas.Bounds().Dy() != height {
+		if g.canvas != nil {
+			g.canvas.Deallocate()
+		}
+		g.canvas = ebiten.NewImage(width, height)
+	}
+	g.canvas.Clear()
+	ui.SetDrawScale(1)
+	g.drawUI(g.canvas)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(s, s)
+	if s == math.Trunc(s) {
+		op.Filter = ebiten.FilterNearest
+	} else {
+		op.Filter = ebiten.FilterLinear
+	}
+	screen.DrawImage(g.canvas, op)
+}
+
+// zoom returns the user's zoom level, 1 when none is saved. A level saved
+// outside the zoom steps, as by editing the settings file, is clamped to
+// them.
+func (g *Game) zoom() float64 {
+	if g.settings.Zoom <= 0 {
+		return 1
+	}
+	return clampZoom(g.settings.Zoom)
+}
+
+func clampZoom(zoom float64) float64 {
+	return math.Max(zoomLevels[0], math.Min(zoom, zoomLevels[len(zoomLevels)-1]))
+}
+
+// setZoom changes the zoom level and remembers it for the next start. The
+// new scale takes effect at the next Layout.
+func (g *Game) setZoom(zoom float64) {
+	zoom = clampZoom(zoom)
+	if zoom == g.zoom() {
+		return
+	}
+	g.settings.Zoom = zoom
+	if zoom == 1 {
+		g.settings.Zoom = 0
+	}
+	g.error = ""
+	if err := storage.SaveSettings(g.settingsPath, g.settings); err != nil {
+		g.error = fmt.Sprintf("Failed to save settings: %v", err)
+	}
+}
+
+// zoomIn steps to the next larger zoom level.
+func (g *Game) zoomIn() {
+	for _, level := range zoomLevels {
+		if level > g.zoom()+1e-9 {
+			g.setZoom(level)
+			return
+		}
+	}
+}
+
+// zoomOut steps to the next smaller zoom level.
+func (g *Game) zoomOut() {
+	for i := len(zoomLevels) - 1; i >= 0; i-- {
+		if zoomLevels[i] < g.zoom()-1e-9 {
+			g.setZoom(zoomLevels[i])
+			return
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package game
+
+import (
+	"github.com/lapis2411/todo/internal/ui"
+)
+
+// The list keeps a set of selected todos and a cursor. The cursor is the
+// row the arrow keys move from and the anchor of Shift ranges; it is always
+// part of the selection unless the selection is empty.
+
+// selectTodo selects only the row at index i and scrolls it into view.
+func (g *Game) selectTodo(i int) {
+	m := g.uiManager
+	if i < 0 || i >= m.list.Count() {
+		return
+	}
+	m.selectedID = m.todos[i].ID
+	m.anchorID = m.selectedID
+	m.selectedIDs = map[string]bool{m.selectedID: true}
+	g.syncSelection()
+	m.list.ScrollToRow(i)
+}
+
+// toggleSelection adds the row at index i to the selection or removes it,
+// as Ctrl-click does.
+func (g *Game) toggleSelection(i int) {
+	m := g.uiManager
+	if i < 0 || i >= m.list.Count() {
+		return
+	}
+	id := m.todos[i].ID
+	if m.selectedIDs[id] {
+		delete(m.selectedIDs, id)
+	} else {
+		m.selectedIDs[id] = true
+	}
+	m.selectedID = id
+	m.anchorID = id
+	g.syncSelection()
+	m.list.ScrollToRow(i)
+}
+
+// extendSelection selects the rows from the anchor to index i, as
+// Shift-click and Shift+arrow do. The anchor stays put.
+func (g *Game) extendSelection(i int) {
+	m := g.uiManager
+	if i < 0 || i >= m.list.Count() {
+		return
+	}
+	anchor := g.indexOfTodo(m.anchorID)
+	if anchor < 0 {
+		g.selectTodo(i)
+		return
+	}
+	m.selectedIDs = make(map[string]bool)
+	for j := min(anchor, i); j <= max(anchor, i); j++ {
+		m.selectedIDs[m.todos[j].ID] = true
+	}
+	m.selectedID = m.todos[i].ID
+	g.syncSelection()
+	m.list.ScrollToRow(i)
+}
+
+// selectAll selects every row in the list, keeping the cursor.
+func (g *Game) selectAll() {
+	m := g.uiManager
+	if m.list.Count() == 0 {
+		return
+	}
+	m.selectedIDs = make(map[string]bool, len(m.todos))
+	for _, todo := range m.todos {
+		m.selectedIDs[todo.ID] = true
+	}
+	if g.selectedIndex() < 0 {
+		m.selectedID = m.todos[0].ID
+		m.anchorID = m.selectedID
+	}
+	g.syncSelection()
+}
+
+// clearSelection collapses the selection to the cursor row.
+func (g *Game) clearSelection() {
+	if i := g.selectedIndex(); i >= 0 {
+		g.selectTodo(i)
+	}
+}
+
+// pruneSelection drops selected todos that have left the list, so bulk
+// actions only apply to rows the user can see.
+func (g *Game) pruneSelection() {
+	m := g.uiManager
+	if len(m.selectedIDs) == 0 {
+		return
+	}
+	selected := make(map[string]bool, len(m.selectedIDs))
+	for _, todo := range m.todos {
+		if m.selectedIDs[todo.ID] {
+			selected[todo.ID] = true
+		}
+	}
+	m.selectedIDs = selected
+	g.syncSelection()
+}
+
+// selectedTodoIDs returns the IDs of the selected todos in list order.
+func (g *Game) selectedTodoIDs() []string {
+	m := g.uiManager
+	if len(m.selectedIDs) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(m.selectedIDs))
+	for _, todo := range m.todos {
+		if m.selectedIDs[todo.ID] {
+			ids = append(ids, todo.ID)
+		}
+	}
+	return ids
+}
+
+// isMultiSelect reports whether more than one row is selected, which is
+// when the bulk toolbar shows and list keys act on the whole selection.
+func (g *Game) isMultiSelect() bool {
+	return len(g.uiManager.selectedIDs) > 1
+}
+
+// syncSelection marks the selected rows and shows or hides the bulk
+// toolbar to match the selection.
+func (g *Game) syncSelection() {
+	m := g.uiManager
+	m.list.ForEachRow(func(j int, row ui.ListRow) {
+		row.(*ui.TodoItem).Selected = m.selectedIDs[m.todos[j].ID]
+	})
+	if shown := g.isMultiSelect(); shown != m.bulkBar.visible {
+		m.bulkBar.visible = shown
+		g.relayout()
+	}
+}
@@ -0,0 +1,281 @@
+package game
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/keymap"
+	"github.com/lapis2411/todo/internal/models"
+	"github.com/lapis2411/todo/internal/ui"
+)
+
+// Names of the actions that can be bound in the keymap file.
+const (
+	actionNewTodo         = "new-todo"
+	actionSearch          = "search"
+	actionFilterAll       = "filter-all"
+	actionFilterActive    = "filter-active"
+	actionFilterCompleted = "filter-completed"
+	actionHelp            = "help"
+	actionPalette         = "palette"
+	actionNextTheme       = "next-theme"
+	actionZoomIn          = "zoom-in"
+	actionZoomOut         = "zoom-out"
+	actionZoomReset       = "zoom-reset"
+	actionUndo            = "undo"
+	actionClearCompleted  = "clear-completed"
+	actionArchive         = "archive"
+	actionTrash           = "trash"
+	actionDetails         = "details"
+	actionDashboard       = "dashboard"
+	actionBoard           = "board"
+	actionCalendar        = "calendar"
+)
+
+var defaultActions = []keymap.Action{
+	{Name: actionNewTodo, Description: "New todo", Defaults: []string{"Ctrl+N"}, WhileTyping: true},
+	{Name: actionSearch, Description: "Search todos", Defaults: []string{"Ctrl+F"}, WhileTyping: true},
+	{Name: actionFilterAll, Description: "Show all todos", Defaults: []string{"Alt+1"}},
+	{Name: actionFilterActive, Description: "Show active todos", Defaults: []string{"Alt+2"}},
+	{Name: actionFilterCompleted, Description: "Show completed todos", Defaults: []string{"Alt+3"}},
+	{Name: actionHelp, Description: "Show keyboard shortcuts", Defaults: []string{"F1", "Ctrl+/"}, WhileTyping: true},
+	{Name: actionPalette, Description: "Command palette", Defaults: []string{"Ctrl+Shift+P"}, WhileTyping: true},
+	{Name: actionNextTheme, Description: "Switch to the next theme", Defaults: []string{"Ctrl+T"}},
+	{Name: actionZoomIn, Description: "Zoom in", Defaults: []string{"Ctrl+=", "Ctrl+Shift+="}, WhileTyping: true},
+	{Name: actionZoomOut, Description: "Zoom out", Defaults: []string{"Ctrl+-"}, WhileTyping: true},
+	{Name: actionZoomReset, Description: "Reset zoom", Defaults: []string{"Ctrl+0"}, WhileTyping: true},
+	{Name: actionUndo, Description: "Undo the last change to the todos", Defaults: []string{"Ctrl+Z"}},
+	{Name: actionClearCompleted, Description: "Archive completed todos"},
+	{Name: actionArchive, Description: "Show archived todos", Defaults: []string{"Ctrl+Shift+A"}},
+	{Name: actionTrash, Description: "Show deleted todos"},
+	{Name: actionDetails, Description: "Show details and history of the selected todo", Defaults: []string{"Ctrl+I"}},
+	{Name: actionDashboard, Description: "Show statistics", Defaults: []string{"Ctrl+D"}},
+	{Name: actionBoard, Description: "Switch between the list and the board", Defaults: []string{"Ctrl+B"}},
+	{Name: actionCalendar, Description: "Show the calendar of due dates", Defaults: []string{"Ctrl+L"}},
+}
+
+// reservedChords are the chords that text fields, the list, the board and
+// focus handling act on themselves, which actions may not be bound to.
+var reservedChords = []keymap.Reserved{
+	// Text fields
+	{Chord: "Ctrl+A", Use: "select all"},
+	{Chord: "Ctrl+C", Use: "copy"},
+	{Chord: "Ctrl+X", Use: "cut"},
+	{Chord: "Ctrl+V", Use: "paste"},
+	{Chord: "Ctrl+ArrowLeft", Use: "move by word"},
+	{Chord: "Ctrl+ArrowRight", Use: "move by word"},
+	{Chord: "Ctrl+Shift+ArrowLeft", Use: "select by word"},
+	{Chord: "Ctrl+Shift+ArrowRight", Use: "select by word"},
+	{Chord: "Ctrl+Backspace", Use: "delete a word"},
+	{Chord: "Ctrl+Delete", Use: "delete a word"},
+	{Chord: "Backspace", Use: "delete"},
+	// The list and the board
+	{Chord: "ArrowUp", Use: "move the selection"},
+	{Chord: "ArrowDown", Use: "move the selection"},
+	{Chord: "ArrowLeft", Use: "move the selection"},
+	{Chord: "ArrowRight", Use: "move the selection"},
+	{Chord: "Shift+ArrowUp", Use: "extend the selection"},
+	{Chord: "Shift+ArrowDown", Use: "extend the selection"},
+	{Chord: "Shift+ArrowLeft", Use: "move the selected card"},
+	{Chord: "Shift+ArrowRight", Use: "move the selected card"},
+	{Chord: "PageUp", Use: "move the selection"},
+	{Chord: "PageDown", Use: "move the selection"},
+	{Chord: "Home", Use: "move the selection"},
+	{Chord: "End", Use: "move the selection"},
+	{Chord: "Space", Use: "complete the selected todo"},
+	{Chord: "Enter", Use: "edit the selected todo"},
+	{Chord: "F2", Use: "edit the selected todo"},
+	{Chord: "Delete", Use: "delete"},
+	{Chord: "Escape", Use: "cancel and close"},
+	// Focus
+	{Chord: "Tab", Use: "move focus"},
+	{Chord: "Shift+Tab", Use: "move focus"},
+}
+
+// newKeymap builds the keymap from the defaults and the user's overrides in
+// path. Overrides that fail to load are reported and the defaults are kept.
+func (g *Game) newKeymap(path string) *keymap.Keymap {
+	km, err := keymap.New(defaultActions, reservedChords)
+	if err != nil {
+		// The defaults are fixed, so this is a programming error
+		panic(err)
+	}
+	if err := km.LoadFile(path); err != nil {
+		g.error = fmt.Sprintf("Failed to load keymap: %v", err)
+	}
+	return km
+}
+
+// handleShortcuts runs the action bound to a chord pressed this frame.
+// While a text field has focus only actions that move focus or open an
+// overlay fire, and only on chords that cannot be typed.
+func (g *Game) handleShortcuts(kb *ui.Keyboard) {
+	if action, ok := g.keymap.Triggered(kb, g.isTextInputFocused()); ok {
+		g.runAction(action)
+	}
+}
+
+// runAction runs a keymap action, from a shortcut or the command palette.
+func (g *Game) runAction(action string) {
+	switch action {
+	case actionNewTodo:
+		g.uiManager.focus.Focus(g.uiManager.inputBox)
+		g.uiManager.inputBox.SelectAll()
+	case actionSearch:
+		g.uiManager.focus.Focus(g.uiManager.searchBox)
+		g.uiManager.searchBox.SelectAll()
+	case actionFilterAll:
+		g.setFilter(models.FilterAll)
+	case actionFilterActive:
+		g.setFilter(models.FilterActive)
+	case actionFilterCompleted:
+		g.setFilter(models.FilterCompleted)
+	case actionHelp:
+		g.showHelp = true
+		g.helpScroll = 0
+	case actionPalette:
+		g.openPalette()
+	case actionNextTheme:
+		g.nextTheme()
+	case actionZoomIn:
+		g.zoomIn()
+	case actionZoomOut:
+		g.zoomOut()
+	case actionZoomReset:
+		g.setZoom(1)
+	case actionClearCompleted:
+		g.clearCompleted()
+	case actionArchive:
+		g.openArchive()
+	case actionTrash:
+		g.openTrash()
+	case actionDetails:
+		g.showDetails()
+	case actionDashboard:
+		g.openDashboard()
+	case actionBoard:
+		g.setBoardVisible(!g.uiManager.board.visible)
+	case actionCalendar:
+		g.openCalendar()
+	case actionUndo:
+		// Leave the todos alone while the user is typing
+		if !g.isTextInputFocused() {
+			g.undo()
+		}
+	}
+}
+
+// updateHelp handles input while the help overlay is open. The overlay is
+// modal: it closes on Escape or the help chord, scrolls with the arrow
+// keys, Page Up and Page Down and the wheel when the shortcuts do not fit,
+// and swallows everything else.
+func (g *Game) updateHelp(in ui.InputSource, kb *ui.Keyboard) {
+	if action, ok := g.keymap.Triggered(kb, false); (ok && action == actionHelp) || kb.IsJustPressed(ebiten.KeyEscape) {
+		g.showHelp = false
+		return
+	}
+
+	_, visible := g.helpLayout(len(g.helpRows()))
+	scroll := g.helpScroll
+	switch {
+	case kb.IsTriggered(ebiten.KeyArrowUp):
+		scroll--
+	case kb.IsTriggered(ebiten.KeyArrowDown):
+		scroll++
+	case kb.IsTriggered(ebiten.KeyPageUp):
+		scroll -= visible
+	case kb.IsTriggered(ebiten.KeyPageDown):
+		scroll += visible
+	case kb.IsJustPressed(ebiten.KeyHome):
+		scroll = 0
+	case kb.IsJustPressed(ebiten.KeyEnd):
+		scroll = len(g.helpRows())
+	}
+	if _, dy := in.Wheel(); dy != 0 {
+		scroll -= int(math.Round(dy * 3))
+	}
+	g.helpScroll = g.clampHelpScroll(scroll)
+}
+
+// helpRows returns the shortcut and description of each bound action.
+func (g *Game) helpRows() [][2]string {
+	var rows [][2]string
+	for _, b := range g.keymap.Bindings() {
+		if len(b.Chords) == 0 {
+			continue
+		}
+		rows = append(rows, [2]string{chordsText(b.Chords), b.Description})
+	}
+	return rows
+}
+
+const helpLineHeight = 20
+
+// helpLayout returns the bounds of the help panel, fitted to the window,
+// and how many of count rows it shows at once.
+func (g *Game) helpLayout(count int) (image.Rectangle, int) {
+	const margin = 10
+	width := min(420, g.uiManager.windowWidth-2*margin)
+	height := min(90+count*helpLineHeight, g.uiManager.windowHeight-2*margin)
+	x := (g.uiManager.windowWidth - width) / 2
+	y := (g.uiManager.windowHeight - height) / 2
+	return image.Rect(x, y, x+width, y+height), max((height-90)/helpLineHeight, 1)
+}
+
+// clampHelpScroll limits the first row shown to keep the last page full.
+func (g *Game) clampHelpScroll(scroll int) int {
+	count := len(g.helpRows())
+	_, visible := g.helpLayout(count)
+	return max(0, min(scroll, count-visible))
+}
+
+func (g *Game) isTextInputFocused() bool {
+	switch g.uiManager.focus.Focused().(type) {
+	case *ui.TextBox, *ui.TextArea:
+		return true
+	}
+	return false
+}
+
+func (g *Game) drawHelp(screen *ebiten.Image) {
+	theme := ui.CurrentTheme()
+
+	// Dim the window behind the overlay
+	ui.DrawRect(screen, 0, 0, float64(g.uiManager.windowWidth), float64(g.uiManager.windowHeight), theme.Overlay)
+
+	// The panel shrinks to the window and scrolls the rows that do not fit
+	rows := g.helpRows()
+	panel, visible := g.helpLayout(len(rows))
+	panelX, panelY := panel.Min.X, panel.Min.Y
+	panelWidth, panelHeight := panel.Dx(), panel.Dy()
+	first := g.clampHelpScroll(g.helpScroll)
+	last := min(first+visible, len(rows))
+
+	// Draw panel background and border
+	ui.DrawRect(screen, float64(panelX), float64(panelY), float64(panelWidth), float64(panelHeight), theme.Surface)
+	borderColor := theme.Border
+	ui.DrawRect(screen, float64(panelX), float64(panelY), float64(panelWidth), 1, borderColor)
+	ui.DrawRect(screen, float64(panelX), float64(panelY+panelHeight-1), float64(panelWidth), 1, borderColor)
+	ui.DrawRect(screen, float64(panelX), float64(panelY), 1, float64(panelHeight), borderColor)
+	ui.DrawRect(screen, float64(panelX+panelWidth-1), float64(panelY), 1, float64(panelHeight), borderColor)
+
+	textColor := theme.Text
+	mutedColor := theme.TextMuted
+	ui.DrawText(screen, "Keyboard Shortcuts", panelX+20, panelY+30, textColor)
+
+	y := panelY + 60
+	for _, row := range rows[first:last] {
+		ui.DrawText(screen, row[0], panelX+20, y, textColor)
+		ui.DrawText(screen, ui.TruncateText(row[1], panelWidth-200), panelX+180, y, mutedColor)
+		y += helpLineHeight
+	}
+
+	hint := "Press Esc to close"
+	if visible < len(rows) {
+		hint = fmt.Sprintf("%d-%d of %d   Up/Down: scroll   Esc: close", first+1, last, len(rows))
+	}
+	ui.DrawText(screen, hint, panelX+20, panelY+panelHeight-15, mutedColor)
+}
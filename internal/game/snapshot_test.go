@@ -0,0 +1,95 @@
+//go:build snapshot
+
+package game
+
+import (
+	"testing"
+
+	"github.com/lapis2411/todo/internal/models"
+	"github.com/lapis2411/todo/internal/quickadd"
+	"github.com/lapis2411/todo/internal/snapshot"
+	"github.com/lapis2411/todo/internal/snapshot/headless"
+	"github.com/lapis2411/todo/internal/ui"
+)
+
+// The snapshot tests draw the whole window with Game.Draw and compare it
+// with the goldens in testdata/golden. See package headless for how to
+// run them.
+
+func TestMain(m *testing.M) {
+	headless.Main(m)
+}
+
+func matchGame(t *testing.T, name string, g *Game) {
+	t.Helper()
+	width, height := g.Layout(WindowWidth, WindowHeight)
+	img := headless.Render(width, height, g.Draw)
+	snapshot.Match(t, name, img, snapshot.DefaultOptions)
+}
+
+// newSnapshotGame returns a game with a few todos that show off the row
+// decorations: done, priority, tags, due dates and markup.
+func newSnapshotGame(t *testing.T) *Game {
+	g := newTestGame(t)
+	ui.SetTheme(ui.LightTheme())
+	for _, text := range []string{
+		"Buy milk",
+		"Pay rent tomorrow 9am !high #home every month",
+		"Read **the** [docs](https://example.com) #work",
+		"Call mom",
+	} {
+		g.uiManager.inputBox.SetText(text)
+		g.addTodo()
+	}
+	g.toggleTodo(g.todos.Todos[0].ID)
+
+	// An overdue todo
+	yesterday := testNow.AddDate(0, 0, -1)
+	g.todos.Todos[3].SetDue(&yesterday, testNow)
+	g.updateTodoItems()
+	return g
+}
+
+func TestGameSnapshots(t *testing.T) {
+	defer ui.SetTheme(ui.LightTheme())
+
+	matchGame(t, "game-empty", newTestGame(t))
+
+	g := newSnapshotGame(t)
+	matchGame(t, "game-todos", g)
+
+	g.setFilter(models.FilterActive)
+	matchGame(t, "game-active", g)
+	g.setFilter(models.FilterAll)
+
+	ui.SetTheme(ui.DarkTheme())
+	matchGame(t, "game-todos-dark", g)
+	ui.SetTheme(ui.LightTheme())
+
+	g.uiManager.inputBox.SetText("Dentist on fri 2pm !med #health")
+	g.uiManager.preview = quickadd.Parse(g.uiManager.inputBox.GetText(), g.clock.Now())
+	matchGame(t, "game-quickadd-preview", g)
+}
+
+func TestPanelSnapshots(t *testing.T) {
+	g := newSnapshotGame(t)
+	g.openDashboard()
+	matchGame(t, "game-dashboard", g)
+	g.uiManager.dashboard.visible = false
+
+	g.openCalendar()
+	matchGame(t, "game-calendar", g)
+	g.uiManager.calendar.visible = false
+
+	g.showHelp = true
+	matchGame(t, "game-help", g)
+}
+
+// TestScaledSnapshot draws at twice the size, which goes through the
+// offscreen canvas.
+func TestScaledSnapshot(t *testing.T) {
+	g := newSnapshotGame(t)
+	g.deviceScale = func() float64 { return 2 }
+	defer ui.SetScale(1)
+	matchGame(t, "game-todos-2x", g)
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lapis2411/todo/internal/storage"
+	"github.com/lapis2411/todo/internal/ui"
+)
+
+// loadThemes collects the built-in themes and the custom ones in dir, then
+// applies the theme saved in the settings. Custom themes may replace a
+// built-in theme by using its name.
+func (g *Game) loadThemes(dir string) {
+	g.themes = ui.BuiltinThemes()
+	custom, err := ui.LoadThemeDir(dir, g.themes)
+	if err != nil {
+		g.error = err.Error()
+	}
+	for _, theme := range custom {
+		if i := themeIndex(g.themes, theme.Name); i >= 0 {
+			g.themes[i] = theme
+		} else {
+			g.themes = append(g.themes, theme)
+		}
+	}
+
+	theme, ok := ui.FindTheme(g.themes, g.settings.Theme)
+	if !ok {
+		theme = g.themes[0]
+	}
+	ui.SetTheme(theme)
+}
+
+// setTheme switches to the named theme and remembers it for the next start.
+func (g *Game) setTheme(name string) {
+	theme, ok := ui.FindTheme(g.themes, name)
+	if !ok {
+		g.error = fmt.Sprintf("Unknown theme %q", name)
+		return
+	}
+	ui.SetTheme(theme)
+
+	g.settings.Theme = theme.Name
+	g.error = ""
+	if err := storage.SaveSettings(g.settingsPath, g.settings); err != nil {
+		g.error = fmt.Sprintf("Failed to save settings: %v", err)
+	}
+}
+
+// nextTheme switches to the theme after the active one, wrapping around.
+func (g *Game) nextTheme() {
+	i := themeIndex(g.themes, ui.CurrentTheme().Name)
+	g.setTheme(g.themes[(i+1)%len(g.themes)].Name)
+}
+
+func themeIndex(themes []*ui.Theme, name string) int {
+	for i, t := range themes {
+		if strings.EqualFold(t.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,119 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// Deleted todos go to the trash, where they can be restored until they are
+// deleted for good or purged after the retention period.
+
+// defaultTrashRetentionDays is how long deleted todos are kept when the
+// settings do not say.
+const defaultTrashRetentionDays = 30
+
+func (g *Game) newTrashView() *collectionView {
+	v := newCollectionView(g.trash)
+	v.title = "Trash"
+	v.emptyMessage = "The trash is empty."
+	v.deleteHint = "delete forever"
+	v.detail = func(todo *models.Todo) string {
+		if todo.DeletedAt == nil {
+			return ""
+		}
+		return "Deleted " + todo.DeletedAt.Format("2006-01-02")
+	}
+	v.onRestore = g.restoreTrashed
+	v.onDelete = g.deleteForever
+	return v
+}
+
+// trashTodos marks todos as deleted now and adds them to the trash.
+func (g *Game) trashTodos(todos []models.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+	now := g.clock.Now()
+	for i := range todos {
+		todos[i].DeletedAt = &now
+	}
+	return g.trash.add(todos...)
+}
+
+// deleteTodos moves the todos with the given IDs to the trash as one
+// undoable step. The trash is saved first, and the todos stay in the list
+// if that fails.
+func (g *Game) deleteTodos(ids ...string) {
+	step := g.captureUndo(ids...)
+	if len(step.before) == 0 {
+		return
+	}
+	trashed := make([]models.Todo, len(step.before))
+	for i, entry := range step.before {
+		trashed[i] = entry.todo
+	}
+	if err := g.trashTodos(trashed); err != nil {
+		g.error = fmt.Sprintf("Failed to save trash: %v", err)
+		return
+	}
+
+	g.todos.RemoveTodos(ids...)
+	step.moved, step.movedTo = ids, g.trash
+	g.pushUndo(step)
+	g.error = ""
+	if err := g.saveTodos(); err != nil {
+		g.error = fmt.Sprintf("Failed to save: %v", err)
+	}
+	g.updateTodoItems()
+}
+
+// restoreTrashed moves a deleted todo back to the end of the todo list.
+// Todos deleted from the archive come back as live todos.
+func (g *Game) restoreTrashed(id string) {
+	g.restoreFrom(g.trash, id, func(todo *models.Todo) {
+		todo.DeletedAt = nil
+		todo.ArchivedAt = nil
+	})
+	g.uiManager.trash.refresh()
+}
+
+// deleteForever removes a todo from the trash for good.
+func (g *Game) deleteForever(id string) {
+	g.error = ""
+	if _, err := g.trash.remove(id); err != nil {
+		g.error = fmt.Sprintf("Failed to save trash: %v", err)
+	}
+	g.uiManager.trash.refresh()
+}
+
+// trashRetentionDays returns how many days deleted todos are kept, or a
+// negative number when they are kept until deleted by hand.
+func (g *Game) trashRetentionDays() int {
+	if g.settings.TrashRetentionDays == 0 {
+		return defaultTrashRetentionDays
+	}
+	return g.settings.TrashRetentionDays
+}
+
+// purgeTrash deletes the todos that have been in the trash longer than the
+// retention period.
+func (g *Game) purgeTrash() {
+	days := g.trashRetentionDays()
+	if days < 0 {
+		return
+	}
+	cutoff := g.clock.Now().AddDate(0, 0, -days)
+	_, err := g.trash.removeFunc(func(todo models.Todo) bool {
+		return todo.DeletedAt != nil && todo.DeletedAt.Before(cutoff)
+	})
+	if err != nil {
+		g.error = fmt.Sprintf("Failed to purge trash: %v", err)
+	}
+}
+
+func (g *Game) openTrash() {
+	if err := g.uiManager.trash.open(); err != nil {
+		g.error = fmt.Sprintf("Failed to load trash: %v", err)
+	}
+}
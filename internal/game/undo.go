@@ -0,0 +1,118 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// maxUndoSteps bounds the undo history.
+const maxUndoSteps = 100
+
+// undoStep reverses one user action. It keeps the todos the action changed
+// or removed as they were before, and the IDs of the todos it added, so a
+// step costs memory in proportion to the action rather than the list.
+type undoStep struct {
+	before []undoEntry
+	added  []string
+	// Todos the action moved into a collection, to take back out on undo
+	moved   []string
+	movedTo *collection
+}
+
+type undoEntry struct {
+	index int // Position in the todo list, to put a removed todo back in place
+	todo  models.Todo
+}
+
+// captureUndo records the todos with the given IDs before an action changes
+// them. Entries are kept in list order.
+func (g *Game) captureUndo(ids ...string) *undoStep {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	step := &undoStep{}
+	for i, todo := range g.todos.Todos {
+		if wanted[todo.ID] {
+			step.before = append(step.before, undoEntry{index: i, todo: todo})
+		}
+	}
+	return step
+}
+
+// pushUndo adds a step to the history, dropping the oldest step when full.
+func (g *Game) pushUndo(step *undoStep) {
+	if len(step.before) == 0 && len(step.added) == 0 {
+		return
+	}
+	g.undoStack = append(g.undoStack, step)
+	if len(g.undoStack) > maxUndoSteps {
+		g.undoStack = slices.Delete(g.undoStack, 0, 1)
+	}
+}
+
+// undo reverts the most recent action and saves the result.
+func (g *Game) undo() {
+	if len(g.undoStack) == 0 {
+		return
+	}
+	step := g.undoStack[len(g.undoStack)-1]
+	g.undoStack = g.undoStack[:len(g.undoStack)-1]
+
+	if len(step.added) > 0 {
+		added := make(map[string]bool, len(step.added))
+		for _, id := range step.added {
+			added[id] = true
+		}
+		g.todos.Todos = slices.DeleteFunc(g.todos.Todos, func(todo models.Todo) bool {
+			return added[todo.ID]
+		})
+	}
+
+	// Put changed todos back in place and collect the removed ones
+	index := make(map[string]int, len(g.todos.Todos))
+	for i, todo := range g.todos.Todos {
+		index[todo.ID] = i
+	}
+	var removed []undoEntry
+	for _, entry := range step.before {
+		if i, ok := index[entry.todo.ID]; ok {
+			g.todos.Todos[i] = entry.todo
+		} else {
+			removed = append(removed, entry)
+		}
+	}
+
+	// Merging in list order puts each removed todo back at its old index
+	if len(removed) > 0 {
+		todos := make([]models.Todo, 0, len(g.todos.Todos)+len(removed))
+		rest := g.todos.Todos
+		for _, entry := range removed {
+			for len(todos) < entry.index && len(rest) > 0 {
+				todos = append(todos, rest[0])
+				rest = rest[1:]
+			}
+			todos = append(todos, entry.todo)
+		}
+		g.todos.Todos = append(todos, rest...)
+	}
+
+	// Todos moved to a collection are written back to the todo file before
+	// they are taken out of the collection, so that a failure or a crash in
+	// between leaves them in the collection rather than in neither file
+	g.error = ""
+	if len(step.moved) > 0 {
+		err := g.saveTodosNow()
+		if err == nil {
+			_, err = step.movedTo.remove(step.moved...)
+		}
+		if err != nil {
+			g.error = fmt.Sprintf("Failed to save: %v", err)
+		}
+	} else if err := g.saveTodos(); err != nil {
+		g.error = fmt.Sprintf("Failed to save: %v", err)
+	}
+	g.updateTodoItems()
+}
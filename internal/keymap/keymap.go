@@ -0,0 +1,370 @@
+// Package keymap maps keyboard chords such as Ctrl+N to named actions.
+// Actions declare their default chords, and users may rebind them in a
+// JSON file that maps action names to lists of chords:
+//
+//	{
+//	  "search": ["Ctrl+F", "Ctrl+K"],
+//	  "filter-all": []
+//	}
+//
+// An action listed in the file replaces all of its default chords; an
+// empty list unbinds it.
+package keymap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// Chord is a key pressed together with modifiers. Ctrl also matches the
+// Command key so that shortcuts work the same way on macOS.
+type Chord struct {
+	Key   ebiten.Key
+	Ctrl  bool
+	Shift bool
+	Alt   bool
+}
+
+// KeyState reports the state of the keyboard. ui.Keyboard implements it.
+type KeyState interface {
+	IsPressed(key ebiten.Key) bool
+	IsJustPressed(key ebiten.Key) bool
+}
+
+// punctuationKeys lets chords name punctuation keys by their character.
+var punctuationKeys = map[string]ebiten.Key{
+	"/":  ebiten.KeySlash,
+	"\\": ebiten.KeyBackslash,
+	"-":  ebiten.KeyMinus,
+	"=":  ebiten.KeyEqual,
+	",":  ebiten.KeyComma,
+	".":  ebiten.KeyPeriod,
+	";":  ebiten.KeySemicolon,
+	"'":  ebiten.KeyQuote,
+	"`":  ebiten.KeyBackquote,
+	"[":  ebiten.KeyBracketLeft,
+	"]":  ebiten.KeyBracketRight,
+}
+
+// ParseChord parses a chord written as modifiers and a key joined by "+",
+// for example "Ctrl+Shift+P", "Alt+1" or "F1". Names are case-insensitive.
+func ParseChord(s string) (Chord, error) {
+	var c Chord
+	parts := strings.Split(strings.TrimSpace(s), "+")
+	// "Ctrl++" splits into a trailing empty part. Plus shares its key with
+	// "=" on most layouts, so read it as that key.
+	if n := len(parts); n > 1 && parts[n-1] == "" && parts[n-2] == "" {
+		parts = append(parts[:n-2], "=")
+	}
+	for _, mod := range parts[:len(parts)-1] {
+		switch strings.ToLower(strings.TrimSpace(mod)) {
+		case "ctrl", "control", "cmd", "command", "meta":
+			c.Ctrl = true
+		case "shift":
+			c.Shift = true
+		case "alt", "option":
+			c.Alt = true
+		default:
+			return Chord{}, fmt.Errorf("unknown modifier %q in %q", mod, s)
+		}
+	}
+
+	name := strings.TrimSpace(parts[len(parts)-1])
+	if key, ok := punctuationKeys[name]; ok {
+		c.Key = key
+		return c, nil
+	}
+	if err := c.Key.UnmarshalText([]byte(name)); err != nil || name == "" {
+		return Chord{}, fmt.Errorf("unknown key %q in %q", name, s)
+	}
+	if isModifierKey(c.Key) {
+		return Chord{}, fmt.Errorf("modifier %q cannot be the key of %q", name, s)
+	}
+	return c, nil
+}
+
+// String formats the chord the way ParseChord reads it.
+func (c Chord) String() string {
+	var parts []string
+	if c.Ctrl {
+		parts = append(parts, "Ctrl")
+	}
+	if c.Alt {
+		parts = append(parts, "Alt")
+	}
+	if c.Shift {
+		parts = append(parts, "Shift")
+	}
+	name := strings.TrimPrefix(c.Key.String(), "Digit")
+	for s, key := range punctuationKeys {
+		if key == c.Key {
+			name = s
+		}
+	}
+	return strings.Join(append(parts, name), "+")
+}
+
+// IsGlobal reports whether the chord may fire while a text field has focus
+// without getting in the way of typing.
+func (c Chord) IsGlobal() bool {
+	return c.Ctrl || c.Alt || (c.Key >= ebiten.KeyF1 && c.Key <= ebiten.KeyF24) || c.Key == ebiten.KeyEscape
+}
+
+// Matches reports whether the chord's key was just pressed with exactly
+// its modifiers held.
+func (c Chord) Matches(state KeyState) bool {
+	ctrl := state.IsPressed(ebiten.KeyControl) || state.IsPressed(ebiten.KeyMeta)
+	return state.IsJustPressed(c.Key) &&
+		ctrl == c.Ctrl &&
+		state.IsPressed(ebiten.KeyShift) == c.Shift &&
+		state.IsPressed(ebiten.KeyAlt) == c.Alt
+}
+
+func isModifierKey(key ebiten.Key) bool {
+	switch key {
+	case ebiten.KeyControl, ebiten.KeyControlLeft, ebiten.KeyControlRight,
+		ebiten.KeyShift, ebiten.KeyShiftLeft, ebiten.KeyShiftRight,
+		ebiten.KeyAlt, ebiten.KeyAltLeft, ebiten.KeyAltRight,
+		ebiten.KeyMeta, ebiten.KeyMetaLeft, ebiten.KeyMetaRight:
+		return true
+	}
+	return false
+}
+
+// Action is a named command with its default chords.
+type Action struct {
+	Name        string
+	Description string
+	Defaults    []string
+	// WhileTyping lets the action fire while a text field has focus, for
+	// actions such as moving focus that do not get in the way of typing.
+	WhileTyping bool
+}
+
+// Binding is an action together with the chords that currently trigger it.
+type Binding struct {
+	Action      string
+	Description string
+	Chords      []Chord
+	WhileTyping bool
+}
+
+// Reserved is a chord that widgets handle themselves, such as Ctrl+C in a
+// text field. No action may be bound to it, or the chord would do both.
+type Reserved struct {
+	Chord string
+	Use   string // What the chord does, such as "copy"
+}
+
+// Conflict is a chord bound to more than one action, or to an action and a
+// reserved use.
+type Conflict struct {
+	Chord    Chord
+	Actions  []string
+	Reserved string // The use of a reserved chord
+}
+
+func (c Conflict) String() string {
+	if c.Reserved != "" {
+		return fmt.Sprintf("%s is bound to %s but is reserved to %s", c.Chord, strings.Join(c.Actions, " and "), c.Reserved)
+	}
+	return fmt.Sprintf("%s is bound to %s", c.Chord, strings.Join(c.Actions, " and "))
+}
+
+// Keymap holds the chords bound to each action.
+type Keymap struct {
+	bindings []Binding
+	reserved map[Chord]string
+}
+
+// New creates a keymap with the default chords of actions, which may not
+// use the reserved chords. The order of actions is kept for Bindings.
+func New(actions []Action, reserved []Reserved) (*Keymap, error) {
+	km := &Keymap{reserved: make(map[Chord]string)}
+	for _, r := range reserved {
+		c, err := ParseChord(r.Chord)
+		if err != nil {
+			return nil, &models.AppError{
+				Type:    models.ErrorValidation,
+				Message: "Invalid reserved chord",
+				Err:     err,
+			}
+		}
+		if km.reserved[c] == "" {
+			km.reserved[c] = r.Use
+		}
+	}
+	for _, action := range actions {
+		chords, err := parseChords(action.Defaults)
+		if err != nil {
+			return nil, err
+		}
+		km.bindings = append(km.bindings, Binding{
+			Action:      action.Name,
+			Description: action.Description,
+			Chords:      chords,
+			WhileTyping: action.WhileTyping,
+		})
+	}
+	if conflicts := km.Conflicts(); len(conflicts) > 0 {
+		return nil, conflictError(conflicts)
+	}
+	return km, nil
+}
+
+// Override rebinds actions to the given chords. Nothing is changed when an
+// action is unknown, a chord does not parse, or the result has conflicts.
+func (km *Keymap) Override(overrides map[string][]string) error {
+	updated := &Keymap{bindings: make([]Binding, len(km.bindings)), reserved: km.reserved}
+	copy(updated.bindings, km.bindings)
+
+	for name, chordNames := range overrides {
+		i := km.indexOf(name)
+		if i < 0 {
+			return &models.AppError{
+				Type:    models.ErrorValidation,
+				Message: fmt.Sprintf("Unknown action %q in keymap", name),
+			}
+		}
+		chords, err := parseChords(chordNames)
+		if err != nil {
+			return err
+		}
+		updated.bindings[i].Chords = chords
+	}
+
+	if conflicts := updated.Conflicts(); len(conflicts) > 0 {
+		return conflictError(conflicts)
+	}
+	km.bindings = updated.bindings
+	return nil
+}
+
+// LoadFile applies the overrides in the JSON file at path. A missing file
+// is not an error.
+func (km *Keymap) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return &models.AppError{
+			Type:    models.ErrorStorage,
+			Message: "Failed to read keymap file",
+			Err:     err,
+		}
+	}
+
+	var overrides map[string][]string
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return &models.AppError{
+			Type:    models.ErrorStorage,
+			Message: "Failed to parse keymap file",
+			Err:     err,
+		}
+	}
+	return km.Override(overrides)
+}
+
+// Conflicts returns the chords bound to more than one action, and those
+// bound to an action but reserved.
+func (km *Keymap) Conflicts() []Conflict {
+	actionsByChord := make(map[Chord][]string)
+	var order []Chord
+	for _, b := range km.bindings {
+		for _, c := range b.Chords {
+			if len(actionsByChord[c]) == 0 {
+				order = append(order, c)
+			}
+			actionsByChord[c] = append(actionsByChord[c], b.Action)
+		}
+	}
+
+	var conflicts []Conflict
+	for _, c := range order {
+		if use := km.reserved[c]; use != "" {
+			conflicts = append(conflicts, Conflict{Chord: c, Actions: actionsByChord[c], Reserved: use})
+		} else if len(actionsByChord[c]) > 1 {
+			conflicts = append(conflicts, Conflict{Chord: c, Actions: actionsByChord[c]})
+		}
+	}
+	return conflicts
+}
+
+// Triggered returns the action whose chord was just pressed. While typing
+// only actions that allow it fire, and only on chords that cannot be typed.
+func (km *Keymap) Triggered(state KeyState, typing bool) (string, bool) {
+	for _, b := range km.bindings {
+		if typing && !b.WhileTyping {
+			continue
+		}
+		for _, c := range b.Chords {
+			if typing && !c.IsGlobal() {
+				continue
+			}
+			if c.Matches(state) {
+				return b.Action, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Bindings returns the actions in their original order with their chords.
+func (km *Keymap) Bindings() []Binding {
+	bindings := make([]Binding, len(km.bindings))
+	copy(bindings, km.bindings)
+	return bindings
+}
+
+// Chords returns the chords bound to an action.
+func (km *Keymap) Chords(action string) []Chord {
+	if i := km.indexOf(action); i >= 0 {
+		return km.bindings[i].Chords
+	}
+	return nil
+}
+
+func (km *Keymap) indexOf(action string) int {
+	for i, b := range km.bindings {
+		if b.Action == action {
+			return i
+		}
+	}
+	return -1
+}
+
+func parseChords(names []string) ([]Chord, error) {
+	chords := make([]Chord, 0, len(names))
+	for _, name := range names {
+		c, err := ParseChord(name)
+		if err != nil {
+			return nil, &models.AppError{
+				Type:    models.ErrorValidation,
+				Message: "Invalid keymap chord",
+				Err:     err,
+			}
+		}
+		chords = append(chords, c)
+	}
+	return chords, nil
+}
+
+func conflictError(conflicts []Conflict) error {
+	descriptions := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		descriptions[i] = c.String()
+	}
+	sort.Strings(descriptions)
+	return &models.AppError{
+		Type:    models.ErrorValidation,
+		Message: "Conflicting key bindings: " + strings.Join(descriptions, "; "),
+	}
+}
@@ -0,0 +1,230 @@
+package keymap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// fakeState is a keyboard where the listed keys are held and the last one
+// was just pressed.
+type fakeState []ebiten.Key
+
+func (s fakeState) IsPressed(key ebiten.Key) bool {
+	for _, k := range s {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (s fakeState) IsJustPressed(key ebiten.Key) bool {
+	return len(s) > 0 && s[len(s)-1] == key
+}
+
+var testActions = []Action{
+	{Name: "new", Description: "New", Defaults: []string{"Ctrl+N"}},
+	{Name: "palette", Description: "Palette", Defaults: []string{"Ctrl+Shift+P"}},
+	{Name: "help", Description: "Help", Defaults: []string{"F1", "Ctrl+/"}, WhileTyping: true},
+	{Name: "next", Description: "Next", Defaults: []string{"J"}},
+}
+
+var testReserved = []Reserved{
+	{Chord: "Ctrl+C", Use: "copy"},
+	{Chord: "Delete", Use: "delete"},
+}
+
+func TestParseChord(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Chord
+	}{
+		{"Ctrl+N", Chord{Key: ebiten.KeyN, Ctrl: true}},
+		{"ctrl+shift+p", Chord{Key: ebiten.KeyP, Ctrl: true, Shift: true}},
+		{"Cmd+N", Chord{Key: ebiten.KeyN, Ctrl: true}},
+		{"Alt+1", Chord{Key: ebiten.Key1, Alt: true}},
+		{"F2", Chord{Key: ebiten.KeyF2}},
+		{"Ctrl+/", Chord{Key: ebiten.KeySlash, Ctrl: true}},
+		{"Ctrl++", Chord{Key: ebiten.KeyEqual, Ctrl: true}},
+		{" Ctrl + ArrowUp ", Chord{Key: ebiten.KeyArrowUp, Ctrl: true}},
+	}
+	for _, tt := range tests {
+		got, err := ParseChord(tt.input)
+		if err != nil {
+			t.Errorf("ParseChord(%q) returned error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseChord(%q): expected %+v, got %+v", tt.input, tt.want, got)
+		}
+	}
+
+	for _, input := range []string{"", "Ctrl+", "Hyper+N", "Ctrl+Nope", "Ctrl+Shift"} {
+		if _, err := ParseChord(input); err == nil {
+			t.Errorf("ParseChord(%q): expected an error", input)
+		}
+	}
+}
+
+func TestChordStringRoundTrip(t *testing.T) {
+	for _, input := range []string{"Ctrl+N", "Ctrl+Alt+Shift+P", "Alt+1", "F1", "Ctrl+/", "Ctrl+="} {
+		c, err := ParseChord(input)
+		if err != nil {
+			t.Fatalf("ParseChord(%q) returned error: %v", input, err)
+		}
+		if got := c.String(); got != input {
+			t.Errorf("Expected %q, got %q", input, got)
+		}
+	}
+}
+
+func TestTriggeredRequiresExactModifiers(t *testing.T) {
+	km, err := New(testActions, testReserved)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		state fakeState
+		want  string
+	}{
+		{"ctrl+n", fakeState{ebiten.KeyControl, ebiten.KeyN}, "new"},
+		{"meta counts as ctrl", fakeState{ebiten.KeyMeta, ebiten.KeyN}, "new"},
+		{"extra shift", fakeState{ebiten.KeyControl, ebiten.KeyShift, ebiten.KeyN}, ""},
+		{"ctrl+shift+p", fakeState{ebiten.KeyControl, ebiten.KeyShift, ebiten.KeyP}, "palette"},
+		{"missing shift", fakeState{ebiten.KeyControl, ebiten.KeyP}, ""},
+		{"second chord", fakeState{ebiten.KeyControl, ebiten.KeySlash}, "help"},
+		{"plain key", fakeState{ebiten.KeyJ}, "next"},
+	}
+	for _, tt := range tests {
+		got, _ := km.Triggered(tt.state, false)
+		if got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+
+	// Plain keys would be typed into a focused text field, and only actions
+	// that allow it fire while typing
+	if got, ok := km.Triggered(fakeState{ebiten.KeyJ}, true); ok {
+		t.Errorf("Expected J to be ignored while typing, got %q", got)
+	}
+	if got, ok := km.Triggered(fakeState{ebiten.KeyControl, ebiten.KeyN}, true); ok {
+		t.Errorf("Expected Ctrl+N to be ignored while typing, got %q", got)
+	}
+	if got, _ := km.Triggered(fakeState{ebiten.KeyF1}, true); got != "help" {
+		t.Errorf("Expected F1 to fire while typing, got %q", got)
+	}
+}
+
+func TestOverrideReplacesDefaults(t *testing.T) {
+	km, err := New(testActions, testReserved)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	if err := km.Override(map[string][]string{"new": {"Ctrl+T"}, "next": {}}); err != nil {
+		t.Fatalf("Override returned error: %v", err)
+	}
+
+	if got, _ := km.Triggered(fakeState{ebiten.KeyControl, ebiten.KeyT}, false); got != "new" {
+		t.Errorf("Expected Ctrl+T to trigger new, got %q", got)
+	}
+	if _, ok := km.Triggered(fakeState{ebiten.KeyControl, ebiten.KeyN}, false); ok {
+		t.Error("Expected the default Ctrl+N to be replaced")
+	}
+	if chords := km.Chords("next"); len(chords) != 0 {
+		t.Errorf("Expected next to be unbound, got %v", chords)
+	}
+}
+
+func TestOverrideRejectsConflictsAndUnknownActions(t *testing.T) {
+	km, err := New(testActions, testReserved)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	err = km.Override(map[string][]string{"new": {"F1"}})
+	if err == nil || !strings.Contains(err.Error(), "F1 is bound to new and help") {
+		t.Errorf("Expected a conflict error naming both actions, got %v", err)
+	}
+	if got, _ := km.Triggered(fakeState{ebiten.KeyControl, ebiten.KeyN}, false); got != "new" {
+		t.Error("Expected a rejected override to leave the bindings unchanged")
+	}
+
+	if err := km.Override(map[string][]string{"launch": {"Ctrl+L"}}); err == nil {
+		t.Error("Expected an error for an unknown action")
+	}
+	if err := km.Override(map[string][]string{"new": {"Ctrl+Nope"}}); err == nil {
+		t.Error("Expected an error for an invalid chord")
+	}
+}
+
+func TestNewRejectsConflictingDefaults(t *testing.T) {
+	_, err := New([]Action{
+		{Name: "a", Defaults: []string{"Ctrl+A"}},
+		{Name: "b", Defaults: []string{"ctrl+a"}},
+	}, nil)
+	if err == nil {
+		t.Error("Expected an error for conflicting defaults")
+	}
+
+	_, err = New([]Action{{Name: "a", Defaults: []string{"Ctrl+C"}}}, testReserved)
+	if err == nil {
+		t.Error("Expected an error for a default on a reserved chord")
+	}
+	if _, err := New(testActions, []Reserved{{Chord: "Ctrl+Nope"}}); err == nil {
+		t.Error("Expected an error for an invalid reserved chord")
+	}
+}
+
+func TestOverrideRejectsReservedChords(t *testing.T) {
+	km, err := New(testActions, testReserved)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	for _, chord := range []string{"Ctrl+C", "delete"} {
+		err := km.Override(map[string][]string{"new": {chord}})
+		if err == nil || !strings.Contains(err.Error(), "bound to new but is reserved") {
+			t.Errorf("Expected %s to be rejected as reserved, got %v", chord, err)
+		}
+	}
+	if err := km.Override(map[string][]string{"new": {"Ctrl+Shift+C"}}); err != nil {
+		t.Errorf("Expected a chord with other modifiers to be allowed, got %v", err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	km, err := New(testActions, testReserved)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	if err := km.LoadFile(filepath.Join(dir, "missing.json")); err != nil {
+		t.Errorf("Expected a missing file to be ignored, got %v", err)
+	}
+
+	path := filepath.Join(dir, "keymap.json")
+	if err := os.WriteFile(path, []byte(`{"palette": ["Ctrl+K"]}`), 0644); err != nil {
+		t.Fatalf("Failed to write keymap: %v", err)
+	}
+	if err := km.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile returned error: %v", err)
+	}
+	if got, _ := km.Triggered(fakeState{ebiten.KeyControl, ebiten.KeyK}, false); got != "palette" {
+		t.Errorf("Expected Ctrl+K to trigger palette, got %q", got)
+	}
+
+	if err := os.WriteFile(path, []byte(`{not json`), 0644); err != nil {
+		t.Fatalf("Failed to write keymap: %v", err)
+	}
+	if err := km.LoadFile(path); err == nil {
+		t.Error("Expected an error for a malformed file")
+	}
+}
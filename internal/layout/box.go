@@ -0,0 +1,160 @@
+package layout
+
+import "image"
+
+// Align places children across the main axis of a box.
+type Align int
+
+const (
+	AlignStretch Align = iota
+	AlignStart
+	AlignCenter
+	AlignEnd
+)
+
+// Child is a node in a box together with how much of the main axis it
+// gets: a fixed Size, a Flex share of the space the other children leave,
+// or, when both are zero, its measured size.
+type Child struct {
+	Node Node
+	Size int
+	Flex int
+}
+
+// Fixed returns a child that is size pixels long on the main axis.
+func Fixed(size int, node Node) Child {
+	return Child{Node: node, Size: size}
+}
+
+// Flex returns a child that takes weight shares of the free space.
+func Flex(weight int, node Node) Child {
+	return Child{Node: node, Flex: weight}
+}
+
+// Auto returns a child that is as long as it measures.
+func Auto(node Node) Child {
+	return Child{Node: node}
+}
+
+// Spacer returns an empty child that takes one share of the free space,
+// pushing its neighbours apart.
+func Spacer() Child {
+	return Child{Flex: 1}
+}
+
+// Box lays its children out one after another, left to right in a row or
+// top to bottom in a column.
+type Box struct {
+	Vertical bool
+	Padding  Insets
+	Gap      int
+	Align    Align
+	Children []Child
+}
+
+func Row(children ...Child) *Box {
+	return &Box{Children: children}
+}
+
+func Column(children ...Child) *Box {
+	return &Box{Vertical: true, Children: children}
+}
+
+func (b *Box) Measure() image.Point {
+	var main, cross int
+	for i, c := range b.Children {
+		if i > 0 {
+			main += b.Gap
+		}
+		size := b.measure(c)
+		cross = max(cross, b.cross(size))
+		if c.Size > 0 {
+			main += c.Size
+		} else {
+			main += b.main(size)
+		}
+	}
+	return b.point(main, cross).Add(b.Padding.size())
+}
+
+func (b *Box) Arrange(bounds image.Rectangle) {
+	inner := b.Padding.Shrink(bounds)
+	mainLen, crossLen := b.main(inner.Size()), b.cross(inner.Size())
+
+	// Give fixed and measured children their size, and split the rest
+	// between the flexible ones
+	sizes := make([]int, len(b.Children))
+	free := mainLen - b.Gap*max(len(b.Children)-1, 0)
+	totalFlex := 0
+	for i, c := range b.Children {
+		switch {
+		case c.Flex > 0:
+			totalFlex += c.Flex
+		case c.Size > 0:
+			sizes[i] = c.Size
+		default:
+			sizes[i] = b.main(b.measure(c))
+		}
+		free -= sizes[i]
+	}
+	free = max(free, 0)
+	remaining, flexLeft := free, totalFlex
+	for i, c := range b.Children {
+		if c.Flex <= 0 {
+			continue
+		}
+		// The last flexible child takes the rounding remainder
+		share := remaining * c.Flex / flexLeft
+		sizes[i] = share
+		remaining -= share
+		flexLeft -= c.Flex
+	}
+
+	pos := 0
+	for i, c := range b.Children {
+		crossSize, crossPos := crossLen, 0
+		if b.Align != AlignStretch {
+			crossSize = min(b.cross(b.measure(c)), crossLen)
+			switch b.Align {
+			case AlignCenter:
+				crossPos = (crossLen - crossSize) / 2
+			case AlignEnd:
+				crossPos = crossLen - crossSize
+			}
+		}
+		if c.Node != nil {
+			origin := inner.Min.Add(b.point(pos, crossPos))
+			c.Node.Arrange(image.Rectangle{Min: origin, Max: origin.Add(b.point(sizes[i], crossSize))})
+		}
+		pos += sizes[i] + b.Gap
+	}
+}
+
+func (b *Box) measure(c Child) image.Point {
+	if c.Node == nil {
+		return image.Point{}
+	}
+	return c.Node.Measure()
+}
+
+func (b *Box) main(p image.Point) int {
+	if b.Vertical {
+		return p.Y
+	}
+	return p.X
+}
+
+func (b *Box) cross(p image.Point) int {
+	if b.Vertical {
+		return p.X
+	}
+	return p.Y
+}
+
+// point builds a point from main and cross axis values.
+func (b *Box) point(main, cross int) image.Point {
+	if b.Vertical {
+		return image.Pt(cross, main)
+	}
+	return image.Pt(main, cross)
+}
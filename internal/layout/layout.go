@@ -0,0 +1,107 @@
+// Package layout computes widget bounds from a tree of rows, columns and
+// stacks, so that the UI follows the window when it is resized. A tree is
+// built once and arranged again whenever the window size changes; leaves
+// hand their bounds to the widgets they wrap.
+package layout
+
+import "image"
+
+// Node is an element of a layout tree.
+type Node interface {
+	// Measure returns the size the node would like to have.
+	Measure() image.Point
+	// Arrange places the node and its children in bounds.
+	Arrange(bounds image.Rectangle)
+}
+
+// Insets is space kept free on each side of a rectangle.
+type Insets struct {
+	Top, Right, Bottom, Left int
+}
+
+// Uniform returns insets of n on every side.
+func Uniform(n int) Insets {
+	return Insets{Top: n, Right: n, Bottom: n, Left: n}
+}
+
+// Shrink returns r without the insets. The result is never inverted.
+func (in Insets) Shrink(r image.Rectangle) image.Rectangle {
+	// image.Rect would swap the corners of an inverted rectangle, so build
+	// it by hand and clamp instead
+	minX, minY := r.Min.X+in.Left, r.Min.Y+in.Top
+	return image.Rectangle{
+		Min: image.Pt(minX, minY),
+		Max: image.Pt(max(r.Max.X-in.Right, minX), max(r.Max.Y-in.Bottom, minY)),
+	}
+}
+
+func (in Insets) size() image.Point {
+	return image.Pt(in.Left+in.Right, in.Top+in.Bottom)
+}
+
+type leaf struct {
+	size image.Point
+	set  func(image.Rectangle)
+}
+
+// Leaf returns a node of the given preferred size that passes its bounds
+// to set, typically a widget's SetBounds.
+func Leaf(width, height int, set func(image.Rectangle)) Node {
+	return &leaf{size: image.Pt(width, height), set: set}
+}
+
+func (l *leaf) Measure() image.Point { return l.size }
+
+func (l *leaf) Arrange(bounds image.Rectangle) {
+	if l.set != nil {
+		l.set(bounds)
+	}
+}
+
+// Rect returns a node that takes whatever space it is given and stores its
+// bounds in dst, for areas that are drawn directly rather than by a widget.
+func Rect(dst *image.Rectangle) Node {
+	return Leaf(0, 0, func(r image.Rectangle) { *dst = r })
+}
+
+type padded struct {
+	insets Insets
+	node   Node
+}
+
+// Pad surrounds node with insets.
+func Pad(insets Insets, node Node) Node {
+	return &padded{insets: insets, node: node}
+}
+
+func (p *padded) Measure() image.Point {
+	return p.node.Measure().Add(p.insets.size())
+}
+
+func (p *padded) Arrange(bounds image.Rectangle) {
+	p.node.Arrange(p.insets.Shrink(bounds))
+}
+
+type conditional struct {
+	visible func() bool
+	node    Node
+}
+
+// When shows node only while visible reports true. A hidden node measures
+// zero and is not arranged, so boxes close the gap it leaves.
+func When(visible func() bool, node Node) Node {
+	return &conditional{visible: visible, node: node}
+}
+
+func (c *conditional) Measure() image.Point {
+	if !c.visible() {
+		return image.Point{}
+	}
+	return c.node.Measure()
+}
+
+func (c *conditional) Arrange(bounds image.Rectangle) {
+	if c.visible() {
+		c.node.Arrange(bounds)
+	}
+}
@@ -0,0 +1,161 @@
+package layout
+
+import (
+	"image"
+	"testing"
+)
+
+// box records the bounds it is arranged in.
+func box(width, height int, dst *image.Rectangle) Node {
+	return Leaf(width, height, func(r image.Rectangle) { *dst = r })
+}
+
+func TestRowFixedFlexAndGap(t *testing.T) {
+	var input, add, search image.Rectangle
+	row := Row(
+		Flex(1, box(0, 35, &input)),
+		Fixed(100, box(0, 35, &add)),
+		Fixed(120, box(0, 35, &search)),
+	)
+	row.Padding = Insets{Top: 20, Left: 20, Right: 20}
+	row.Gap = 20
+	row.Align = AlignStart
+
+	row.Arrange(image.Rect(0, 0, 800, 80))
+	if want := image.Rect(20, 20, 520, 55); input != want {
+		t.Errorf("Expected input at %v, got %v", want, input)
+	}
+	if want := image.Rect(540, 20, 640, 55); add != want {
+		t.Errorf("Expected add at %v, got %v", want, add)
+	}
+	if want := image.Rect(660, 20, 780, 55); search != want {
+		t.Errorf("Expected search at %v, got %v", want, search)
+	}
+
+	// Only the flexible child changes size when the window grows
+	row.Arrange(image.Rect(0, 0, 1000, 80))
+	if input.Dx() != 700 || add.Dx() != 100 || search.Max.X != 980 {
+		t.Errorf("Expected the input to absorb the extra width, got %v %v %v", input, add, search)
+	}
+}
+
+func TestColumnFlexSharesAndShrink(t *testing.T) {
+	var header, a, b, footer image.Rectangle
+	col := Column(
+		Fixed(80, Rect(&header)),
+		Flex(1, Rect(&a)),
+		Flex(2, Rect(&b)),
+		Fixed(60, Rect(&footer)),
+	)
+
+	col.Arrange(image.Rect(0, 0, 400, 440))
+	if a.Dy() != 100 || b.Dy() != 200 {
+		t.Errorf("Expected flex heights 100 and 200, got %d and %d", a.Dy(), b.Dy())
+	}
+	if footer != image.Rect(0, 380, 400, 440) {
+		t.Errorf("Expected footer at the bottom, got %v", footer)
+	}
+
+	// Flexible children shrink to nothing before fixed ones overlap
+	col.Arrange(image.Rect(0, 0, 400, 100))
+	if a.Dy() != 0 || b.Dy() != 0 {
+		t.Errorf("Expected flexible children to collapse, got %v %v", a, b)
+	}
+}
+
+func TestBoxAlignAndMeasure(t *testing.T) {
+	var small image.Rectangle
+	row := Row(Auto(box(50, 10, &small)), Spacer(), Fixed(30, box(30, 40, new(image.Rectangle))))
+	row.Padding = Uniform(5)
+	row.Gap = 4
+
+	if got, want := row.Measure(), image.Pt(5+50+4+0+4+30+5, 5+40+5); got != want {
+		t.Errorf("Expected measure %v, got %v", want, got)
+	}
+
+	tests := []struct {
+		align Align
+		want  image.Rectangle
+	}{
+		{AlignStretch, image.Rect(5, 5, 55, 45)},
+		{AlignStart, image.Rect(5, 5, 55, 15)},
+		{AlignCenter, image.Rect(5, 20, 55, 30)},
+		{AlignEnd, image.Rect(5, 35, 55, 45)},
+	}
+	for _, tt := range tests {
+		row.Align = tt.align
+		row.Arrange(image.Rect(0, 0, 200, 50))
+		if small != tt.want {
+			t.Errorf("Align %d: expected %v, got %v", tt.align, tt.want, small)
+		}
+	}
+}
+
+func TestStackAnchors(t *testing.T) {
+	tests := []struct {
+		anchor Anchor
+		want   image.Rectangle
+	}{
+		{Fill, image.Rect(10, 10, 190, 90)},
+		{TopLeft, image.Rect(10, 10, 50, 30)},
+		{Top, image.Rect(80, 10, 120, 30)},
+		{Center, image.Rect(80, 40, 120, 60)},
+		{Right, image.Rect(150, 40, 190, 60)},
+		{BottomRight, image.Rect(150, 70, 190, 90)},
+		{BottomLeft, image.Rect(10, 70, 50, 90)},
+	}
+
+	for _, tt := range tests {
+		var got image.Rectangle
+		stack := NewStack(Layer{Node: box(40, 20, &got), Anchor: tt.anchor, Margin: Uniform(10)})
+		stack.Arrange(image.Rect(0, 0, 200, 100))
+		if got != tt.want {
+			t.Errorf("Anchor %d: expected %v, got %v", tt.anchor, tt.want, got)
+		}
+	}
+
+	// Anchored layers never grow past the stack
+	var got image.Rectangle
+	NewStack(Anchored(Center, box(500, 500, &got))).Arrange(image.Rect(0, 0, 100, 50))
+	if got != image.Rect(0, 0, 100, 50) {
+		t.Errorf("Expected the layer to be clamped, got %v", got)
+	}
+}
+
+func TestPad(t *testing.T) {
+	var got image.Rectangle
+	node := Pad(Insets{Top: 1, Right: 2, Bottom: 3, Left: 4}, box(10, 10, &got))
+	if size := node.Measure(); size != image.Pt(16, 14) {
+		t.Errorf("Expected padded size (16,14), got %v", size)
+	}
+	node.Arrange(image.Rect(0, 0, 5, 3))
+	if !got.Empty() || got.Min != image.Pt(4, 1) {
+		t.Errorf("Expected an empty rectangle at (4,1), got %v", got)
+	}
+}
+
+func TestWhen(t *testing.T) {
+	var list, bar image.Rectangle
+	shown := false
+	column := Column(
+		Flex(1, box(0, 0, &list)),
+		Auto(When(func() bool { return shown }, box(0, 40, &bar))),
+	)
+
+	column.Arrange(image.Rect(0, 0, 100, 300))
+	if want := image.Rect(0, 0, 100, 300); list != want {
+		t.Errorf("Expected a hidden node to leave the space to the list, got %v", list)
+	}
+	if bar != (image.Rectangle{}) {
+		t.Errorf("Expected a hidden node not to be arranged, got %v", bar)
+	}
+
+	shown = true
+	column.Arrange(image.Rect(0, 0, 100, 300))
+	if want := image.Rect(0, 0, 100, 260); list != want {
+		t.Errorf("Expected list at %v, got %v", want, list)
+	}
+	if want := image.Rect(0, 260, 100, 300); bar != want {
+		t.Errorf("Expected bar at %v, got %v", want, bar)
+	}
+}
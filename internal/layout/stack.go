@@ -0,0 +1,85 @@
+package layout
+
+import "image"
+
+// Anchor is the edge or corner of a stack that a layer sticks to.
+type Anchor int
+
+const (
+	Fill Anchor = iota
+	TopLeft
+	Top
+	TopRight
+	Left
+	Center
+	Right
+	BottomLeft
+	Bottom
+	BottomRight
+)
+
+// Layer is a node in a stack. Anchored layers keep their measured size
+// and are placed against the anchor, Margin away from the stack's edges.
+type Layer struct {
+	Node   Node
+	Anchor Anchor
+	Margin Insets
+}
+
+// Anchored returns a layer placed at anchor.
+func Anchored(anchor Anchor, node Node) Layer {
+	return Layer{Node: node, Anchor: anchor}
+}
+
+// Stack places its layers on top of each other in the same area.
+type Stack struct {
+	Padding Insets
+	Layers  []Layer
+}
+
+func NewStack(layers ...Layer) *Stack {
+	return &Stack{Layers: layers}
+}
+
+func (s *Stack) Measure() image.Point {
+	var size image.Point
+	for _, l := range s.Layers {
+		m := l.Node.Measure().Add(l.Margin.size())
+		size.X = max(size.X, m.X)
+		size.Y = max(size.Y, m.Y)
+	}
+	return size.Add(s.Padding.size())
+}
+
+func (s *Stack) Arrange(bounds image.Rectangle) {
+	inner := s.Padding.Shrink(bounds)
+	for _, l := range s.Layers {
+		area := l.Margin.Shrink(inner)
+		if l.Anchor == Fill {
+			l.Node.Arrange(area)
+			continue
+		}
+
+		size := l.Node.Measure()
+		size.X = min(size.X, area.Dx())
+		size.Y = min(size.Y, area.Dy())
+		free := area.Size().Sub(size)
+
+		var offset image.Point
+		switch l.Anchor {
+		case Top, Center, Bottom:
+			offset.X = free.X / 2
+		case TopRight, Right, BottomRight:
+			offset.X = free.X
+		}
+		switch l.Anchor {
+		case Left, Center, Right:
+			offset.Y = free.Y / 2
+		case BottomLeft, Bottom, BottomRight:
+			offset.Y = free.Y
+		}
+
+		origin := area.Min.Add(offset)
+		l.Node.Arrange(image.Rectangle{Min: origin, Max: origin.Add(size)})
+	}
+}
@@ -0,0 +1,271 @@
+// Package markdown parses the small, safe subset of Markdown used in todo
+// text and notes: bold, italic, inline code, links and bullet lists. Raw
+// HTML is never interpreted and only http, https and mailto links are kept.
+package markdown
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// Style is a set of inline text styles.
+type Style uint8
+
+const (
+	Bold Style = 1 << iota
+	Italic
+	Code
+	Link
+)
+
+func (s Style) Has(style Style) bool {
+	return s&style != 0
+}
+
+// Run is a span of text sharing one style. URL is set for links.
+type Run struct {
+	Text  string
+	Style Style
+	URL   string
+}
+
+type BlockKind int
+
+const (
+	Paragraph BlockKind = iota
+	Bullet
+)
+
+// Block is one line of a document: a paragraph or a bullet list item.
+type Block struct {
+	Kind BlockKind
+	Runs []Run
+}
+
+// Parse splits src into lines and parses each one as a block. Blank lines
+// are kept as empty paragraphs so that spacing is preserved.
+func Parse(src string) []Block {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	lines := strings.Split(src, "\n")
+	blocks := make([]Block, 0, len(lines))
+	for _, line := range lines {
+		kind := Paragraph
+		trimmed := strings.TrimLeft(line, " \t")
+		if len(trimmed) >= 2 && strings.ContainsRune("-*+", rune(trimmed[0])) && trimmed[1] == ' ' {
+			kind = Bullet
+			line = strings.TrimLeft(trimmed[2:], " ")
+		}
+		blocks = append(blocks, Block{Kind: kind, Runs: ParseInline(line)})
+	}
+	return blocks
+}
+
+// ParseInline parses the inline styles of a single line.
+func ParseInline(src string) []Run {
+	p := &inlineParser{}
+	p.parse([]rune(src), 0)
+	return p.runs
+}
+
+// IsSafeURL reports whether u is an absolute http, https or mailto URL.
+func IsSafeURL(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https":
+		return parsed.Host != ""
+	case "mailto":
+		return parsed.Opaque != ""
+	}
+	return false
+}
+
+// PlainText returns the text of runs without any markup.
+func PlainText(runs []Run) string {
+	var b strings.Builder
+	for _, r := range runs {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+type inlineParser struct {
+	runs []Run
+	buf  []rune
+}
+
+func (p *inlineParser) parse(src []rune, style Style) {
+	for i := 0; i < len(src); i++ {
+		r := src[i]
+		switch {
+		case r == '\\' && i+1 < len(src) && unicode.IsPunct(src[i+1]):
+			i++
+			p.buf = append(p.buf, src[i])
+
+		case r == '`':
+			end := indexRune(src, '`', i+1)
+			if end < 0 {
+				p.buf = append(p.buf, r)
+				continue
+			}
+			p.flush(style)
+			p.emit(string(src[i+1:end]), style|Code, "")
+			i = end
+
+		case (r == '*' || r == '_') && i+1 < len(src) && src[i+1] == r:
+			delim := []rune{r, r}
+			end := indexDelimiter(src, delim, i+2)
+			if end <= i+2 || !canOpen(src, i, 2) {
+				p.buf = append(p.buf, r, r)
+				i++
+				continue
+			}
+			p.flush(style)
+			p.parse(src[i+2:end], style|Bold)
+			i = end + 1
+
+		case r == '*' || r == '_':
+			end := indexDelimiter(src, []rune{r}, i+1)
+			if end <= i+1 || !canOpen(src, i, 1) {
+				p.buf = append(p.buf, r)
+				continue
+			}
+			p.flush(style)
+			p.parse(src[i+1:end], style|Italic)
+			i = end
+
+		case r == '[':
+			label, target, next, ok := parseLink(src, i)
+			if !ok {
+				p.buf = append(p.buf, r)
+				continue
+			}
+			p.flush(style)
+			if IsSafeURL(target) {
+				p.emit(label, style|Link, target)
+			} else {
+				p.emit(label, style, "")
+			}
+			i = next - 1
+
+		case (r == 'h' || r == 'm') && (i == 0 || !isWordRune(src[i-1])):
+			end := autolinkEnd(src, i)
+			if end < 0 {
+				p.buf = append(p.buf, r)
+				continue
+			}
+			p.flush(style)
+			link := string(src[i:end])
+			p.emit(link, style|Link, link)
+			i = end - 1
+
+		default:
+			p.buf = append(p.buf, r)
+		}
+	}
+	p.flush(style)
+}
+
+// canOpen reports whether the delimiter run of the given width at src[i] can
+// open emphasis: it must be followed by text, and underscores inside words
+// stay literal so that snake_case is not mangled.
+func canOpen(src []rune, i, width int) bool {
+	if i+width >= len(src) || unicode.IsSpace(src[i+width]) {
+		return false
+	}
+	return src[i] != '_' || i == 0 || !isWordRune(src[i-1])
+}
+
+func (p *inlineParser) flush(style Style) {
+	if len(p.buf) == 0 {
+		return
+	}
+	p.emit(string(p.buf), style, "")
+	p.buf = p.buf[:0]
+}
+
+// emit appends a run, merging it into the previous run when the style matches.
+func (p *inlineParser) emit(text string, style Style, url string) {
+	if text == "" {
+		return
+	}
+	if n := len(p.runs); n > 0 && p.runs[n-1].Style == style && p.runs[n-1].URL == url && url == "" {
+		p.runs[n-1].Text += text
+		return
+	}
+	p.runs = append(p.runs, Run{Text: text, Style: style, URL: url})
+}
+
+// parseLink parses [label](target) starting at src[i] == '['.
+func parseLink(src []rune, i int) (label, target string, next int, ok bool) {
+	closeLabel := indexRune(src, ']', i+1)
+	if closeLabel < 0 || closeLabel+1 >= len(src) || src[closeLabel+1] != '(' {
+		return "", "", 0, false
+	}
+	closeTarget := indexRune(src, ')', closeLabel+2)
+	if closeTarget < 0 {
+		return "", "", 0, false
+	}
+	label = string(src[i+1 : closeLabel])
+	target = strings.TrimSpace(string(src[closeLabel+2 : closeTarget]))
+	if label == "" {
+		label = target
+	}
+	return label, target, closeTarget + 1, true
+}
+
+// autolinkEnd returns the end of a bare URL starting at src[i], or -1.
+func autolinkEnd(src []rune, i int) int {
+	rest := string(src[i:])
+	if !strings.HasPrefix(rest, "http://") && !strings.HasPrefix(rest, "https://") && !strings.HasPrefix(rest, "mailto:") {
+		return -1
+	}
+	end := i
+	for end < len(src) && !unicode.IsSpace(src[end]) {
+		end++
+	}
+	// Trailing punctuation usually belongs to the sentence, not the URL.
+	for end > i && strings.ContainsRune(".,;:!?)'\"", src[end-1]) {
+		end--
+	}
+	if !IsSafeURL(string(src[i:end])) {
+		return -1
+	}
+	return end
+}
+
+func indexRune(src []rune, r rune, from int) int {
+	for i := from; i < len(src); i++ {
+		if src[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexDelimiter finds the next unescaped occurrence of delim at or after from.
+func indexDelimiter(src []rune, delim []rune, from int) int {
+	for i := from; i+len(delim) <= len(src); i++ {
+		if src[i] == '\\' {
+			i++
+			continue
+		}
+		if string(src[i:i+len(delim)]) != string(delim) || unicode.IsSpace(src[i-1]) {
+			continue
+		}
+		// A single delimiter must not be half of a double one.
+		if len(delim) == 1 && i+1 < len(src) && src[i+1] == delim[0] {
+			i++
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,91 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseInline(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []Run
+	}{
+		{"plain", "buy milk", []Run{{Text: "buy milk"}}},
+		{"bold", "buy **oat** milk", []Run{{Text: "buy "}, {Text: "oat", Style: Bold}, {Text: " milk"}}},
+		{"italic star", "*soon*", []Run{{Text: "soon", Style: Italic}}},
+		{"italic underscore", "_soon_", []Run{{Text: "soon", Style: Italic}}},
+		{"nested", "**a *b* c**", []Run{{Text: "a ", Style: Bold}, {Text: "b", Style: Bold | Italic}, {Text: " c", Style: Bold}}},
+		{"code", "run `go test`", []Run{{Text: "run "}, {Text: "go test", Style: Code}}},
+		{"code keeps markup", "`**x**`", []Run{{Text: "**x**", Style: Code}}},
+		{"snake case", "fix some_var_name", []Run{{Text: "fix some_var_name"}}},
+		{"spaced stars", "2 * 3 * 4", []Run{{Text: "2 * 3 * 4"}}},
+		{"unclosed", "**open", []Run{{Text: "**open"}}},
+		{"escaped", `\*literal\*`, []Run{{Text: "*literal*"}}},
+		{
+			"link",
+			"see [docs](https://example.com/a)",
+			[]Run{{Text: "see "}, {Text: "docs", Style: Link, URL: "https://example.com/a"}},
+		},
+		{
+			"unsafe link",
+			"[click](javascript:alert(1))",
+			[]Run{{Text: "click)"}},
+		},
+		{
+			"autolink",
+			"ticket https://example.com/T-1.",
+			[]Run{{Text: "ticket "}, {Text: "https://example.com/T-1", Style: Link, URL: "https://example.com/T-1"}, {Text: "."}},
+		},
+		{"html is literal", "<b>x</b>", []Run{{Text: "<b>x</b>"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseInline(tt.src)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseInline(%q):\n got %+v\nwant %+v", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBlocks(t *testing.T) {
+	blocks := Parse("Shopping:\n- milk\n  * **eggs**\n\n+not a bullet")
+
+	wantKinds := []BlockKind{Paragraph, Bullet, Bullet, Paragraph, Paragraph}
+	if len(blocks) != len(wantKinds) {
+		t.Fatalf("Expected %d blocks, got %d", len(wantKinds), len(blocks))
+	}
+	for i, kind := range wantKinds {
+		if blocks[i].Kind != kind {
+			t.Errorf("block %d: expected kind %v, got %v", i, kind, blocks[i].Kind)
+		}
+	}
+
+	if got := blocks[2].Runs; !reflect.DeepEqual(got, []Run{{Text: "eggs", Style: Bold}}) {
+		t.Errorf("Expected bold bullet text, got %+v", got)
+	}
+	if len(blocks[3].Runs) != 0 {
+		t.Errorf("Expected blank line to have no runs, got %+v", blocks[3].Runs)
+	}
+}
+
+func TestIsSafeURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://example.com":       true,
+		"http://example.com/path":   true,
+		"mailto:team@example.com":   true,
+		"javascript:alert(1)":       false,
+		"file:///etc/passwd":        false,
+		"example.com":               false,
+		"https://":                  false,
+		"HTTPS://EXAMPLE.COM/UPPER": true,
+	}
+
+	for u, want := range tests {
+		if got := IsSafeURL(u); got != want {
+			t.Errorf("IsSafeURL(%q): expected %v, got %v", u, want, got)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// Status is the stage of a todo's work, shown as its column on the board.
+// Todos saved before statuses existed have none and are placed by
+// Completed.
+type Status string
+
+const (
+	StatusTodo       Status = "todo"
+	StatusInProgress Status = "in_progress"
+	StatusDone       Status = "done"
+)
+
+// BoardColumn is a column of the board. Moving a todo into a column gives
+// it the column's status and completes or reopens it to match Done, so
+// the Active and Completed filters follow the board.
+type BoardColumn struct {
+	Status Status `json:"status"`
+	Title  string `json:"title"`
+	Done   bool   `json:"done,omitempty"`
+}
+
+// DefaultBoardColumns are used unless the settings define columns.
+var DefaultBoardColumns = []BoardColumn{
+	{Status: StatusTodo, Title: "To Do"},
+	{Status: StatusInProgress, Title: "In Progress"},
+	{Status: StatusDone, Title: "Done", Done: true},
+}
+
+// ValidateBoardColumns checks that every column has a status and a title,
+// that no status is repeated, and that there is a column for open todos
+// and one for completed todos.
+func ValidateBoardColumns(columns []BoardColumn) error {
+	seen := make(map[Status]bool, len(columns))
+	var open, done bool
+	for _, column := range columns {
+		if column.Status == "" || column.Title == "" {
+			return &AppError{Type: ErrorValidation, Message: "Board columns need a status and a title"}
+		}
+		if seen[column.Status] {
+			return &AppError{Type: ErrorValidation, Message: "Board column status " + string(column.Status) + " is used twice"}
+		}
+		seen[column.Status] = true
+		if column.Done {
+			done = true
+		} else {
+			open = true
+		}
+	}
+	if !open || !done {
+		return &AppError{Type: ErrorValidation, Message: "The board needs a column for open todos and one for completed todos"}
+	}
+	return nil
+}
+
+// ColumnOf returns the index of the column a todo belongs in: the column
+// with its status, or else the first column matching whether it is
+// completed.
+func ColumnOf(columns []BoardColumn, todo *Todo) int {
+	for i, column := range columns {
+		if todo.Status != "" && column.Status == todo.Status && column.Done == todo.Completed {
+			return i
+		}
+	}
+	for i, column := range columns {
+		if column.Done == todo.Completed {
+			return i
+		}
+	}
+	return 0
+}
+
+// SetStatus moves the todo to a status, completing or reopening it to
+// match done.
+func (t *Todo) SetStatus(status Status, done bool, now time.Time) {
+	if done != t.Completed {
+		t.Toggle(now)
+	}
+	if status == t.Status {
+		return
+	}
+	t.record(now, FieldStatus, string(t.Status), string(status))
+	t.Status = status
+}
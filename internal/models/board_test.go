@@ -0,0 +1,77 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateBoardColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []BoardColumn
+		ok      bool
+	}{
+		{"defaults", DefaultBoardColumns, true},
+		{"custom", []BoardColumn{{Status: "backlog", Title: "Backlog"}, {Status: "shipped", Title: "Shipped", Done: true}}, true},
+		{"no done column", []BoardColumn{{Status: "todo", Title: "To Do"}}, false},
+		{"no open column", []BoardColumn{{Status: "done", Title: "Done", Done: true}}, false},
+		{"repeated status", append([]BoardColumn{{Status: StatusTodo, Title: "Again"}}, DefaultBoardColumns...), false},
+		{"missing title", []BoardColumn{{Status: "todo"}, {Status: "done", Title: "Done", Done: true}}, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateBoardColumns(tt.columns); (err == nil) != tt.ok {
+			t.Errorf("%s: expected ok=%v, got %v", tt.name, tt.ok, err)
+		}
+	}
+}
+
+func TestColumnOf(t *testing.T) {
+	columns := DefaultBoardColumns
+	tests := []struct {
+		name string
+		todo Todo
+		want int
+	}{
+		{"open without status", Todo{}, 0},
+		{"completed without status", Todo{Completed: true}, 2},
+		{"in progress", Todo{Status: StatusInProgress}, 1},
+		{"unknown status", Todo{Status: "review"}, 0},
+		// A status that disagrees with Completed loses
+		{"completed in progress", Todo{Status: StatusInProgress, Completed: true}, 2},
+	}
+	for _, tt := range tests {
+		if got := ColumnOf(columns, &tt.todo); got != tt.want {
+			t.Errorf("%s: expected column %d, got %d", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestTodoSetStatus(t *testing.T) {
+	clock, ids := newTestClock()
+	todo := NewTodo("Test", clock, ids)
+
+	todo.SetStatus(StatusInProgress, false, clock.Now())
+	if todo.Status != StatusInProgress || todo.Completed {
+		t.Errorf("Expected an open todo in progress, got %q completed=%v", todo.Status, todo.Completed)
+	}
+
+	completed := clock.Advance(time.Minute)
+	todo.SetStatus(StatusDone, true, clock.Now())
+	if todo.Status != StatusDone || !todo.Completed || todo.CompletedAt == nil || !todo.CompletedAt.Equal(completed) {
+		t.Errorf("Expected a completed todo, got %q completed=%v", todo.Status, todo.Completed)
+	}
+
+	// Reopening with the checkbox sends it back to the first open column
+	todo.Toggle(clock.Advance(time.Minute))
+	if todo.Status != "" || ColumnOf(DefaultBoardColumns, &todo) != 0 {
+		t.Errorf("Expected toggling to clear the status, got %q", todo.Status)
+	}
+
+	last := todo.History[len(todo.History)-1]
+	if last.Field != FieldCompleted {
+		t.Errorf("Expected the reopening in the history, got %+v", last)
+	}
+	if n := len(todo.History); n != 4 {
+		t.Errorf("Expected 4 changes (status, completed, status, completed), got %d", n)
+	}
+}
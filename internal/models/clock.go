@@ -0,0 +1,163 @@
+package models
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Clock tells the current time. The app runs on SystemClock, and tests use
+// a FakeClock to get exact times.
+type Clock interface {
+	Now() time.Time
+}
+
+// TimerClock is a Clock that can also run functions after a delay, for
+// work that waits, such as saving in the background.
+type TimerClock interface {
+	Clock
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a function waiting to run. Stop and Reset work like those of
+// time.Timer.
+type Timer interface {
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// IDGenerator makes the IDs of new todos. The app uses UUIDs, and tests
+// use SequentialIDs to get predictable ones.
+type IDGenerator interface {
+	NewID() string
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
+
+// SystemClock is the clock of the machine.
+var SystemClock TimerClock = systemClock{}
+
+type uuidGenerator struct{}
+
+func (uuidGenerator) NewID() string { return uuid.New().String() }
+
+// UUIDs makes random UUIDs.
+var UUIDs IDGenerator = uuidGenerator{}
+
+// FakeClock is a Clock that stands still until it is set or advanced. Its
+// timers run when it is set or advanced to their time, on the goroutine
+// that moves it, in the order they are due.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer // The timers waiting to run
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	c.now = now
+	c.mu.Unlock()
+	c.runTimers()
+}
+
+// Advance moves the clock forward by d and returns the new time.
+func (c *FakeClock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	now := c.now
+	c.mu.Unlock()
+	c.runTimers()
+	return now
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	t := &fakeTimer{clock: c, f: f}
+	t.Reset(d)
+	return t
+}
+
+// runTimers runs the timers that are due, without holding the lock so
+// that they can use the clock.
+func (c *FakeClock) runTimers() {
+	for {
+		c.mu.Lock()
+		var next *fakeTimer
+		for _, t := range c.timers {
+			if !t.at.After(c.now) && (next == nil || t.at.Before(next.at)) {
+				next = t
+			}
+		}
+		if next == nil {
+			c.mu.Unlock()
+			return
+		}
+		c.stop(next)
+		c.mu.Unlock()
+		next.f()
+	}
+}
+
+// stop removes t from the waiting timers, reporting whether it was
+// waiting. The caller holds the lock.
+func (c *FakeClock) stop(t *fakeTimer) bool {
+	i := slices.Index(c.timers, t)
+	if i < 0 {
+		return false
+	}
+	c.timers = slices.Delete(c.timers, i, i+1)
+	return true
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	at    time.Time
+	f     func()
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.stop(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	waiting := c.stop(t)
+	t.at = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	return waiting
+}
+
+// SequentialIDs makes the IDs Prefix+"1", Prefix+"2" and so on.
+type SequentialIDs struct {
+	Prefix string
+
+	mu   sync.Mutex
+	next int
+}
+
+func (s *SequentialIDs) NewID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next++
+	return s.Prefix + strconv.Itoa(s.next)
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFakeClockTimers(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	var ran []string
+	run := func(name string) func() {
+		return func() { ran = append(ran, name) }
+	}
+
+	late := clock.AfterFunc(20*time.Millisecond, run("late"))
+	clock.AfterFunc(10*time.Millisecond, run("early"))
+	stopped := clock.AfterFunc(5*time.Millisecond, run("stopped"))
+	if !stopped.Stop() || stopped.Stop() {
+		t.Error("Expected Stop to report only the first stop")
+	}
+
+	clock.Advance(9 * time.Millisecond)
+	if len(ran) != 0 {
+		t.Fatalf("Expected no timer to run early, got %v", ran)
+	}
+
+	// Reset moves a waiting timer from the current time
+	if !late.Reset(5 * time.Millisecond) {
+		t.Error("Expected Reset to report a waiting timer")
+	}
+	clock.Advance(time.Hour)
+	if want := []string{"early", "late"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("Expected %v in the order they were due, got %v", want, ran)
+	}
+
+	// A timer that ran can be started again
+	if late.Reset(time.Second) {
+		t.Error("Expected Reset to report a timer that already ran")
+	}
+	clock.Set(clock.Now().Add(time.Second))
+	if len(ran) != 3 || ran[2] != "late" {
+		t.Errorf("Expected the reset timer to run again, got %v", ran)
+	}
+}
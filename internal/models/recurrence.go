@@ -0,0 +1,83 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Period is the unit a recurrence counts in.
+type Period string
+
+const (
+	PeriodDay   Period = "day"
+	PeriodWeek  Period = "week"
+	PeriodMonth Period = "month"
+	PeriodYear  Period = "year"
+)
+
+// Recurrence says how often a todo repeats: every Every periods after its
+// due time.
+type Recurrence struct {
+	Every  int    `json:"every"`
+	Period Period `json:"period"`
+}
+
+// Next returns the time one recurrence after t, at the same clock time.
+// Months and years are clamped to the end of shorter months, so a todo due
+// on January 31 is next due at the end of February.
+func (r Recurrence) Next(t time.Time) time.Time {
+	every := max(r.Every, 1)
+	switch r.Period {
+	case PeriodWeek:
+		return t.AddDate(0, 0, 7*every)
+	case PeriodMonth:
+		return addMonths(t, every)
+	case PeriodYear:
+		return addMonths(t, 12*every)
+	default:
+		return t.AddDate(0, 0, every)
+	}
+}
+
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	return time.Date(first.Year(), first.Month(), min(t.Day(), last), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// String describes the recurrence, such as "every month" or "every 2 weeks".
+func (r Recurrence) String() string {
+	if r.Every <= 1 {
+		return "every " + string(r.Period)
+	}
+	return fmt.Sprintf("every %d %ss", r.Every, r.Period)
+}
+
+func formatRecurrence(r *Recurrence) string {
+	if r == nil {
+		return ""
+	}
+	return r.String()
+}
+
+// Repeat returns the next occurrence of a recurring todo, due one
+// recurrence after it, and hands the recurrence over to it, so that
+// completing the todo again does not repeat it twice. It reports false
+// for todos without a recurrence or due time.
+func (t *Todo) Repeat(clock Clock, ids IDGenerator) (Todo, bool) {
+	if t.Recurrence == nil || t.Due == nil {
+		return Todo{}, false
+	}
+	next := NewTodo(t.Text, clock, ids)
+	next.Notes = t.Notes
+	next.Priority = t.Priority
+	next.Tags = slices.Clone(t.Tags)
+	due := t.Recurrence.Next(*t.Due)
+	next.Due = &due
+	next.Recurrence = t.Recurrence
+
+	t.record(next.CreatedAt, FieldRecurrence, formatRecurrence(t.Recurrence), "")
+	t.Recurrence = nil
+	return next, true
+}
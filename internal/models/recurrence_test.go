@@ -0,0 +1,80 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecurrenceNext(t *testing.T) {
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 9, 30, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		r    Recurrence
+		from time.Time
+		want time.Time
+	}{
+		{Recurrence{1, PeriodDay}, at(2024, time.February, 28), at(2024, time.February, 29)},
+		{Recurrence{3, PeriodDay}, at(2024, time.December, 30), at(2025, time.January, 2)},
+		{Recurrence{2, PeriodWeek}, at(2024, time.March, 13), at(2024, time.March, 27)},
+		{Recurrence{1, PeriodMonth}, at(2024, time.January, 31), at(2024, time.February, 29)},
+		{Recurrence{1, PeriodMonth}, at(2024, time.December, 15), at(2025, time.January, 15)},
+		{Recurrence{1, PeriodYear}, at(2024, time.February, 29), at(2025, time.February, 28)},
+		// An unset interval counts as one
+		{Recurrence{0, PeriodDay}, at(2024, time.March, 1), at(2024, time.March, 2)},
+	}
+	for _, tt := range tests {
+		if got := tt.r.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("Expected %v after %v to be %v, got %v", tt.r, tt.from, tt.want, got)
+		}
+	}
+
+	if s := (Recurrence{1, PeriodMonth}).String(); s != "every month" {
+		t.Errorf("Expected \"every month\", got %q", s)
+	}
+	if s := (Recurrence{2, PeriodWeek}).String(); s != "every 2 weeks" {
+		t.Errorf("Expected \"every 2 weeks\", got %q", s)
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	clock, ids := newTestClock()
+	todo := NewTodo("Pay rent", clock, ids)
+	if _, ok := todo.Repeat(clock, ids); ok {
+		t.Error("Expected a todo without a recurrence not to repeat")
+	}
+
+	due := time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+	todo.Due = &due
+	todo.Priority = PriorityHigh
+	todo.Tags = []string{"home"}
+	todo.Recurrence = &Recurrence{Every: 1, Period: PeriodMonth}
+	completed := clock.Advance(time.Hour)
+	todo.Toggle(completed)
+
+	next, ok := todo.Repeat(clock, ids)
+	if !ok {
+		t.Fatal("Expected a recurring todo to repeat")
+	}
+	if next.ID != "todo-2" || next.Completed || next.Text != "Pay rent" || next.Priority != PriorityHigh || !next.CreatedAt.Equal(completed) {
+		t.Errorf("Expected an open copy with the next ID created on completion, got %+v", next)
+	}
+	if next.Due == nil || !next.Due.Equal(time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the next one due at the end of February, got %v", next.Due)
+	}
+	next.Tags[0] = "work"
+	if todo.Tags[0] != "home" {
+		t.Error("Expected the copy not to share tags")
+	}
+
+	// The recurrence moves on, so the todo does not repeat again
+	if todo.Recurrence != nil || next.Recurrence == nil {
+		t.Errorf("Expected the recurrence to move to the copy, got %v and %v", todo.Recurrence, next.Recurrence)
+	}
+	if _, ok := todo.Repeat(clock, ids); ok {
+		t.Error("Expected a todo to repeat only once")
+	}
+	if last := todo.History[len(todo.History)-1]; last.Field != FieldRecurrence || last.From != "every month" || !last.At.Equal(completed) {
+		t.Errorf("Expected the handover in the history, got %+v", last)
+	}
+}
@@ -1,16 +1,83 @@
 package models
 
 import (
+	"strconv"
+	"strings"
 	"time"
-
-	"github.com/google/uuid"
 )
 
 type Todo struct {
 	ID        string    `json:"id"`
 	Text      string    `json:"text"`
+	Notes     string    `json:"notes,omitempty"`
 	Completed bool      `json:"completed"`
+	Status    Status    `json:"status,omitempty"`
+	Priority  Priority  `json:"priority,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
+	// Due is when the todo should be done by, if it has a deadline.
+	Due *time.Time `json:"due,omitempty"`
+	// Recurrence makes a todo with a due time come back when completed.
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+	// UpdatedAt is when a field of the todo last changed. Todos saved
+	// before it was recorded have none.
+	UpdatedAt time.Time `json:"updated_at"`
+	// CompletedAt is when the todo was last completed. Todos completed
+	// before it was recorded have none.
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	// ArchivedAt is set on todos moved to the archive.
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// DeletedAt is set on todos moved to the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// History lists the changes made to the todo, oldest first.
+	History []Change `json:"history,omitempty"`
+}
+
+// Change records that a field of a todo changed, with the old and new
+// values. Notes changes leave the values out, as notes can be long.
+type Change struct {
+	At    time.Time `json:"at"`
+	Field string    `json:"field"`
+	From  string    `json:"from,omitempty"`
+	To    string    `json:"to,omitempty"`
+}
+
+// Fields named in a todo's history.
+const (
+	FieldText       = "text"
+	FieldNotes      = "notes"
+	FieldCompleted  = "completed"
+	FieldPriority   = "priority"
+	FieldTags       = "tags"
+	FieldStatus     = "status"
+	FieldDue        = "due"
+	FieldRecurrence = "recurrence"
+)
+
+// MaxHistory bounds the history kept for each todo; older changes are
+// dropped first.
+const MaxHistory = 100
+
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "Low"
+	case PriorityMedium:
+		return "Medium"
+	case PriorityHigh:
+		return "High"
+	default:
+		return "None"
+	}
 }
 
 type TodoList struct {
@@ -32,25 +99,158 @@ type AppState struct {
 	InputText     string
 }
 
-func NewTodo(text string) Todo {
+// NewTodo makes an open todo created at the time of clock, with an ID
+// from ids.
+func NewTodo(text string, clock Clock, ids IDGenerator) Todo {
+	now := clock.Now()
 	return Todo{
-		ID:        uuid.New().String(),
+		ID:        ids.NewID(),
 		Text:      text,
 		Completed: false,
-		CreatedAt: time.Now(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// record notes a change to a field at now in the history and the update
+// time.
+func (t *Todo) record(now time.Time, field, from, to string) {
+	t.UpdatedAt = now
+	t.History = append(t.History, Change{At: now, Field: field, From: from, To: to})
+	if len(t.History) > MaxHistory {
+		t.History = t.History[len(t.History)-MaxHistory:]
 	}
 }
 
-func (t *Todo) Toggle() {
+// LastUpdated returns when the todo last changed, falling back to when it
+// was created.
+func (t *Todo) LastUpdated() time.Time {
+	if t.UpdatedAt.IsZero() {
+		return t.CreatedAt
+	}
+	return t.UpdatedAt
+}
+
+// Toggle completes or reopens the todo. Its status is cleared so that it
+// lands in the first done or open column of the board.
+func (t *Todo) Toggle(now time.Time) {
 	t.Completed = !t.Completed
+	t.Status = ""
+	if t.Completed {
+		t.CompletedAt = &now
+	} else {
+		t.CompletedAt = nil
+	}
+	t.record(now, FieldCompleted, strconv.FormatBool(!t.Completed), strconv.FormatBool(t.Completed))
+}
+
+// CompletionTime returns when the todo was completed, falling back to
+// when it was created for todos completed before that was recorded.
+func (t *Todo) CompletionTime() time.Time {
+	if t.CompletedAt != nil {
+		return *t.CompletedAt
+	}
+	return t.CreatedAt
+}
+
+// IsOverdue reports whether the todo is still open past its due time.
+func (t *Todo) IsOverdue(now time.Time) bool {
+	return !t.Completed && t.Due != nil && t.Due.Before(now)
 }
 
-func (t *Todo) SetText(text string) {
+// DueFormat is how due times are written in a todo's history.
+const DueFormat = "2006-01-02 15:04"
+
+// SetDue sets or, with nil, clears when the todo is due.
+func (t *Todo) SetDue(due *time.Time, now time.Time) {
+	if due == nil && t.Due == nil || due != nil && t.Due != nil && due.Equal(*t.Due) {
+		return
+	}
+	t.record(now, FieldDue, formatDue(t.Due), formatDue(due))
+	t.Due = due
+}
+
+func formatDue(due *time.Time) string {
+	if due == nil {
+		return ""
+	}
+	return due.Format(DueFormat)
+}
+
+func (t *Todo) SetText(text string, now time.Time) {
+	if text == t.Text {
+		return
+	}
+	t.record(now, FieldText, t.Text, text)
 	t.Text = text
 }
 
-func (tl *TodoList) AddTodo(text string) {
-	todo := NewTodo(text)
+func (t *Todo) SetNotes(notes string, now time.Time) {
+	if notes == t.Notes {
+		return
+	}
+	t.record(now, FieldNotes, "", "")
+	t.Notes = notes
+}
+
+func (t *Todo) SetPriority(priority Priority, now time.Time) {
+	if priority == t.Priority {
+		return
+	}
+	t.record(now, FieldPriority, t.Priority.String(), priority.String())
+	t.Priority = priority
+}
+
+// NormalizeTag trims a tag and drops a leading '#'.
+func NormalizeTag(tag string) string {
+	return strings.TrimPrefix(strings.TrimSpace(tag), "#")
+}
+
+// HasTag reports whether the todo has tag, ignoring case.
+func (t *Todo) HasTag(tag string) bool {
+	tag = NormalizeTag(tag)
+	for _, existing := range t.Tags {
+		if strings.EqualFold(existing, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTag adds tag unless the todo already has it. It reports whether the
+// tags changed.
+func (t *Todo) AddTag(tag string, now time.Time) bool {
+	tag = NormalizeTag(tag)
+	if tag == "" || t.HasTag(tag) {
+		return false
+	}
+	before := strings.Join(t.Tags, " ")
+	t.Tags = append(t.Tags, tag)
+	t.record(now, FieldTags, before, strings.Join(t.Tags, " "))
+	return true
+}
+
+// Matches reports whether the todo text, notes or tags contain query,
+// ignoring case.
+func (t *Todo) Matches(query string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return true
+	}
+	if strings.Contains(strings.ToLower(t.Text), query) ||
+		strings.Contains(strings.ToLower(t.Notes), query) {
+		return true
+	}
+	for _, tag := range t.Tags {
+		if strings.Contains(strings.ToLower(tag), strings.TrimPrefix(query, "#")) {
+			return true
+		}
+	}
+	return false
+}
+
+func (tl *TodoList) AddTodo(text string, clock Clock, ids IDGenerator) {
+	todo := NewTodo(text, clock, ids)
 	tl.Todos = append(tl.Todos, todo)
 }
 
@@ -64,6 +264,38 @@ func (tl *TodoList) DeleteTodo(id string) bool {
 	return false
 }
 
+// CompletedBy returns copies of the todos completed at or before cutoff,
+// in list order, leaving the list unchanged.
+func (tl *TodoList) CompletedBy(cutoff time.Time) []Todo {
+	var completed []Todo
+	for _, todo := range tl.Todos {
+		if todo.Completed && !todo.CompletionTime().After(cutoff) {
+			completed = append(completed, todo)
+		}
+	}
+	return completed
+}
+
+// RemoveTodos removes the todos with the given IDs and returns them in
+// list order.
+func (tl *TodoList) RemoveTodos(ids ...string) []Todo {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	var removed []Todo
+	kept := tl.Todos[:0]
+	for _, todo := range tl.Todos {
+		if wanted[todo.ID] {
+			removed = append(removed, todo)
+		} else {
+			kept = append(kept, todo)
+		}
+	}
+	tl.Todos = kept
+	return removed
+}
+
 func (tl *TodoList) FindTodo(id string) *Todo {
 	for i, todo := range tl.Todos {
 		if todo.ID == id {
@@ -2,11 +2,20 @@ package models
 
 import (
 	"testing"
+	"time"
 )
 
+// testStart is when the fake clocks of the tests start.
+var testStart = time.Date(2024, 3, 12, 9, 0, 0, 0, time.UTC)
+
+func newTestClock() (*FakeClock, *SequentialIDs) {
+	return NewFakeClock(testStart), &SequentialIDs{Prefix: "todo-"}
+}
+
 func TestNewTodo(t *testing.T) {
+	clock, ids := newTestClock()
 	text := "Test todo"
-	todo := NewTodo(text)
+	todo := NewTodo(text, clock, ids)
 
 	if todo.Text != text {
 		t.Errorf("Expected text %s, got %s", text, todo.Text)
@@ -16,52 +25,85 @@ func TestNewTodo(t *testing.T) {
 		t.Error("New todo should not be completed")
 	}
 
-	if todo.ID == "" {
-		t.Error("Todo should have an ID")
+	if todo.ID != "todo-1" {
+		t.Errorf("Expected the first ID, got %q", todo.ID)
+	}
+
+	if !todo.CreatedAt.Equal(testStart) || !todo.UpdatedAt.Equal(testStart) {
+		t.Errorf("Expected the todo created and updated at %v, got %v and %v", testStart, todo.CreatedAt, todo.UpdatedAt)
+	}
+
+	if other := NewTodo(text, clock, ids); other.ID != "todo-2" {
+		t.Errorf("Expected the next ID, got %q", other.ID)
 	}
 
-	if todo.CreatedAt.IsZero() {
-		t.Error("Todo should have a creation time")
+	// The system clock and UUIDs stay the defaults of the app
+	system := NewTodo(text, SystemClock, UUIDs)
+	if len(system.ID) != 36 || system.CreatedAt.IsZero() {
+		t.Errorf("Expected a UUID and the current time, got %q and %v", system.ID, system.CreatedAt)
 	}
 }
 
 func TestTodoToggle(t *testing.T) {
-	todo := NewTodo("Test")
+	clock, ids := newTestClock()
+	todo := NewTodo("Test", clock, ids)
 	
 	if todo.Completed {
 		t.Error("Todo should start uncompleted")
 	}
 
-	todo.Toggle()
+	completed := clock.Advance(time.Hour)
+	todo.Toggle(clock.Now())
 	if !todo.Completed {
 		t.Error("Todo should be completed after toggle")
 	}
+	if todo.CompletedAt == nil || !todo.CompletedAt.Equal(completed) {
+		t.Errorf("Expected the todo completed at %v, got %v", completed, todo.CompletedAt)
+	}
 
-	todo.Toggle()
+	reopened := clock.Advance(time.Minute)
+	todo.Toggle(clock.Now())
 	if todo.Completed {
 		t.Error("Todo should be uncompleted after second toggle")
 	}
+	if todo.CompletedAt != nil {
+		t.Error("Reopening a todo should clear its completion time")
+	}
+	if !todo.UpdatedAt.Equal(reopened) {
+		t.Errorf("Expected the todo updated at %v, got %v", reopened, todo.UpdatedAt)
+	}
 }
 
 func TestTodoListAddTodo(t *testing.T) {
+	clock, ids := newTestClock()
 	todoList := TodoList{}
 	text := "Test todo"
 
-	todoList.AddTodo(text)
+	todoList.AddTodo(text, clock, ids)
+	clock.Advance(time.Second)
+	todoList.AddTodo("Another", clock, ids)
 
-	if len(todoList.Todos) != 1 {
-		t.Errorf("Expected 1 todo, got %d", len(todoList.Todos))
+	if len(todoList.Todos) != 2 {
+		t.Fatalf("Expected 2 todos, got %d", len(todoList.Todos))
 	}
 
 	if todoList.Todos[0].Text != text {
 		t.Errorf("Expected text %s, got %s", text, todoList.Todos[0].Text)
 	}
+
+	if todoList.Todos[0].ID != "todo-1" || todoList.Todos[1].ID != "todo-2" {
+		t.Errorf("Expected sequential IDs, got %q and %q", todoList.Todos[0].ID, todoList.Todos[1].ID)
+	}
+	if want := testStart.Add(time.Second); !todoList.Todos[1].CreatedAt.Equal(want) {
+		t.Errorf("Expected the second todo created at %v, got %v", want, todoList.Todos[1].CreatedAt)
+	}
 }
 
 func TestTodoListDeleteTodo(t *testing.T) {
+	clock, ids := newTestClock()
 	todoList := TodoList{}
-	todoList.AddTodo("Test 1")
-	todoList.AddTodo("Test 2")
+	todoList.AddTodo("Test 1", clock, ids)
+	todoList.AddTodo("Test 2", clock, ids)
 
 	id := todoList.Todos[0].ID
 	deleted := todoList.DeleteTodo(id)
@@ -80,9 +122,10 @@ func TestTodoListDeleteTodo(t *testing.T) {
 }
 
 func TestTodoListFindTodo(t *testing.T) {
+	clock, ids := newTestClock()
 	todoList := TodoList{}
-	todoList.AddTodo("Test 1")
-	todoList.AddTodo("Test 2")
+	todoList.AddTodo("Test 1", clock, ids)
+	todoList.AddTodo("Test 2", clock, ids)
 
 	id := todoList.Todos[0].ID
 	found := todoList.FindTodo(id)
@@ -102,12 +145,13 @@ func TestTodoListFindTodo(t *testing.T) {
 }
 
 func TestGetFilteredTodos(t *testing.T) {
+	clock, ids := newTestClock()
 	todoList := TodoList{}
-	todoList.AddTodo("Active todo")
-	todoList.AddTodo("Completed todo")
+	todoList.AddTodo("Active todo", clock, ids)
+	todoList.AddTodo("Completed todo", clock, ids)
 	
 	// Mark second todo as completed
-	todoList.Todos[1].Toggle()
+	todoList.Todos[1].Toggle(clock.Now())
 
 	// Test FilterAll
 	allTodos := todoList.GetFilteredTodos(FilterAll)
@@ -132,4 +176,147 @@ func TestGetFilteredTodos(t *testing.T) {
 	if completedTodos[0].Text != "Completed todo" {
 		t.Errorf("FilterCompleted: expected 'Completed todo', got %s", completedTodos[0].Text)
 	}
-}
\ No newline at end of file
+}
+
+func TestTodoMatches(t *testing.T) {
+	clock, ids := newTestClock()
+	todo := NewTodo("Pay rent", clock, ids)
+	todo.SetNotes("Transfer to landlord\nReference: APT-12", clock.Now())
+	todo.AddTag("home", clock.Now())
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"", true},
+		{"rent", true},
+		{"PAY", true},
+		{"landlord", true},
+		{"apt-12", true},
+		{"#Home", true},
+		{"groceries", false},
+	}
+
+	for _, tt := range tests {
+		if got := todo.Matches(tt.query); got != tt.want {
+			t.Errorf("Matches(%q): expected %v, got %v", tt.query, tt.want, got)
+		}
+	}
+}
+
+func TestTodoTags(t *testing.T) {
+	clock, ids := newTestClock()
+	todo := NewTodo("Pay rent", clock, ids)
+
+	if !todo.AddTag(" #home ", clock.Now()) {
+		t.Error("Expected the tag to be added")
+	}
+	if todo.AddTag("Home", clock.Now()) {
+		t.Error("Expected a duplicate tag differing in case to be ignored")
+	}
+	if todo.AddTag("#", clock.Now()) {
+		t.Error("Expected an empty tag to be ignored")
+	}
+	if len(todo.Tags) != 1 || todo.Tags[0] != "home" {
+		t.Errorf("Expected tags [home], got %v", todo.Tags)
+	}
+	if !todo.HasTag("HOME") {
+		t.Error("Expected HasTag to ignore case")
+	}
+}
+
+func TestTodoListCompletedBy(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -10)
+	recent := now.AddDate(0, 0, -1)
+
+	tl := TodoList{Todos: []Todo{
+		{ID: "active", Text: "Active"},
+		{ID: "old", Text: "Old", Completed: true, CompletedAt: &old},
+		{ID: "recent", Text: "Recent", Completed: true, CompletedAt: &recent},
+		{ID: "legacy", Text: "Legacy", Completed: true, CreatedAt: old},
+	}}
+
+	completed := tl.CompletedBy(now.AddDate(0, 0, -7))
+	if len(completed) != 2 || completed[0].ID != "old" || completed[1].ID != "legacy" {
+		t.Fatalf("Expected the todos completed over a week ago, got %+v", completed)
+	}
+	completed[0].Text = "Changed"
+	if len(tl.Todos) != 4 || tl.Todos[1].Text != "Old" {
+		t.Errorf("Expected the list to be left unchanged, got %+v", tl.Todos)
+	}
+
+	if completed = tl.CompletedBy(now); len(completed) != 3 {
+		t.Errorf("Expected every completed todo, got %+v", completed)
+	}
+}
+
+func TestTodoListRemoveTodos(t *testing.T) {
+	tl := TodoList{Todos: []Todo{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}}
+
+	removed := tl.RemoveTodos("c", "a", "missing")
+	if len(removed) != 2 || removed[0].ID != "a" || removed[1].ID != "c" {
+		t.Errorf("Expected a and c to be removed in list order, got %+v", removed)
+	}
+	if len(tl.Todos) != 2 || tl.Todos[0].ID != "b" || tl.Todos[1].ID != "d" {
+		t.Errorf("Expected b and d to stay, got %+v", tl.Todos)
+	}
+}
+
+func TestTodoHistory(t *testing.T) {
+	clock, ids := newTestClock()
+	todo := NewTodo("Draft", clock, ids)
+
+	// Each change happens a minute after the one before
+	next := func() time.Time { return clock.Advance(time.Minute) }
+	todo.SetText("Final", next())
+	todo.SetText("Final", next())
+	todo.Toggle(next())
+	todo.SetNotes("Long notes", next())
+	todo.SetPriority(PriorityHigh, next())
+	todo.AddTag("work", next())
+	due := time.Date(2024, 3, 12, 17, 0, 0, 0, time.UTC)
+	todo.SetDue(&due, next())
+	todo.SetDue(&due, next())
+	todo.SetDue(nil, next())
+
+	minute := func(n int) time.Time { return testStart.Add(time.Duration(n) * time.Minute) }
+	want := []Change{
+		{At: minute(1), Field: FieldText, From: "Draft", To: "Final"},
+		{At: minute(3), Field: FieldCompleted, From: "false", To: "true"},
+		{At: minute(4), Field: FieldNotes},
+		{At: minute(5), Field: FieldPriority, From: "None", To: "High"},
+		{At: minute(6), Field: FieldTags, From: "", To: "work"},
+		{At: minute(7), Field: FieldDue, From: "", To: "2024-03-12 17:00"},
+		{At: minute(9), Field: FieldDue, From: "2024-03-12 17:00", To: ""},
+	}
+	if len(todo.History) != len(want) {
+		t.Fatalf("Expected %d changes, got %+v", len(want), todo.History)
+	}
+	for i, change := range todo.History {
+		if change != want[i] {
+			t.Errorf("Expected change %d to be %+v, got %+v", i, want[i], change)
+		}
+	}
+	if !todo.UpdatedAt.Equal(minute(9)) {
+		t.Errorf("Expected UpdatedAt to follow the last change, got %v", todo.UpdatedAt)
+	}
+	if todo.CompletedAt == nil || !todo.CompletedAt.Equal(minute(3)) {
+		t.Errorf("Expected the todo completed at %v, got %v", minute(3), todo.CompletedAt)
+	}
+
+	for i := 0; i < MaxHistory; i++ {
+		todo.Toggle(next())
+	}
+	if len(todo.History) != MaxHistory || todo.History[0].Field != FieldCompleted {
+		t.Errorf("Expected the history to keep the latest %d changes, got %d", MaxHistory, len(todo.History))
+	}
+}
+
+func TestTodoLastUpdated(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	todo := Todo{CreatedAt: created}
+	if !todo.LastUpdated().Equal(created) {
+		t.Errorf("Expected todos without an update time to fall back to creation, got %v", todo.LastUpdated())
+	}
+}
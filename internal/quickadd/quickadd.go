@@ -0,0 +1,526 @@
+// Package quickadd parses the text typed into the add box into the fields
+// of a new todo, so that "Pay rent tomorrow 9am !high #home every month"
+// becomes the todo "Pay rent" due tomorrow at 9:00 with high priority, the
+// tag "home" and a monthly recurrence.
+//
+// Recognized words are taken out of the text and the rest is kept as is:
+//
+//   - Tags are words starting with '#', other than issue numbers like #12.
+//   - Priorities are !high, !medium and !low, or !h, !m, !l and !1 to !3.
+//   - Dates are today, tonight, tomorrow, weekday names, "next fri", "next
+//     week", "in 3 days", 2024-03-12, 3/12 and "march 12". A weekday is the
+//     next such day, today included, and "next fri" the one a week later.
+//     Dates without a year are the next such day.
+//   - Times are 9am, 9:30pm, 21:00, noon, "at 9" and "in 2 hours".
+//   - Recurrences are "every month", "every 2 weeks", "every other day",
+//     "every fri", and daily, weekly, monthly and yearly after "every" or
+//     "repeat".
+//
+// Dates may follow "on", "by" or "due", and times "at". Words that are
+// common in ordinary text only count after such a word: weekday and month
+// abbreviations, "may" and dates like 3/12 need "on fri", "due may 3" or
+// "by 3/12", so that "Read pages 3/4" keeps its text. A word starting
+// with a backslash is always kept in the text, without the backslash, so
+// "Call \tomorrow" is the todo "Call tomorrow".
+//
+// Each field is parsed once; a second date is left in the text. A date
+// without a time is due at the end of the day, and a time without a date at
+// its next occurrence.
+package quickadd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lapis2411/todo/internal/calendar"
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// Result is a todo parsed from the add box.
+type Result struct {
+	// Text is the input without the words parsed into other fields.
+	Text       string
+	Due        *time.Time
+	Priority   models.Priority
+	Tags       []string
+	Recurrence *models.Recurrence
+}
+
+// HasFields reports whether anything besides the text was parsed.
+func (r Result) HasFields() bool {
+	return r.Due != nil || r.Priority != models.PriorityNone || len(r.Tags) > 0 || r.Recurrence != nil
+}
+
+// clock is a time of day.
+type clock struct {
+	hour, min int
+}
+
+// endOfDay is when todos due on a day without a time are due.
+var endOfDay = clock{23, 59}
+
+type parser struct {
+	now   time.Time
+	today calendar.Date
+	words []string
+	// lower holds the words in lower case without trailing punctuation,
+	// for matching
+	lower []string
+	text  []string
+
+	result       Result
+	date         *calendar.Date
+	clock        *clock
+	defaultClock *clock // From "tonight", unless a time is given
+	instant      *time.Time
+	weekday      *time.Weekday // From "every fri", unless a date is given
+}
+
+// Parse parses input into a todo, with relative dates such as "tomorrow"
+// taken from now.
+func Parse(input string, now time.Time) Result {
+	p := &parser{now: now, today: calendar.DateOf(now), words: strings.Fields(input)}
+	for _, word := range p.words {
+		p.lower = append(p.lower, strings.TrimRight(strings.ToLower(word), ",.;"))
+	}
+	matchers := []func(int) int{p.tag, p.priority, p.recurrence, p.relative, p.calendarDate, p.timeOfDay}
+	for i := 0; i < len(p.words); {
+		if word := p.words[i]; len(word) > 1 && word[0] == '\\' {
+			p.text = append(p.text, word[1:])
+			i++
+			continue
+		}
+		n := 0
+		for _, match := range matchers {
+			if n = match(i); n > 0 {
+				break
+			}
+		}
+		if n == 0 {
+			p.text = append(p.text, p.words[i])
+			n = 1
+		}
+		i += n
+	}
+	p.result.Text = strings.Join(p.text, " ")
+	p.result.Due = p.due()
+	return p.result
+}
+
+// word returns the matching form of the i-th word, or "" past the end.
+func (p *parser) word(i int) string {
+	if i < len(p.lower) {
+		return p.lower[i]
+	}
+	return ""
+}
+
+var issueNumber = regexp.MustCompile(`^#\d+$`)
+
+func (p *parser) tag(i int) int {
+	word := strings.TrimRight(p.words[i], ",.;")
+	if !strings.HasPrefix(word, "#") || issueNumber.MatchString(word) {
+		return 0
+	}
+	tag := models.NormalizeTag(word)
+	if tag == "" {
+		return 0
+	}
+	for _, existing := range p.result.Tags {
+		if strings.EqualFold(existing, tag) {
+			return 1
+		}
+	}
+	p.result.Tags = append(p.result.Tags, tag)
+	return 1
+}
+
+var priorities = map[string]models.Priority{
+	"!high": models.PriorityHigh, "!h": models.PriorityHigh, "!1": models.PriorityHigh,
+	"!medium": models.PriorityMedium, "!med": models.PriorityMedium, "!m": models.PriorityMedium, "!2": models.PriorityMedium,
+	"!low": models.PriorityLow, "!l": models.PriorityLow, "!3": models.PriorityLow,
+}
+
+func (p *parser) priority(i int) int {
+	priority, ok := priorities[p.word(i)]
+	if !ok || p.result.Priority != models.PriorityNone {
+		return 0
+	}
+	p.result.Priority = priority
+	return 1
+}
+
+var adverbs = map[string]models.Period{
+	"daily": models.PeriodDay, "weekly": models.PeriodWeek, "monthly": models.PeriodMonth,
+	"yearly": models.PeriodYear, "annually": models.PeriodYear,
+}
+
+func (p *parser) recurrence(i int) int {
+	if p.result.Recurrence != nil {
+		return 0
+	}
+	word, next := p.word(i), p.word(i+1)
+	if word != "every" && word != "repeat" {
+		return 0
+	}
+	// "Send weekly newsletter" is not a recurrence, "repeat weekly" is
+	if period, ok := adverbs[next]; ok {
+		p.result.Recurrence = &models.Recurrence{Every: 1, Period: period}
+		return 2
+	}
+	if word != "every" {
+		return 0
+	}
+
+	if day, ok := weekday(next); ok {
+		p.result.Recurrence = &models.Recurrence{Every: 1, Period: models.PeriodWeek}
+		p.weekday = &day
+		return 2
+	}
+	every, n := 1, 1
+	if next == "other" {
+		every, n = 2, 2
+	} else if count, err := strconv.Atoi(next); err == nil {
+		every, n = count, 2
+	}
+	u, ok := units[p.word(i+n)]
+	if !ok || u.period == "" || every < 1 {
+		return 0 // Hours and minutes are too short to repeat a todo
+	}
+	p.result.Recurrence = &models.Recurrence{Every: every, Period: u.period}
+	return n + 1
+}
+
+// span is a unit of "in 3 days" and "every 2 weeks". Days and months
+// are counted on the calendar rather than in hours, so that they keep the
+// time of day across daylight saving changes.
+type span struct {
+	duration time.Duration
+	days     int
+	months   int
+	period   models.Period // The recurrence, for spans a todo can repeat in
+}
+
+var (
+	minute = span{duration: time.Minute}
+	hour   = span{duration: time.Hour}
+	day    = span{days: 1, period: models.PeriodDay}
+	week   = span{days: 7, period: models.PeriodWeek}
+	month  = span{months: 1, period: models.PeriodMonth}
+	year   = span{months: 12, period: models.PeriodYear}
+)
+
+var units = map[string]span{
+	"min": minute, "mins": minute, "minute": minute, "minutes": minute,
+	"h": hour, "hr": hour, "hrs": hour, "hour": hour, "hours": hour,
+	"d": day, "day": day, "days": day,
+	"w": week, "wk": week, "wks": week, "week": week, "weeks": week,
+	"month": month, "months": month,
+	"y": year, "yr": year, "yrs": year, "year": year, "years": year,
+}
+
+// relative parses "in 3 days" and "in an hour".
+func (p *parser) relative(i int) int {
+	if p.word(i) != "in" || p.date != nil || p.instant != nil {
+		return 0
+	}
+	count := 0
+	switch next := p.word(i + 1); next {
+	case "a", "an":
+		count = 1
+	default:
+		n, err := strconv.Atoi(next)
+		if err != nil || n < 0 {
+			return 0
+		}
+		count = n
+	}
+	u, ok := units[p.word(i+2)]
+	if !ok {
+		return 0
+	}
+	if u.duration != 0 {
+		if p.clock != nil {
+			return 0
+		}
+		instant := p.now.Add(time.Duration(count) * u.duration).Truncate(time.Minute)
+		p.instant = &instant
+	} else {
+		date := p.today.AddDays(count * u.days).AddMonths(count * u.months)
+		p.date = &date
+	}
+	return 3
+}
+
+// calendarDate parses a date, after "on", "by" or "due" when given.
+func (p *parser) calendarDate(i int) int {
+	if p.date != nil || p.instant != nil {
+		return 0
+	}
+	skip := 0
+	switch p.word(i) {
+	case "on", "by", "due":
+		skip = 1
+	}
+	date, n := p.parseDate(i+skip, skip > 0)
+	if n == 0 {
+		return 0
+	}
+	p.date = &date
+	return skip + n
+}
+
+// parseDate parses the date at the i-th word. Abbreviations and dates like
+// 3/12 count only when marked, as when they follow "on".
+func (p *parser) parseDate(i int, marked bool) (calendar.Date, int) {
+	word := p.word(i)
+	switch word {
+	case "today":
+		return p.today, 1
+	case "tonight":
+		p.defaultClock = &clock{20, 0}
+		return p.today, 1
+	case "tomorrow", "tmr", "tmrw":
+		return p.today.AddDays(1), 1
+	case "this":
+		if day, ok := weekday(p.word(i + 1)); ok {
+			return p.upcoming(day), 2
+		}
+		return calendar.Date{}, 0
+	case "next":
+		next := p.word(i + 1)
+		if day, ok := weekday(next); ok {
+			return p.upcoming(day).AddDays(7), 2
+		}
+		switch next {
+		case "week":
+			return p.today.AddDays(7), 2
+		case "month":
+			return p.today.AddMonths(1), 2
+		case "year":
+			return p.today.AddMonths(12), 2
+		}
+		return calendar.Date{}, 0
+	}
+	if day, ok := weekday(word); ok && (marked || word == strings.ToLower(day.String())) {
+		return p.upcoming(day), 1
+	}
+	if date, ok := p.numericDate(word, marked); ok {
+		return date, 1
+	}
+	return p.namedDate(i, marked)
+}
+
+// upcoming returns the next day that falls on a weekday, today included.
+func (p *parser) upcoming(day time.Weekday) calendar.Date {
+	return p.today.AddDays((int(day) - int(p.today.Weekday()) + 7) % 7)
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+func weekday(word string) (time.Weekday, bool) {
+	day, ok := weekdays[word]
+	return day, ok
+}
+
+var (
+	isoDate   = regexp.MustCompile(`^(\d{4})-(\d{1,2})-(\d{1,2})$`)
+	slashDate = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})(?:/(\d{2}|\d{4}))?$`)
+)
+
+// numericDate parses 2024-03-12, and when marked 3/12 or 3/12/2024 as
+// month and day, which could as well be a fraction.
+func (p *parser) numericDate(word string, marked bool) (calendar.Date, bool) {
+	if m := isoDate.FindStringSubmatch(word); m != nil {
+		return validDate(atoi(m[1]), atoi(m[2]), atoi(m[3]))
+	}
+	m := slashDate.FindStringSubmatch(word)
+	if m == nil || !marked {
+		return calendar.Date{}, false
+	}
+	if m[3] == "" {
+		return p.nextDate(atoi(m[1]), atoi(m[2]))
+	}
+	year := atoi(m[3])
+	if len(m[3]) == 2 {
+		year += 2000
+	}
+	return validDate(year, atoi(m[1]), atoi(m[2]))
+}
+
+var (
+	months = map[string]time.Month{
+		"jan": time.January, "january": time.January,
+		"feb": time.February, "february": time.February,
+		"mar": time.March, "march": time.March,
+		"apr": time.April, "april": time.April,
+		"may": time.May,
+		"jun": time.June, "june": time.June,
+		"jul": time.July, "july": time.July,
+		"aug": time.August, "august": time.August,
+		"sep": time.September, "sept": time.September, "september": time.September,
+		"oct": time.October, "october": time.October,
+		"nov": time.November, "november": time.November,
+		"dec": time.December, "december": time.December,
+	}
+	dayOfMonth = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+	yearNumber = regexp.MustCompile(`^\d{4}$`)
+)
+
+// namedDate parses "mar 12", "march 12th" and "12 mar", each optionally
+// followed by a year. Abbreviated months and "may" count only when marked.
+func (p *parser) namedDate(i int, marked bool) (calendar.Date, int) {
+	var month time.Month
+	var name, day string
+	if m, ok := months[p.word(i)]; ok {
+		month, name, day = m, p.word(i), p.word(i+1)
+	} else if m, ok := months[p.word(i+1)]; ok {
+		month, name, day = m, p.word(i+1), p.word(i)
+	} else {
+		return calendar.Date{}, 0
+	}
+	if !marked && (month == time.May || name != strings.ToLower(month.String())) {
+		return calendar.Date{}, 0
+	}
+	d := dayOfMonth.FindStringSubmatch(day)
+	if d == nil {
+		return calendar.Date{}, 0
+	}
+	if y := p.word(i + 2); yearNumber.MatchString(y) {
+		if date, ok := validDate(atoi(y), int(month), atoi(d[1])); ok {
+			return date, 3
+		}
+		return calendar.Date{}, 0
+	}
+	if date, ok := p.nextDate(int(month), atoi(d[1])); ok {
+		return date, 2
+	}
+	return calendar.Date{}, 0
+}
+
+// nextDate returns the next month and day from today, today included.
+func (p *parser) nextDate(month, day int) (calendar.Date, bool) {
+	for year := p.today.Year; year <= p.today.Year+4; year++ {
+		date, ok := validDate(year, month, day)
+		if ok && !date.Before(p.today) {
+			return date, true
+		}
+	}
+	return calendar.Date{}, false // February 29 too far ahead, or no such day
+}
+
+func validDate(year, month, day int) (calendar.Date, bool) {
+	date := calendar.Date{Year: year, Month: time.Month(month), Day: day}
+	if month < 1 || month > 12 || date.AddDays(0) != date {
+		return calendar.Date{}, false
+	}
+	return date, true
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+var clockTime = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm|a|p)?$`)
+
+// timeOfDay parses a time, after "at" when given.
+func (p *parser) timeOfDay(i int) int {
+	if p.clock != nil || p.instant != nil {
+		return 0
+	}
+	if word := p.word(i); word == "at" || word == "@" {
+		c, n := p.parseClock(i+1, true)
+		if n == 0 {
+			return 0
+		}
+		p.clock = &c
+		return n + 1
+	}
+	c, n := p.parseClock(i, false)
+	if n == 0 {
+		return 0
+	}
+	p.clock = &c
+	return n
+}
+
+// parseClock parses 9am, 9 pm, 9:30, 21:00 and noon. A bare hour such as
+// "9" counts only after "at".
+func (p *parser) parseClock(i int, bare bool) (clock, int) {
+	if p.word(i) == "noon" {
+		return clock{12, 0}, 1
+	}
+	m := clockTime.FindStringSubmatch(p.word(i))
+	if m == nil {
+		return clock{}, 0
+	}
+	hour, minute, suffix, n := atoi(m[1]), atoi(m[2]), m[3], 1
+	if suffix == "" {
+		if next := p.word(i + 1); next == "am" || next == "pm" {
+			suffix, n = next, 2
+		}
+	}
+	if minute > 59 {
+		return clock{}, 0
+	}
+	switch {
+	case suffix != "":
+		if hour < 1 || hour > 12 {
+			return clock{}, 0
+		}
+		hour %= 12
+		if suffix[0] == 'p' {
+			hour += 12
+		}
+	case m[2] != "" || bare:
+		if hour > 23 {
+			return clock{}, 0
+		}
+	default:
+		return clock{}, 0 // A plain number, as in "buy 2 apples"
+	}
+	return clock{hour, minute}, n
+}
+
+// due combines the parsed date and time into the due time.
+func (p *parser) due() *time.Time {
+	if p.instant != nil {
+		return p.instant
+	}
+	c := p.clock
+	if c == nil {
+		c = p.defaultClock
+	}
+	date := p.date
+	if date == nil && p.weekday != nil {
+		day := p.upcoming(*p.weekday)
+		date = &day
+	}
+	if date == nil {
+		if c == nil && p.result.Recurrence == nil {
+			return nil
+		}
+		day := p.today
+		// A time alone means its next occurrence
+		if c != nil && !day.At(c.hour, c.min, p.now.Location()).After(p.now) {
+			day = day.AddDays(1)
+		}
+		date = &day
+	}
+	if c == nil {
+		c = &endOfDay
+	}
+	due := date.At(c.hour, c.min, p.now.Location())
+	return &due
+}
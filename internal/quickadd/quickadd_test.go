@@ -0,0 +1,181 @@
+package quickadd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// now is a Wednesday morning, which the relative dates below count from.
+var now = time.Date(2024, time.March, 13, 10, 30, 15, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input      string
+		text       string
+		due        string // In models.DueFormat, or "" for none
+		priority   models.Priority
+		tags       string // Joined with spaces
+		recurrence string
+	}{
+		// Everything at once
+		{"Pay rent tomorrow 9am !high #home every month", "Pay rent", "2024-03-14 09:00", models.PriorityHigh, "home", "every month"},
+		{"Buy milk", "Buy milk", "", models.PriorityNone, "", ""},
+		{"", "", "", models.PriorityNone, "", ""},
+
+		// Days by name
+		{"Buy milk today", "Buy milk", "2024-03-13 23:59", models.PriorityNone, "", ""},
+		{"Call mom tonight", "Call mom", "2024-03-13 20:00", models.PriorityNone, "", ""},
+		{"Call mom tonight at 9pm", "Call mom", "2024-03-13 21:00", models.PriorityNone, "", ""},
+		{"Call TOMORROW", "Call", "2024-03-14 23:59", models.PriorityNone, "", ""},
+		{"Call tmrw", "Call", "2024-03-14 23:59", models.PriorityNone, "", ""},
+		{"Report on fri", "Report", "2024-03-15 23:59", models.PriorityNone, "", ""},
+		{"Report Friday", "Report", "2024-03-15 23:59", models.PriorityNone, "", ""},
+		{"Report next fri", "Report", "2024-03-22 23:59", models.PriorityNone, "", ""},
+		{"Standup on wed", "Standup", "2024-03-13 23:59", models.PriorityNone, "", ""},
+		{"Standup next wed", "Standup", "2024-03-20 23:59", models.PriorityNone, "", ""},
+		{"Review on monday", "Review", "2024-03-18 23:59", models.PriorityNone, "", ""},
+		{"Plan this sat", "Plan", "2024-03-16 23:59", models.PriorityNone, "", ""},
+		{"Laundry by sun", "Laundry", "2024-03-17 23:59", models.PriorityNone, "", ""},
+		{"Party next week", "Party", "2024-03-20 23:59", models.PriorityNone, "", ""},
+		{"Trip next month", "Trip", "2024-04-13 23:59", models.PriorityNone, "", ""},
+		{"Renew next year", "Renew", "2025-03-13 23:59", models.PriorityNone, "", ""},
+
+		// Relative dates and times
+		{"Dentist in 3 days", "Dentist", "2024-03-16 23:59", models.PriorityNone, "", ""},
+		{"Dentist in 0 days", "Dentist", "2024-03-13 23:59", models.PriorityNone, "", ""},
+		{"Renew in 2 weeks", "Renew", "2024-03-27 23:59", models.PriorityNone, "", ""},
+		{"Taxes in a month", "Taxes", "2024-04-13 23:59", models.PriorityNone, "", ""},
+		{"Passport in 1 year", "Passport", "2025-03-13 23:59", models.PriorityNone, "", ""},
+		{"Dentist in 3 days at 2pm", "Dentist", "2024-03-16 14:00", models.PriorityNone, "", ""},
+		{"Check oven in 45 min", "Check oven", "2024-03-13 11:15", models.PriorityNone, "", ""},
+		{"Call back in an hour", "Call back", "2024-03-13 11:30", models.PriorityNone, "", ""},
+		{"Call back in 26 hours", "Call back", "2024-03-14 12:30", models.PriorityNone, "", ""},
+
+		// Dates by number and month name
+		{"Launch 2024-04-01", "Launch", "2024-04-01 23:59", models.PriorityNone, "", ""},
+		{"Launch on 4/1", "Launch", "2024-04-01 23:59", models.PriorityNone, "", ""},
+		{"Launch by 3/13", "Launch", "2024-03-13 23:59", models.PriorityNone, "", ""},
+		{"Checkup due 3/1", "Checkup", "2025-03-01 23:59", models.PriorityNone, "", ""},
+		{"Gift due 12/25/2025", "Gift", "2025-12-25 23:59", models.PriorityNone, "", ""},
+		{"Gift by 12/25/25", "Gift", "2025-12-25 23:59", models.PriorityNone, "", ""},
+		{"Party on mar 20", "Party", "2024-03-20 23:59", models.PriorityNone, "", ""},
+		{"Party 20th March", "Party", "2024-03-20 23:59", models.PriorityNone, "", ""},
+		{"Party march 2nd 2025", "Party", "2025-03-02 23:59", models.PriorityNone, "", ""},
+		{"Party on Jan 5", "Party", "2025-01-05 23:59", models.PriorityNone, "", ""},
+		{"Party due may 3", "Party", "2024-05-03 23:59", models.PriorityNone, "", ""},
+		{"Party on 3 May", "Party", "2024-05-03 23:59", models.PriorityNone, "", ""},
+		{"Leap day on feb 29", "Leap day", "2028-02-29 23:59", models.PriorityNone, "", ""},
+
+		// Times of day
+		{"Lunch at noon", "Lunch", "2024-03-13 12:00", models.PriorityNone, "", ""},
+		{"Meeting 14:30", "Meeting", "2024-03-13 14:30", models.PriorityNone, "", ""},
+		{"Meeting 2:30 pm tomorrow", "Meeting", "2024-03-14 14:30", models.PriorityNone, "", ""},
+		{"Meeting @ 4pm", "Meeting", "2024-03-13 16:00", models.PriorityNone, "", ""},
+		{"Gym 7am", "Gym", "2024-03-14 07:00", models.PriorityNone, "", ""},
+		{"Meet at 9", "Meet", "2024-03-14 09:00", models.PriorityNone, "", ""},
+		{"Late 12am", "Late", "2024-03-14 00:00", models.PriorityNone, "", ""},
+		{"Lunch 12pm", "Lunch", "2024-03-13 12:00", models.PriorityNone, "", ""},
+		{"Email Bob tomorrow, 9am.", "Email Bob", "2024-03-14 09:00", models.PriorityNone, "", ""},
+
+		// Priorities and tags
+		{"Fix bug !1", "Fix bug", "", models.PriorityHigh, "", ""},
+		{"Fix bug !med", "Fix bug", "", models.PriorityMedium, "", ""},
+		{"Fix bug !L", "Fix bug", "", models.PriorityLow, "", ""},
+		{"Fix bug !high !low", "Fix bug !low", "", models.PriorityHigh, "", ""},
+		{"Read #books #Fun #BOOKS", "Read", "", models.PriorityNone, "books Fun", ""},
+		{"Fix #12 crash #work", "Fix #12 crash", "", models.PriorityNone, "work", ""},
+
+		// Recurrences, due today or on the weekday when no date is given
+		{"Water plants every day", "Water plants", "2024-03-13 23:59", models.PriorityNone, "", "every day"},
+		{"Stretch repeat daily 7am", "Stretch", "2024-03-14 07:00", models.PriorityNone, "", "every day"},
+		{"Trash every fri", "Trash", "2024-03-15 23:59", models.PriorityNone, "", "every week"},
+		{"Trash every fri 7pm", "Trash", "2024-03-15 19:00", models.PriorityNone, "", "every week"},
+		{"Review every 2 weeks", "Review", "2024-03-13 23:59", models.PriorityNone, "", "every 2 weeks"},
+		{"Clean every other day", "Clean", "2024-03-13 23:59", models.PriorityNone, "", "every 2 days"},
+		{"Pay rent repeat monthly on 4/1", "Pay rent", "2024-04-01 23:59", models.PriorityNone, "", "every month"},
+		{"Birthday every annually on mar 20", "Birthday", "2024-03-20 23:59", models.PriorityNone, "", "every year"},
+
+		// Words that only look like fields stay in the text
+		{"Buy 2 apples", "Buy 2 apples", "", models.PriorityNone, "", ""},
+		{"Meet at office", "Meet at office", "", models.PriorityNone, "", ""},
+		{"Work on site", "Work on site", "", models.PriorityNone, "", ""},
+		{"Read in bed", "Read in bed", "", models.PriorityNone, "", ""},
+		{"Stretch every 3 hours", "Stretch every 3 hours", "", models.PriorityNone, "", ""},
+		{"Stretch every 0 days", "Stretch every 0 days", "", models.PriorityNone, "", ""},
+		{"Fix feb 30", "Fix feb 30", "", models.PriorityNone, "", ""},
+		{"Fix 13/40", "Fix 13/40", "", models.PriorityNone, "", ""},
+		{"Alarm 13pm", "Alarm 13pm", "", models.PriorityNone, "", ""},
+		{"Alarm 9:75", "Alarm 9:75", "", models.PriorityNone, "", ""},
+		{"Next steps", "Next steps", "", models.PriorityNone, "", ""},
+		{"Tag #", "Tag #", "", models.PriorityNone, "", ""},
+		{"! important", "! important", "", models.PriorityNone, "", ""},
+		{"Send weekly newsletter", "Send weekly newsletter", "", models.PriorityNone, "", ""},
+		{"Daily standup notes", "Daily standup notes", "", models.PriorityNone, "", ""},
+		{"Wear sun hat", "Wear sun hat", "", models.PriorityNone, "", ""},
+		{"Sat nav update", "Sat nav update", "", models.PriorityNone, "", ""},
+		{"Wed anniversary gift", "Wed anniversary gift", "", models.PriorityNone, "", ""},
+		{"Ask if we may 3 guests", "Ask if we may 3 guests", "", models.PriorityNone, "", ""},
+		{"Don't mar 2 tables", "Don't mar 2 tables", "", models.PriorityNone, "", ""},
+		{"Read pages 3/4", "Read pages 3/4", "", models.PriorityNone, "", ""},
+
+		// A backslash keeps a word in the text
+		{`Call \tomorrow`, "Call tomorrow", "", models.PriorityNone, "", ""},
+		{`Send \weekly report every week`, "Send weekly report", "2024-03-13 23:59", models.PriorityNone, "", "every week"},
+		{`Buy \#home paint on \fri`, "Buy #home paint on fri", "", models.PriorityNone, "", ""},
+		{`Say \!high`, "Say !high", "", models.PriorityNone, "", ""},
+		{`Just \`, `Just \`, "", models.PriorityNone, "", ""},
+
+		// Each field is parsed once
+		{"Call today tomorrow", "Call tomorrow", "2024-03-13 23:59", models.PriorityNone, "", ""},
+		{"Call 9am 10am", "Call 10am", "2024-03-14 09:00", models.PriorityNone, "", ""},
+		{"Call every day repeat weekly", "Call repeat weekly", "2024-03-13 23:59", models.PriorityNone, "", "every day"},
+	}
+	for _, tt := range tests {
+		got := Parse(tt.input, now)
+		due := ""
+		if got.Due != nil {
+			due = got.Due.Format(models.DueFormat)
+		}
+		recurrence := ""
+		if got.Recurrence != nil {
+			recurrence = got.Recurrence.String()
+		}
+		tags := strings.Join(got.Tags, " ")
+		if got.Text != tt.text || due != tt.due || got.Priority != tt.priority || tags != tt.tags || recurrence != tt.recurrence {
+			t.Errorf("%q: expected %q due %q %v tags %q %q, got %q due %q %v tags %q %q", tt.input,
+				tt.text, tt.due, tt.priority, tt.tags, tt.recurrence,
+				got.Text, due, got.Priority, tags, recurrence)
+		}
+	}
+}
+
+func TestParseKeepsLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Time zone data not available: %v", err)
+	}
+
+	// March 10, 2024 had 23 hours there. A day later is a calendar day,
+	// while 24 hours later is an hour later on the clock.
+	now := time.Date(2024, time.March, 9, 12, 0, 0, 0, loc)
+	if got := Parse("Call tomorrow 12pm", now).Due; got == nil || !got.Equal(time.Date(2024, time.March, 10, 12, 0, 0, 0, loc)) {
+		t.Errorf("Expected noon on March 10, got %v", got)
+	}
+	if got := Parse("Call in 24 hours", now).Due; got == nil || got.Hour() != 13 || got.Location() != loc {
+		t.Errorf("Expected 1pm in New York, got %v", got)
+	}
+}
+
+func TestHasFields(t *testing.T) {
+	if Parse("Buy milk", now).HasFields() {
+		t.Error("Expected plain text to have no fields")
+	}
+	for _, input := range []string{"Buy milk today", "Buy milk !low", "Buy milk #shop", "Buy milk repeat weekly"} {
+		if !Parse(input, now).HasFields() {
+			t.Errorf("Expected %q to have fields", input)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Package headless renders Ebiten drawing into images for snapshot tests.
+// Ebiten can only read pixels back while its game loop runs, so a test
+// package calls Main from TestMain to run its tests inside the loop. On a
+// Linux box without a GPU run the tests under Xvfb with Mesa's software
+// renderer, for example:
+//
+//	LIBGL_ALWAYS_SOFTWARE=1 xvfb-run -a go test -tags snapshot ./internal/...
+package headless
+
+import (
+	"image"
+	"os"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// runner is a game that runs the tests on its first update and then ends.
+type runner struct {
+	m    *testing.M
+	code int
+}
+
+func (r *runner) Update() error {
+	r.code = r.m.Run()
+	return ebiten.Termination
+}
+
+func (r *runner) Draw(*ebiten.Image) {}
+
+func (r *runner) Layout(int, int) (int, int) { return 1, 1 }
+
+// Main runs the tests of m inside the Ebiten game loop and exits with
+// their result.
+func Main(m *testing.M) {
+	r := &runner{m: m}
+	ebiten.SetWindowSize(1, 1)
+	ebiten.SetWindowTitle("snapshot tests")
+	err := ebiten.RunGameWithOptions(r, &ebiten.RunGameOptions{InitUnfocused: true, SkipTaskbar: true})
+	if err != nil {
+		panic(err)
+	}
+	os.Exit(r.code)
+}
+
+// Render calls draw on a width by height image and returns its pixels.
+func Render(width, height int, draw func(screen *ebiten.Image)) *image.RGBA {
+	screen := ebiten.NewImage(width, height)
+	defer screen.Deallocate()
+	draw(screen)
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	screen.ReadPixels(img.Pix)
+	return img
+}
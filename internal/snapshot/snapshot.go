@@ -0,0 +1,181 @@
+// Package snapshot compares rendered images against golden PNGs committed
+// next to the tests. Two pixels match when every channel is within a
+// threshold of each other, and an image matches when few enough pixels
+// differ, which absorbs the rounding differences between graphics drivers.
+// Run the tests with -update to write the goldens from the current output.
+// When an image does not match, the output and a diff image are written
+// to the failed directory so they can be inspected or kept as CI
+// artifacts.
+package snapshot
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "write golden images from the current output")
+
+var (
+	// GoldenDir is where golden images are read from and updated.
+	GoldenDir = filepath.Join("testdata", "golden")
+	// FailedDir is where the output and diff of failed matches go.
+	FailedDir = filepath.Join("testdata", "failed")
+)
+
+// Options says how close an image must be to its golden.
+type Options struct {
+	// Threshold is how far a color channel may be off, out of 255,
+	// before the pixel counts as different.
+	Threshold uint8
+	// MaxDiff is the fraction of pixels that may differ.
+	MaxDiff float64
+}
+
+// DefaultOptions allow small rounding differences in color, but no
+// pixels that are clearly wrong.
+var DefaultOptions = Options{Threshold: 8, MaxDiff: 0}
+
+// Result is the outcome of comparing two images of the same size.
+type Result struct {
+	Differing int // Pixels with a channel beyond the threshold
+	Total     int
+	// Diff shows the expected image faded, with differing pixels in red.
+	Diff *image.RGBA
+}
+
+// Fraction returns the fraction of pixels that differ.
+func (r Result) Fraction() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	return float64(r.Differing) / float64(r.Total)
+}
+
+// Compare compares got with want pixel by pixel.
+func Compare(got, want image.Image, opts Options) (Result, error) {
+	size := got.Bounds().Size()
+	if want.Bounds().Size() != size {
+		return Result{}, fmt.Errorf("size %v does not match the golden size %v", size, want.Bounds().Size())
+	}
+
+	result := Result{Total: size.X * size.Y, Diff: image.NewRGBA(image.Rect(0, 0, size.X, size.Y))}
+	g, w := toRGBA(got), toRGBA(want)
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			a, b := g.RGBAAt(x, y), w.RGBAAt(x, y)
+			if differs(a, b, opts.Threshold) {
+				result.Differing++
+				result.Diff.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+				continue
+			}
+			result.Diff.SetRGBA(x, y, faded(b))
+		}
+	}
+	return result, nil
+}
+
+func differs(a, b color.RGBA, threshold uint8) bool {
+	return channelDiff(a.R, b.R) > threshold || channelDiff(a.G, b.G) > threshold ||
+		channelDiff(a.B, b.B) > threshold || channelDiff(a.A, b.A) > threshold
+}
+
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// faded returns c as a light gray, so that the red of differing pixels
+// stands out against the expected image.
+func faded(c color.RGBA) color.RGBA {
+	gray := (uint16(c.R) + uint16(c.G) + uint16(c.B)) / 3
+	v := uint8(192 + gray/4)
+	return color.RGBA{R: v, G: v, B: v, A: 255}
+}
+
+// toRGBA returns img as an RGBA image at the origin.
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Rect, img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// Match compares got with the golden image called name, failing t when
+// they differ by more than opts allow. With -update it writes the golden
+// instead.
+func Match(t testing.TB, name string, got image.Image, opts Options) {
+	t.Helper()
+	golden := filepath.Join(GoldenDir, name+".png")
+	if *update {
+		if err := writePNG(golden, got); err != nil {
+			t.Fatalf("Failed to update golden image: %v", err)
+		}
+		t.Logf("Updated %s", golden)
+		return
+	}
+
+	want, err := readPNG(golden)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("No golden image %s; run the tests with -update to create it", golden)
+	}
+	if err != nil {
+		t.Fatalf("Failed to read golden image: %v", err)
+	}
+
+	result, compareErr := Compare(got, want, opts)
+	if compareErr == nil && result.Fraction() <= opts.MaxDiff {
+		return
+	}
+	failed := filepath.Join(FailedDir, name+".png")
+	if err := writePNG(failed, got); err != nil {
+		t.Errorf("Failed to write the output: %v", err)
+	}
+	if compareErr != nil {
+		t.Errorf("%s: %v; output written to %s", name, compareErr, failed)
+		return
+	}
+	diff := filepath.Join(FailedDir, name+".diff.png")
+	if err := writePNG(diff, result.Diff); err != nil {
+		t.Errorf("Failed to write the diff: %v", err)
+	}
+	t.Errorf("%s: %d of %d pixels differ from the golden; output written to %s and diff to %s",
+		name, result.Differing, result.Total, failed, diff)
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,124 @@
+package snapshot
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func filled(width, height int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestCompare(t *testing.T) {
+	gray := color.RGBA{R: 100, G: 100, B: 100, A: 255}
+	want := filled(10, 10, gray)
+
+	// Small differences in color are within the threshold
+	got := filled(10, 10, color.RGBA{R: 104, G: 96, B: 100, A: 255})
+	got.SetRGBA(3, 4, color.RGBA{R: 255, A: 255})
+	got.SetRGBA(5, 6, color.RGBA{R: 100, G: 100, B: 109, A: 255})
+	result, err := Compare(got, want, Options{Threshold: 8})
+	if err != nil {
+		t.Fatalf("Failed to compare: %v", err)
+	}
+	if result.Differing != 2 || result.Total != 100 || result.Fraction() != 0.02 {
+		t.Errorf("Expected 2 of 100 pixels to differ, got %d of %d", result.Differing, result.Total)
+	}
+	if c := result.Diff.RGBAAt(3, 4); c != (color.RGBA{R: 255, A: 255}) {
+		t.Errorf("Expected a differing pixel to be red in the diff, got %v", c)
+	}
+	if c := result.Diff.RGBAAt(0, 0); c.R != c.G || c.G != c.B || c.R < 192 {
+		t.Errorf("Expected a matching pixel to be light gray in the diff, got %v", c)
+	}
+
+	// Images are compared from their own origins
+	moved := image.NewRGBA(image.Rect(5, 5, 15, 15))
+	copy(moved.Pix, want.Pix)
+	if result, err := Compare(moved, want, DefaultOptions); err != nil || result.Differing != 0 {
+		t.Errorf("Expected an offset copy to match, got %d differing, err %v", result.Differing, err)
+	}
+
+	if _, err := Compare(filled(10, 9, gray), want, DefaultOptions); err == nil {
+		t.Error("Expected an error for images of different sizes")
+	}
+}
+
+// fakeTB records the failures of Match. Fatalf ends the goroutine like it
+// does in a test.
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeTB) Helper()                        {}
+func (f *fakeTB) Logf(string, ...any)            {}
+func (f *fakeTB) Errorf(format string, _ ...any) { f.errors = append(f.errors, format) }
+
+func (f *fakeTB) Fatalf(format string, args ...any) {
+	f.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+func match(name string, img image.Image) []string {
+	tb := &fakeTB{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Match(tb, name, img, DefaultOptions)
+	}()
+	<-done
+	return tb.errors
+}
+
+func TestMatch(t *testing.T) {
+	dir := t.TempDir()
+	GoldenDir, FailedDir = filepath.Join(dir, "golden"), filepath.Join(dir, "failed")
+	defer func() {
+		GoldenDir, FailedDir = filepath.Join("testdata", "golden"), filepath.Join("testdata", "failed")
+	}()
+	white := filled(4, 4, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+
+	if errs := match("button", white); len(errs) != 1 || !strings.Contains(errs[0], "-update") {
+		t.Fatalf("Expected a missing golden to ask for -update, got %v", errs)
+	}
+
+	*update = true
+	errs := match("button", white)
+	*update = false
+	if len(errs) != 0 {
+		t.Fatalf("Expected -update to write the golden, got %v", errs)
+	}
+	if _, err := os.Stat(filepath.Join(GoldenDir, "button.png")); err != nil {
+		t.Fatalf("Expected the golden to be written: %v", err)
+	}
+
+	if errs := match("button", white); len(errs) != 0 {
+		t.Errorf("Expected the image to match its golden, got %v", errs)
+	}
+	if _, err := os.Stat(FailedDir); !os.IsNotExist(err) {
+		t.Error("Expected nothing to be written when the image matches")
+	}
+
+	black := filled(4, 4, color.RGBA{A: 255})
+	if errs := match("button", black); len(errs) != 1 {
+		t.Errorf("Expected a different image to fail, got %v", errs)
+	}
+	for _, name := range []string{"button.png", "button.diff.png"} {
+		if _, err := os.Stat(filepath.Join(FailedDir, name)); err != nil {
+			t.Errorf("Expected %s to be written on failure: %v", name, err)
+		}
+	}
+
+	if errs := match("button", filled(4, 5, color.RGBA{A: 255})); len(errs) != 1 {
+		t.Errorf("Expected an image of another size to fail, got %v", errs)
+	}
+}
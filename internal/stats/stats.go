@@ -0,0 +1,174 @@
+// Package stats summarizes todos for the dashboard: how many are open,
+// done and overdue, completions per day and per week, open and done
+// counts over time, how long todos take to complete, and streaks of days
+// with a completion. Days are calendar days in the location of the time
+// passed as now, so they follow daylight saving changes.
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+const (
+	// Days is how many days the daily charts cover, ending today.
+	Days = 14
+	// Weeks is how many weeks the weekly chart covers, ending this week.
+	Weeks = 8
+)
+
+// Bucket counts the completions in the day or week starting at Start.
+type Bucket struct {
+	Start time.Time
+	Count int
+}
+
+// Point is how many todos were open and done at the end of Day.
+type Point struct {
+	Day  time.Time
+	Open int
+	Done int
+}
+
+// Summary is the aggregated view of a set of todos.
+type Summary struct {
+	Open    int
+	Done    int
+	Overdue int
+	// AverageTimeToComplete is the mean time from creation to completion
+	// of the completed todos, or zero when there are none.
+	AverageTimeToComplete time.Duration
+	// PerDay and PerWeek count completions, oldest first. Weeks start on
+	// Monday.
+	PerDay  []Bucket
+	PerWeek []Bucket
+	// OverTime has the open and done counts for each day of PerDay.
+	OverTime []Point
+	// CurrentStreak is the number of days in a row with a completion,
+	// ending today, or yesterday while nothing has been completed today.
+	CurrentStreak int
+	LongestStreak int
+}
+
+// Compute summarizes todos as of now. Completions only count in the
+// charts, the average and the streaks when their time was recorded.
+func Compute(todos []models.Todo, now time.Time) Summary {
+	loc := now.Location()
+	today := startOfDay(now)
+	s := Summary{
+		PerDay:   make([]Bucket, Days),
+		PerWeek:  make([]Bucket, Weeks),
+		OverTime: make([]Point, Days),
+	}
+	for i := range s.PerDay {
+		day := today.AddDate(0, 0, i-Days+1)
+		s.PerDay[i].Start = day
+		s.OverTime[i].Day = day
+	}
+	thisWeek := startOfWeek(today)
+	for i := range s.PerWeek {
+		s.PerWeek[i].Start = thisWeek.AddDate(0, 0, 7*(i-Weeks+1))
+	}
+
+	var total time.Duration
+	var timed int
+	completionDays := make(map[time.Time]bool)
+	for i := range todos {
+		todo := &todos[i]
+		if todo.Completed {
+			s.Done++
+		} else {
+			s.Open++
+		}
+		if todo.IsOverdue(now) {
+			s.Overdue++
+		}
+
+		// Open and done counts at the end of each day
+		created := todo.CreatedAt.In(loc)
+		completed := todo.CompletionTime().In(loc)
+		for j := range s.OverTime {
+			end := s.OverTime[j].Day.AddDate(0, 0, 1)
+			switch {
+			case !created.Before(end):
+			case todo.Completed && completed.Before(end):
+				s.OverTime[j].Done++
+			default:
+				s.OverTime[j].Open++
+			}
+		}
+
+		if !todo.Completed || todo.CompletedAt == nil {
+			continue
+		}
+		at := todo.CompletedAt.In(loc)
+		if d := at.Sub(created); d >= 0 {
+			total += d
+			timed++
+		}
+		day := startOfDay(at)
+		completionDays[day] = true
+		if i := daysBetween(s.PerDay[0].Start, day); i >= 0 && i < Days {
+			s.PerDay[i].Count++
+		}
+		if i := daysBetween(s.PerWeek[0].Start, startOfWeek(day)) / 7; i >= 0 && i < Weeks {
+			s.PerWeek[i].Count++
+		}
+	}
+	if timed > 0 {
+		s.AverageTimeToComplete = total / time.Duration(timed)
+	}
+	s.CurrentStreak, s.LongestStreak = streaks(completionDays, today)
+	return s
+}
+
+// streaks returns the current and the longest run of consecutive days in
+// days.
+func streaks(days map[time.Time]bool, today time.Time) (current, longest int) {
+	day := today
+	if !days[day] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for days[day] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	sorted := make([]time.Time, 0, len(days))
+	for day := range days {
+		sorted = append(sorted, day)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+	run := 0
+	for i, day := range sorted {
+		if i > 0 && sorted[i-1].AddDate(0, 0, 1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+	return current, longest
+}
+
+// startOfDay returns midnight at the start of t's day in t's location.
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek returns the Monday starting the week of day.
+func startOfWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// daysBetween returns how many calendar days day is after start, negative
+// when it is before. Both must be the start of a day.
+func daysBetween(start, day time.Time) int {
+	// Round to absorb days made shorter or longer by daylight saving
+	return int(math.Round(day.Sub(start).Hours() / 24))
+}
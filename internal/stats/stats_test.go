@@ -0,0 +1,136 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// todo returns a todo created at created, completed at completed unless
+// it is zero.
+func todo(created, completed time.Time) models.Todo {
+	t := models.Todo{CreatedAt: created}
+	if !completed.IsZero() {
+		t.Completed = true
+		t.CompletedAt = &completed
+	}
+	return t
+}
+
+func TestComputeCounts(t *testing.T) {
+	now := time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC) // A Wednesday
+	due := now.Add(-time.Hour)
+	overdue := todo(now.AddDate(0, 0, -3), time.Time{})
+	overdue.Due = &due
+	doneLate := todo(now.AddDate(0, 0, -3), now.Add(-2*time.Hour))
+	doneLate.Due = &due
+
+	todos := []models.Todo{
+		overdue,
+		doneLate,
+		todo(now.Add(-5*time.Hour), now.Add(-4*time.Hour)),
+		todo(now.Add(-time.Hour), time.Time{}),
+		// Completed before completion times were recorded
+		{CreatedAt: now.AddDate(0, 0, -20), Completed: true},
+	}
+	s := Compute(todos, now)
+
+	if s.Open != 2 || s.Done != 3 || s.Overdue != 1 {
+		t.Errorf("Expected 2 open, 3 done and 1 overdue, got %d, %d and %d", s.Open, s.Done, s.Overdue)
+	}
+	want := (3*24*time.Hour - 2*time.Hour + time.Hour) / 2
+	if s.AverageTimeToComplete != want {
+		t.Errorf("Expected an average of %v, got %v", want, s.AverageTimeToComplete)
+	}
+	if len(s.PerDay) != Days || len(s.PerWeek) != Weeks || len(s.OverTime) != Days {
+		t.Fatalf("Expected %d days and %d weeks, got %d, %d and %d", Days, Weeks, len(s.PerDay), len(s.PerWeek), len(s.OverTime))
+	}
+	if last := s.PerDay[Days-1]; !last.Start.Equal(time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC)) || last.Count != 2 {
+		t.Errorf("Expected 2 completions today, got %+v", last)
+	}
+	if last := s.PerWeek[Weeks-1]; !last.Start.Equal(time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)) || last.Count != 2 {
+		t.Errorf("Expected 2 completions in the week starting Monday, got %+v", last)
+	}
+
+	// Three days ago the overdue and the late todo were open, and the todo
+	// without a completion time counts as done since it was created
+	if p := s.OverTime[Days-4]; p.Open != 2 || p.Done != 1 {
+		t.Errorf("Expected 2 open and 1 done three days ago, got %+v", p)
+	}
+	if p := s.OverTime[Days-1]; p.Open != 2 || p.Done != 3 {
+		t.Errorf("Expected 2 open and 3 done today, got %+v", p)
+	}
+}
+
+func TestComputeWeeks(t *testing.T) {
+	now := time.Date(2024, 3, 17, 23, 0, 0, 0, time.UTC) // A Sunday
+	todos := []models.Todo{
+		todo(now.AddDate(0, 0, -30), time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)),   // Monday this week
+		todo(now.AddDate(0, 0, -30), time.Date(2024, 3, 10, 23, 59, 0, 0, time.UTC)), // Sunday last week
+		todo(now.AddDate(0, 0, -90), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),    // Too old
+	}
+	s := Compute(todos, now)
+	counts := make([]int, Weeks)
+	for i, b := range s.PerWeek {
+		counts[i] = b.Count
+		if b.Start.Weekday() != time.Monday {
+			t.Errorf("Expected weeks to start on Monday, got %v", b.Start)
+		}
+	}
+	if counts[Weeks-1] != 1 || counts[Weeks-2] != 1 {
+		t.Errorf("Expected one completion this week and one last week, got %v", counts)
+	}
+}
+
+func TestComputeStreaks(t *testing.T) {
+	now := time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC)
+	day := func(daysAgo int) time.Time { return now.AddDate(0, 0, -daysAgo) }
+	tests := []struct {
+		name             string
+		daysAgo          []int
+		current, longest int
+	}{
+		{"none", nil, 0, 0},
+		{"today", []int{0}, 1, 1},
+		{"up to yesterday", []int{1, 2, 3}, 3, 3},
+		{"broken", []int{2, 3}, 0, 2},
+		{"longest earlier", []int{0, 1, 5, 6, 7, 8}, 2, 4},
+		{"several a day", []int{0, 0, 1}, 2, 2},
+	}
+	for _, tt := range tests {
+		var todos []models.Todo
+		for _, n := range tt.daysAgo {
+			todos = append(todos, todo(day(30), day(n)))
+		}
+		s := Compute(todos, now)
+		if s.CurrentStreak != tt.current || s.LongestStreak != tt.longest {
+			t.Errorf("%s: expected streaks %d and %d, got %d and %d", tt.name, tt.current, tt.longest, s.CurrentStreak, s.LongestStreak)
+		}
+	}
+}
+
+func TestComputeAcrossDaylightSaving(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Time zone data not available: %v", err)
+	}
+	// Clocks went forward on 2024-03-10, so that day had 23 hours
+	now := time.Date(2024, 3, 11, 0, 30, 0, 0, loc)
+	todos := []models.Todo{
+		todo(now.AddDate(0, 0, -5), time.Date(2024, 3, 10, 23, 30, 0, 0, loc)),
+		todo(now.AddDate(0, 0, -5), time.Date(2024, 3, 9, 0, 30, 0, 0, loc)),
+	}
+	s := Compute(todos, now)
+	if s.PerDay[Days-2].Count != 1 || s.PerDay[Days-3].Count != 1 || s.PerDay[Days-1].Count != 0 {
+		t.Errorf("Expected completions on March 9 and 10, got %+v", s.PerDay[Days-3:])
+	}
+	if s.CurrentStreak != 2 || s.LongestStreak != 2 {
+		t.Errorf("Expected the days either side of the change to form a streak up to yesterday, got %d and %d", s.CurrentStreak, s.LongestStreak)
+	}
+	for _, b := range s.PerDay {
+		if b.Start.Hour() != 0 {
+			t.Errorf("Expected days to start at midnight, got %v", b.Start)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// AsyncStorage saves todos in the background so that a slow disk does not
+// hold up the caller. A save is written once saves pause for the delay,
+// and at most the max delay after the first unwritten save, so a burst of
+// changes is written once with the latest todos. Failed background writes
+// are sent to Errors and kept to be tried again.
+//
+// Flush writes a pending save right away, and Close must be called before
+// exiting. AsyncStorage is safe to use from several goroutines.
+type AsyncStorage struct {
+	store    Storage
+	clock    models.TimerClock // Times the delays and runs the writes
+	delay    time.Duration
+	maxDelay time.Duration
+	errs     chan error
+
+	mu      sync.Mutex
+	pending []models.Todo
+	dirty   bool
+	first   time.Time // When the pending save was first requested
+	timer   models.Timer
+	closed  bool
+
+	// writeMu keeps one write at a time, so that writes land in the order
+	// their saves were requested.
+	writeMu sync.Mutex
+}
+
+// NewAsyncStorage returns an AsyncStorage that writes to store on the
+// timers of clock.
+func NewAsyncStorage(store Storage, clock models.TimerClock, delay, maxDelay time.Duration) *AsyncStorage {
+	return &AsyncStorage{
+		store:    store,
+		clock:    clock,
+		delay:    delay,
+		maxDelay: maxDelay,
+		errs:     make(chan error, 1),
+	}
+}
+
+// SaveTodos schedules todos to be written. The slice is copied, so the
+// caller may change it afterwards; todos replace what their fields point to
+// rather than changing it, so the copy keeps its values. Once the storage
+// is closed, todos are written right away.
+func (a *AsyncStorage) SaveTodos(todos []models.Todo) error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		a.writeMu.Lock()
+		defer a.writeMu.Unlock()
+		return a.store.SaveTodos(todos)
+	}
+	defer a.mu.Unlock()
+
+	now := a.clock.Now()
+	a.pending = slices.Clone(todos)
+	if !a.dirty {
+		a.dirty = true
+		a.first = now
+	}
+	wait := min(a.delay, a.first.Add(a.maxDelay).Sub(now))
+	if a.timer == nil {
+		a.timer = a.clock.AfterFunc(wait, a.writePending)
+	} else {
+		a.timer.Reset(wait)
+	}
+	return nil
+}
+
+// writePending writes the pending save in the background.
+func (a *AsyncStorage) writePending() {
+	if err := a.write(); err != nil {
+		// Keep the first failure until it is read rather than blocking
+		select {
+		case a.errs <- err:
+		default:
+		}
+	}
+}
+
+// write writes the pending save, if there is one. A save that fails stays
+// pending, unless a newer one has replaced it, so that the next Flush or
+// Close tries it again.
+func (a *AsyncStorage) write() error {
+	a.writeMu.Lock()
+	defer a.writeMu.Unlock()
+
+	a.mu.Lock()
+	todos, dirty, first := a.pending, a.dirty, a.first
+	a.pending, a.dirty = nil, false
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+	a.mu.Unlock()
+
+	if !dirty {
+		return nil
+	}
+	err := a.store.SaveTodos(todos)
+	if err != nil {
+		a.mu.Lock()
+		if !a.dirty {
+			a.pending, a.dirty, a.first = todos, true, first
+		}
+		a.mu.Unlock()
+	}
+	return err
+}
+
+// Flush writes the pending save now and waits for it.
+func (a *AsyncStorage) Flush() error {
+	return a.write()
+}
+
+// Close writes the pending save. Later saves are written right away.
+func (a *AsyncStorage) Close() error {
+	a.mu.Lock()
+	a.closed = true
+	a.mu.Unlock()
+	return a.write()
+}
+
+// Errors receives failed background writes. Writes that fail while an
+// error is unread are dropped.
+func (a *AsyncStorage) Errors() <-chan error {
+	return a.errs
+}
+
+// Pending reports whether a save is waiting to be written.
+func (a *AsyncStorage) Pending() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.dirty
+}
+
+// LoadTodos writes the pending save and loads the todos back.
+func (a *AsyncStorage) LoadTodos() ([]models.Todo, error) {
+	if err := a.write(); err != nil {
+		return nil, err
+	}
+	return a.store.LoadTodos()
+}
+
+// ClearTodos drops the pending save and clears the todos.
+func (a *AsyncStorage) ClearTodos() error {
+	a.writeMu.Lock()
+	defer a.writeMu.Unlock()
+
+	a.mu.Lock()
+	a.pending, a.dirty = nil, false
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+	a.mu.Unlock()
+	return a.store.ClearTodos()
+}
@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// testNow is the time the tests' clocks start at.
+var testNow = time.Date(2024, time.March, 13, 10, 30, 0, 0, time.UTC)
+
+// recordingStorage keeps the todos written to it.
+type recordingStorage struct {
+	mu     sync.Mutex
+	writes [][]models.Todo
+	fail   error
+}
+
+func newRecordingStorage() *recordingStorage {
+	return &recordingStorage{}
+}
+
+func (s *recordingStorage) SaveTodos(todos []models.Todo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail != nil {
+		return s.fail
+	}
+	s.writes = append(s.writes, todos)
+	return nil
+}
+
+func (s *recordingStorage) LoadTodos() ([]models.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.writes) == 0 {
+		return nil, nil
+	}
+	return s.writes[len(s.writes)-1], nil
+}
+
+func (s *recordingStorage) ClearTodos() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes = append(s.writes, nil)
+	return nil
+}
+
+func (s *recordingStorage) texts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var texts []string
+	for _, todos := range s.writes {
+		text := ""
+		for _, todo := range todos {
+			text += todo.Text
+		}
+		texts = append(texts, text)
+	}
+	return texts
+}
+
+func todosWithText(texts ...string) []models.Todo {
+	todos := make([]models.Todo, len(texts))
+	for i, text := range texts {
+		todos[i] = models.Todo{ID: fmt.Sprint(i), Text: text}
+	}
+	return todos
+}
+
+func TestAsyncStorageCoalescesSaves(t *testing.T) {
+	store := newRecordingStorage()
+	async := NewAsyncStorage(store, models.NewFakeClock(testNow), time.Hour, time.Hour)
+
+	for _, text := range []string{"a", "b", "c"} {
+		if err := async.SaveTodos(todosWithText(text)); err != nil {
+			t.Fatalf("Failed to save: %v", err)
+		}
+	}
+	if !async.Pending() || len(store.texts()) != 0 {
+		t.Fatal("Expected the saves to wait for the delay")
+	}
+	if err := async.Flush(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+	if err := async.Flush(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+	if got := store.texts(); len(got) != 1 || got[0] != "c" {
+		t.Errorf("Expected one write of the latest todos, got %v", got)
+	}
+	if async.Pending() {
+		t.Error("Expected nothing pending after a flush")
+	}
+}
+
+func TestAsyncStorageWritesAfterDelay(t *testing.T) {
+	store := newRecordingStorage()
+	clock := models.NewFakeClock(testNow)
+	async := NewAsyncStorage(store, clock, 10*time.Millisecond, time.Hour)
+
+	async.SaveTodos(todosWithText("a"))
+	clock.Advance(5 * time.Millisecond)
+	async.SaveTodos(todosWithText("b"))
+	clock.Advance(9 * time.Millisecond)
+	if got := store.texts(); len(got) != 0 {
+		t.Fatalf("Expected the delay to restart with each save, got %v", got)
+	}
+	clock.Advance(time.Millisecond)
+	if got := store.texts(); len(got) != 1 || got[0] != "b" {
+		t.Errorf("Expected the burst to be written once, got %v", got)
+	}
+}
+
+func TestAsyncStorageMaxDelay(t *testing.T) {
+	store := newRecordingStorage()
+	clock := models.NewFakeClock(testNow)
+	async := NewAsyncStorage(store, clock, 10*time.Millisecond, 50*time.Millisecond)
+
+	// Saves that keep coming within the delay are written by the max delay
+	for i := 0; i < 10; i++ {
+		if got := store.texts(); len(got) != 0 {
+			t.Fatalf("Expected no write before the max delay, got %v after %v", got, time.Duration(i)*5*time.Millisecond)
+		}
+		async.SaveTodos(todosWithText(fmt.Sprint(i)))
+		clock.Advance(5 * time.Millisecond)
+	}
+	if got := store.texts(); len(got) != 1 || got[0] != "9" {
+		t.Errorf("Expected the latest todos written at the max delay, got %v", got)
+	}
+}
+
+func TestAsyncStorageCopiesTodos(t *testing.T) {
+	store := newRecordingStorage()
+	clock := models.NewFakeClock(testNow)
+	async := NewAsyncStorage(store, clock, time.Millisecond, time.Millisecond)
+
+	// The caller keeps changing its todos before they are written
+	todos := todosWithText("a")
+	async.SaveTodos(todos)
+	todos[0].Text = "b"
+	clock.Advance(time.Millisecond)
+	if got := store.texts(); len(got) != 1 || got[0] != "a" {
+		t.Errorf("Expected the todos as they were saved, got %v", got)
+	}
+}
+
+func TestAsyncStorageReportsErrors(t *testing.T) {
+	store := newRecordingStorage()
+	store.fail = errors.New("disk full")
+	clock := models.NewFakeClock(testNow)
+	async := NewAsyncStorage(store, clock, time.Millisecond, time.Millisecond)
+
+	async.SaveTodos(todosWithText("a"))
+	clock.Advance(time.Millisecond)
+	select {
+	case err := <-async.Errors():
+		if !errors.Is(err, store.fail) {
+			t.Errorf("Expected the write error, got %v", err)
+		}
+	default:
+		t.Fatal("Expected a background write error")
+	}
+
+	async.SaveTodos(todosWithText("b"))
+	if err := async.Flush(); !errors.Is(err, store.fail) {
+		t.Errorf("Expected Flush to return the write error, got %v", err)
+	}
+
+	// The failed save is kept until a write succeeds
+	if !async.Pending() {
+		t.Fatal("Expected the failed save to stay pending")
+	}
+	store.mu.Lock()
+	store.fail = nil
+	store.mu.Unlock()
+	if err := async.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+	if got := store.texts(); len(got) != 1 || got[0] != "b" {
+		t.Errorf("Expected Close to write the failed save, got %v", got)
+	}
+}
+
+func TestAsyncStorageCloseFlushes(t *testing.T) {
+	store := newRecordingStorage()
+	async := NewAsyncStorage(store, models.NewFakeClock(testNow), time.Hour, time.Hour)
+
+	async.SaveTodos(todosWithText("a"))
+	if err := async.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+	if got := store.texts(); len(got) != 1 || got[0] != "a" {
+		t.Fatalf("Expected Close to write the pending save, got %v", got)
+	}
+
+	// Saves after closing are written right away
+	async.SaveTodos(todosWithText("b"))
+	if got := store.texts(); len(got) != 2 || got[1] != "b" || async.Pending() {
+		t.Errorf("Expected a save after Close to be written at once, got %v", got)
+	}
+}
+
+func TestAsyncStorageLoadAndClear(t *testing.T) {
+	store := newRecordingStorage()
+	async := NewAsyncStorage(store, models.NewFakeClock(testNow), time.Hour, time.Hour)
+
+	async.SaveTodos(todosWithText("a"))
+	todos, err := async.LoadTodos()
+	if err != nil || len(todos) != 1 || todos[0].Text != "a" {
+		t.Fatalf("Expected LoadTodos to see the pending save, got %v, %v", todos, err)
+	}
+
+	// A pending save does not bring cleared todos back
+	async.SaveTodos(todosWithText("b"))
+	if err := async.ClearTodos(); err != nil {
+		t.Fatalf("Failed to clear: %v", err)
+	}
+	async.Flush()
+	if got := store.texts(); len(got) != 2 || got[1] != "" {
+		t.Errorf("Expected the clear to be the last write, got %v", got)
+	}
+}
+
+func TestAsyncStorageConcurrentSaves(t *testing.T) {
+	store := newRecordingStorage()
+	// Real timers write in the background while the saves come in
+	async := NewAsyncStorage(store, models.SystemClock, time.Microsecond, time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				async.SaveTodos(todosWithText(fmt.Sprint(i, j)))
+				if j%10 == 0 {
+					async.Flush()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	async.SaveTodos(todosWithText("last"))
+	if err := async.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+	if got := store.texts(); len(got) == 0 || got[len(got)-1] != "last" {
+		t.Errorf("Expected the last save to be written last, got %d writes", len(got))
+	}
+}
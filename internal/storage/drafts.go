@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// Drafts are the edits still open when the app quit, kept in a JSON file
+// next to the todos so that they can be picked up on the next launch.
+type Drafts struct {
+	// Input is the text typed into the new todo box.
+	Input string      `json:"input,omitempty"`
+	Todos []TodoDraft `json:"todos,omitempty"`
+}
+
+// TodoDraft is an unfinished edit of a todo. Text and Notes are nil when
+// that part is not being edited.
+type TodoDraft struct {
+	ID    string  `json:"id"`
+	Text  *string `json:"text,omitempty"`
+	Notes *string `json:"notes,omitempty"`
+}
+
+// IsEmpty reports whether there is nothing to keep.
+func (d Drafts) IsEmpty() bool {
+	return d.Input == "" && len(d.Todos) == 0
+}
+
+// LoadDrafts reads the drafts at path. A missing file yields no drafts.
+func LoadDrafts(path string) (Drafts, error) {
+	var drafts Drafts
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return drafts, nil
+	}
+	if err != nil {
+		return drafts, &models.AppError{
+			Type:    models.ErrorStorage,
+			Message: "Failed to read drafts file",
+			Err:     err,
+		}
+	}
+
+	if err := json.Unmarshal(data, &drafts); err != nil {
+		return Drafts{}, &models.AppError{
+			Type:    models.ErrorStorage,
+			Message: "Failed to parse drafts from JSON",
+			Err:     err,
+		}
+	}
+	return drafts, nil
+}
+
+// SaveDrafts writes drafts to path, or removes the file when there are
+// none.
+func SaveDrafts(path string, drafts Drafts) error {
+	if drafts.IsEmpty() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return &models.AppError{
+				Type:    models.ErrorStorage,
+				Message: "Failed to remove drafts file",
+				Err:     err,
+			}
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(drafts, "", "  ")
+	if err != nil {
+		return &models.AppError{
+			Type:    models.ErrorStorage,
+			Message: "Failed to marshal drafts to JSON",
+			Err:     err,
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return &models.AppError{
+			Type:    models.ErrorStorage,
+			Message: "Failed to create data directory",
+			Err:     err,
+		}
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
+		return &models.AppError{
+			Type:    models.ErrorStorage,
+			Message: "Failed to write drafts to file",
+			Err:     err,
+		}
+	}
+	return nil
+}
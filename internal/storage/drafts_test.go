@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDraftsSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "drafts.json")
+
+	// A missing file yields no drafts
+	drafts, err := LoadDrafts(path)
+	if err != nil {
+		t.Fatalf("Failed to load missing drafts: %v", err)
+	}
+	if !drafts.IsEmpty() {
+		t.Errorf("Expected no drafts, got %+v", drafts)
+	}
+
+	text, notes := "Buy oat milk", ""
+	want := Drafts{
+		Input: "Call the",
+		Todos: []TodoDraft{{ID: "1", Text: &text}, {ID: "2", Notes: &notes}},
+	}
+	if err := SaveDrafts(path, want); err != nil {
+		t.Fatalf("Failed to save drafts: %v", err)
+	}
+	drafts, err = LoadDrafts(path)
+	if err != nil {
+		t.Fatalf("Failed to load drafts: %v", err)
+	}
+	if !reflect.DeepEqual(drafts, want) {
+		t.Errorf("Expected the drafts to survive reload, got %+v", drafts)
+	}
+
+	// Saving no drafts removes the file
+	if err := SaveDrafts(path, Drafts{}); err != nil {
+		t.Fatalf("Failed to save empty drafts: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the drafts file to be removed, got %v", err)
+	}
+	if err := SaveDrafts(path, Drafts{}); err != nil {
+		t.Errorf("Expected saving no drafts twice to succeed, got %v", err)
+	}
+}
+
+func TestLoadDraftsInvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "drafts.json")
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatalf("Failed to write drafts: %v", err)
+	}
+
+	if _, err := LoadDrafts(path); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
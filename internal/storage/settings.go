@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// Settings are the user's preferences, kept in a JSON file next to the todos.
+type Settings struct {
+	Theme string  `json:"theme,omitempty"`
+	Zoom  float64 `json:"zoom,omitempty"`
+	// AutoArchiveDays archives todos this many days after they are
+	// completed. Zero turns auto-archiving off.
+	AutoArchiveDays int `json:"auto_archive_days,omitempty"`
+	// TrashRetentionDays is how long deleted todos stay in the trash. Zero
+	// uses the default and a negative number keeps them until emptied.
+	TrashRetentionDays int `json:"trash_retention_days,omitempty"`
+	// View is the view shown at start, "list" or "board". Empty means the
+	// list.
+	View string `json:"view,omitempty"`
+	// BoardColumns replaces the default columns of the board.
+	BoardColumns []models.BoardColumn `json:"board_columns,omitempty"`
+	// WeekStart is the day calendar weeks start on, such as "sunday".
+	// Empty uses the custom of the system locale.
+	WeekStart string `json:"week_start,omitempty"`
+}
+
+// LoadSettings reads the settings at path. A missing file yields the
+// zero settings.
+func LoadSettings(path string) (Settings, error) {
+	var settings Settings
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, &models.AppError{
+			Type:    models.ErrorStorage,
+			Message: "Failed to read settings file",
+			Err:     err,
+		}
+	}
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return Settings{}, &models.AppError{
+			Type:    models.ErrorStorage,
+			Message: "Failed to parse settings from JSON",
+			Err:     err,
+		}
+	}
+	return settings, nil
+}
+
+func SaveSettings(path string, settings Settings) error {
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return &models.AppError{
+			Type:    models.ErrorStorage,
+			Message: "Failed to marshal settings to JSON",
+			Err:     err,
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return &models.AppError{
+			Type:    models.ErrorStorage,
+			Message: "Failed to create data directory",
+			Err:     err,
+		}
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return &models.AppError{
+			Type:    models.ErrorStorage,
+			Message: "Failed to write settings to file",
+			Err:     err,
+		}
+	}
+	return nil
+}
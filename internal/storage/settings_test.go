@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+func TestSettingsSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "settings.json")
+
+	// A missing file yields the zero settings
+	settings, err := LoadSettings(path)
+	if err != nil {
+		t.Fatalf("Failed to load missing settings: %v", err)
+	}
+	if settings.Theme != "" {
+		t.Errorf("Expected no theme, got %q", settings.Theme)
+	}
+
+	columns := []models.BoardColumn{{Status: "doing", Title: "Doing"}, {Status: "shipped", Title: "Shipped", Done: true}}
+	if err := SaveSettings(path, Settings{Theme: "Dark", View: "board", BoardColumns: columns}); err != nil {
+		t.Fatalf("Failed to save settings: %v", err)
+	}
+	settings, err = LoadSettings(path)
+	if err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+	if settings.Theme != "Dark" {
+		t.Errorf("Expected theme Dark, got %q", settings.Theme)
+	}
+	if settings.View != "board" || !reflect.DeepEqual(settings.BoardColumns, columns) {
+		t.Errorf("Expected the board settings to survive reload, got %q and %+v", settings.View, settings.BoardColumns)
+	}
+}
+
+func TestLoadSettingsInvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+
+	if _, err := LoadSettings(path); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
@@ -45,7 +45,7 @@ func (fs *FileStorage) SaveTodos(todos []models.Todo) error {
 		}
 	}
 
-	if err := os.WriteFile(fs.filepath, data, 0644); err != nil {
+	if err := writeFileAtomic(fs.filepath, data); err != nil {
 		return &models.AppError{
 			Type:    models.ErrorStorage,
 			Message: "Failed to write todos to file",
@@ -56,6 +56,33 @@ func (fs *FileStorage) SaveTodos(todos []models.Todo) error {
 	return nil
 }
 
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it over path, so that a failed write never leaves a half-written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
 func (fs *FileStorage) LoadTodos() ([]models.Todo, error) {
 	if _, err := os.Stat(fs.filepath); os.IsNotExist(err) {
 		return []models.Todo{}, nil
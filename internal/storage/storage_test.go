@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/lapis2411/todo/internal/models"
 )
@@ -15,12 +16,20 @@ func TestFileStorageSaveAndLoad(t *testing.T) {
 
 	storage := NewFileStorage(testFile)
 
-	// Create test todos
+	// Create test todos at known times with known IDs
+	start := time.Date(2024, 3, 12, 9, 0, 0, 0, time.UTC)
+	clock := models.NewFakeClock(start)
+	ids := &models.SequentialIDs{Prefix: "todo-"}
 	todos := []models.Todo{
-		models.NewTodo("Test todo 1"),
-		models.NewTodo("Test todo 2"),
+		models.NewTodo("Test todo 1", clock, ids),
+		models.NewTodo("Test todo 2", clock, ids),
 	}
-	todos[1].Toggle() // Mark second as completed
+	completed := clock.Advance(5 * time.Minute)
+	todos[1].Toggle(completed) // Mark second as completed
+	edited := clock.Advance(5 * time.Minute)
+	todos[1].SetNotes("First line\nSecond line", edited)
+	todos[0].SetStatus(models.StatusInProgress, false, clock.Advance(5*time.Minute))
+	todos[0].Recurrence = &models.Recurrence{Every: 2, Period: models.PeriodWeek}
 
 	// Save todos
 	err := storage.SaveTodos(todos)
@@ -48,9 +57,42 @@ func TestFileStorageSaveAndLoad(t *testing.T) {
 		t.Errorf("Expected first todo text 'Test todo 1', got %s", loadedTodos[0].Text)
 	}
 
+	if loadedTodos[0].ID != "todo-1" || loadedTodos[1].ID != "todo-2" {
+		t.Errorf("Expected the IDs to survive reload, got %q and %q", loadedTodos[0].ID, loadedTodos[1].ID)
+	}
+
+	if !loadedTodos[0].CreatedAt.Equal(start) || !loadedTodos[0].UpdatedAt.Equal(start.Add(15*time.Minute)) {
+		t.Errorf("Expected the first todo created at %v and updated at %v, got %v and %v",
+			start, start.Add(15*time.Minute), loadedTodos[0].CreatedAt, loadedTodos[0].UpdatedAt)
+	}
+
 	if !loadedTodos[1].Completed {
 		t.Error("Expected second todo to be completed")
 	}
+
+	if loadedTodos[0].Status != models.StatusInProgress {
+		t.Errorf("Expected first todo status to survive reload, got %q", loadedTodos[0].Status)
+	}
+
+	if r := loadedTodos[0].Recurrence; r == nil || *r != (models.Recurrence{Every: 2, Period: models.PeriodWeek}) {
+		t.Errorf("Expected first todo recurrence to survive reload, got %v", r)
+	}
+
+	if loadedTodos[1].Notes != "First line\nSecond line" {
+		t.Errorf("Expected second todo notes to survive reload, got %q", loadedTodos[1].Notes)
+	}
+
+	if loadedTodos[1].CompletedAt == nil || !loadedTodos[1].CompletedAt.Equal(completed) {
+		t.Errorf("Expected the completion time %v to survive reload, got %v", completed, loadedTodos[1].CompletedAt)
+	}
+	if !loadedTodos[1].UpdatedAt.Equal(edited) {
+		t.Errorf("Expected the update time %v to survive reload, got %v", edited, loadedTodos[1].UpdatedAt)
+	}
+	history := loadedTodos[1].History
+	if len(history) != 2 || history[0].Field != models.FieldCompleted || !history[0].At.Equal(completed) ||
+		history[1].Field != models.FieldNotes || !history[1].At.Equal(edited) {
+		t.Errorf("Expected the history to survive reload, got %+v", history)
+	}
 }
 
 func TestFileStorageLoadNonexistentFile(t *testing.T) {
@@ -102,7 +144,7 @@ func TestFileStorageClearTodos(t *testing.T) {
 
 	// Save some todos first
 	todos := []models.Todo{
-		models.NewTodo("Test todo"),
+		models.NewTodo("Test todo", models.SystemClock, models.UUIDs),
 	}
 	err := storage.SaveTodos(todos)
 	if err != nil {
@@ -125,4 +167,22 @@ func TestFileStorageClearTodos(t *testing.T) {
 	if err != nil {
 		t.Errorf("Clearing nonexistent file should not error: %v", err)
 	}
-}
\ No newline at end of file
+}
+func TestFileStorageSaveLeavesNoTempFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := NewFileStorage(filepath.Join(tempDir, "todos.json"))
+
+	for i := 0; i < 2; i++ {
+		if err := storage.SaveTodos([]models.Todo{models.NewTodo("Test", models.SystemClock, models.UUIDs)}); err != nil {
+			t.Fatalf("Failed to save todos: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to read directory: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "todos.json" {
+		t.Errorf("Expected only todos.json in the directory, got %v", entries)
+	}
+}
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"image"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// BoardCard is a todo shown as a card in a column of the board, with its
+// text on the first line and its tags dimmed below.
+type BoardCard struct {
+	Todo     *models.Todo
+	X, Y     int
+	Width    int
+	Height   int
+	Selected bool
+	// Dragging draws the card lifted, with an accent border, while it
+	// follows the cursor.
+	Dragging bool
+}
+
+func NewBoardCard(todo *models.Todo, x, y, width, height int) *BoardCard {
+	return &BoardCard{
+		Todo:   todo,
+		X:      x,
+		Y:      y,
+		Width:  width,
+		Height: height,
+	}
+}
+
+func (c *BoardCard) Draw(screen *ebiten.Image) {
+	theme := CurrentTheme()
+
+	borderColor := theme.Border
+	if c.Dragging {
+		borderColor = theme.Accent
+	}
+	bgColor := theme.Surface
+	if c.Selected {
+		bgColor = theme.SelectedRow
+	}
+	DrawRect(screen, float64(c.X), float64(c.Y), float64(c.Width), float64(c.Height), borderColor)
+	DrawRect(screen, float64(c.X+1), float64(c.Y+1), float64(c.Width-2), float64(c.Height-2), bgColor)
+	if barColor, ok := priorityColor(c.Todo.Priority); ok {
+		DrawRect(screen, float64(c.X+1), float64(c.Y+1), priorityBarWidth, float64(c.Height-2), barColor)
+	}
+
+	textColor := theme.Text
+	if c.Todo.Completed {
+		textColor = theme.TextMuted
+	}
+	textX := c.X + 10
+	maxWidth := c.Width - 18
+	DrawText(screen, TruncateText(c.Todo.Text, maxWidth), textX, c.Y+18, textColor)
+	if len(c.Todo.Tags) > 0 {
+		label := "#" + strings.Join(c.Todo.Tags, " #")
+		DrawText(screen, TruncateText(label, maxWidth), textX, c.Y+34, theme.TextMuted)
+	}
+}
+
+func (c *BoardCard) Bounds() image.Rectangle {
+	return image.Rect(c.X, c.Y, c.X+c.Width, c.Y+c.Height)
+}
+
+func (c *BoardCard) Contains(x, y int) bool {
+	return image.Pt(x, y).In(c.Bounds())
+}
@@ -1,53 +1,61 @@
 package ui
 
 import (
+	"image"
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/image/font/basicfont"
 )
 
+// ButtonVariant selects the theme colors a button is drawn with.
+type ButtonVariant int
+
+const (
+	ButtonPrimary ButtonVariant = iota
+	ButtonSecondary
+	ButtonDanger
+)
+
 type Button struct {
 	X, Y, Width, Height int
-	Text               string
-	OnClick            func()
-	Hovered            bool
-	Pressed            bool
-	Enabled            bool
-	BackgroundColor    color.RGBA
-	HoverColor         color.RGBA
-	TextColor          color.RGBA
+	Text                string
+	OnClick             func()
+	Hovered             bool
+	Pressed             bool
+	Enabled             bool
+	Focused             bool
+	Keyboard            *Keyboard
+	Variant             ButtonVariant
 }
 
 func NewButton(x, y, width, height int, text string, onClick func()) *Button {
 	return &Button{
-		X:               x,
-		Y:               y,
-		Width:           width,
-		Height:          height,
-		Text:            text,
-		OnClick:         onClick,
-		Enabled:         true,
-		BackgroundColor: color.RGBA{0, 123, 255, 255},   // Primary blue
-		HoverColor:      color.RGBA{0, 86, 179, 255},    // Darker blue
-		TextColor:       color.RGBA{255, 255, 255, 255}, // White
+		X:        x,
+		Y:        y,
+		Width:    width,
+		Height:   height,
+		Text:     text,
+		OnClick:  onClick,
+		Enabled:  true,
+		Keyboard: DefaultKeyboard,
+		Variant:  ButtonPrimary,
 	}
 }
 
-func (b *Button) Update() {
+func (b *Button) Update(in InputSource) {
 	if !b.Enabled {
 		return
 	}
 
-	x, y := ebiten.CursorPosition()
-	
+	x, y := CursorPosition(in)
+
 	// Check if cursor is over button
 	b.Hovered = x >= b.X && x <= b.X+b.Width && y >= b.Y && y <= b.Y+b.Height
 
 	// Check for click
-	if b.Hovered && ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+	if b.Hovered && in.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
 		if !b.Pressed {
 			b.Pressed = true
 		}
@@ -58,41 +66,53 @@ func (b *Button) Update() {
 		}
 		b.Pressed = false
 	}
+
+	// A focused button is activated with Enter or Space
+	if b.Focused && b.OnClick != nil {
+		kb := b.Keyboard
+		if kb == nil {
+			kb = DefaultKeyboard
+		}
+		if kb.IsJustPressed(ebiten.KeyEnter) || kb.IsJustPressed(ebiten.KeySpace) {
+			b.OnClick()
+		}
+	}
 }
 
 func (b *Button) Draw(screen *ebiten.Image) {
-	bgColor := b.BackgroundColor
+	theme := CurrentTheme()
+	bgColor, hoverColor := b.colors(theme)
 	if !b.Enabled {
-		bgColor = color.RGBA{108, 117, 125, 255} // Disabled gray
+		bgColor = theme.Secondary
 	} else if b.Hovered || b.Pressed {
-		bgColor = b.HoverColor
+		bgColor = hoverColor
 	}
 
 	// Draw background
-	ebitenutil.DrawRect(screen, float64(b.X), float64(b.Y), float64(b.Width), float64(b.Height), bgColor)
+	DrawRect(screen, float64(b.X), float64(b.Y), float64(b.Width), float64(b.Height), bgColor)
 
 	// Draw border
-	borderColor := color.RGBA{0, 0, 0, 100}
-	ebitenutil.DrawRect(screen, float64(b.X), float64(b.Y), float64(b.Width), 1, borderColor)
-	ebitenutil.DrawRect(screen, float64(b.X), float64(b.Y), 1, float64(b.Height), borderColor)
-	ebitenutil.DrawRect(screen, float64(b.X+b.Width-1), float64(b.Y), 1, float64(b.Height), borderColor)
-	ebitenutil.DrawRect(screen, float64(b.X), float64(b.Y+b.Height-1), float64(b.Width), 1, borderColor)
+	borderColor := theme.ButtonBorder
+	DrawRect(screen, float64(b.X), float64(b.Y), float64(b.Width), 1, borderColor)
+	DrawRect(screen, float64(b.X), float64(b.Y), 1, float64(b.Height), borderColor)
+	DrawRect(screen, float64(b.X+b.Width-1), float64(b.Y), 1, float64(b.Height), borderColor)
+	DrawRect(screen, float64(b.X), float64(b.Y+b.Height-1), float64(b.Width), 1, borderColor)
 
 	// Draw text
-	textColor := b.TextColor
+	textColor := theme.TextOnAccent
 	if !b.Enabled {
-		textColor = color.RGBA{200, 200, 200, 255}
+		textColor = theme.TextDisabled
 	}
 
 	// Center text in button
 	bounds := text.BoundString(basicfont.Face7x13, b.Text)
 	textWidth := bounds.Max.X - bounds.Min.X
 	textHeight := bounds.Max.Y - bounds.Min.Y
-	
+
 	textX := b.X + (b.Width-textWidth)/2
 	textY := b.Y + (b.Height+textHeight)/2
 
-	text.Draw(screen, b.Text, basicfont.Face7x13, textX, textY, textColor)
+	DrawText(screen, b.Text, textX, textY, textColor)
 }
 
 func (b *Button) SetEnabled(enabled bool) {
@@ -103,6 +123,14 @@ func (b *Button) Contains(x, y int) bool {
 	return x >= b.X && x <= b.X+b.Width && y >= b.Y && y <= b.Y+b.Height
 }
 
+func (b *Button) SetFocus(focused bool) {
+	b.Focused = focused
+}
+
+func (b *Button) Bounds() image.Rectangle {
+	return image.Rect(b.X, b.Y, b.X+b.Width, b.Y+b.Height)
+}
+
 func (b *Button) SetPosition(x, y int) {
 	b.X = x
 	b.Y = y
@@ -113,12 +141,28 @@ func (b *Button) SetSize(width, height int) {
 	b.Height = height
 }
 
+// SetBounds moves and resizes the button to r.
+func (b *Button) SetBounds(r image.Rectangle) {
+	b.SetPosition(r.Min.X, r.Min.Y)
+	b.SetSize(r.Dx(), r.Dy())
+}
+
 func (b *Button) SetText(text string) {
 	b.Text = text
 }
 
-func (b *Button) SetColors(bg, hover, text color.RGBA) {
-	b.BackgroundColor = bg
-	b.HoverColor = hover
-	b.TextColor = text
-}
\ No newline at end of file
+func (b *Button) SetVariant(variant ButtonVariant) {
+	b.Variant = variant
+}
+
+// colors returns the background and hover colors of the button's variant.
+func (b *Button) colors(theme *Theme) (color.RGBA, color.RGBA) {
+	switch b.Variant {
+	case ButtonSecondary:
+		return theme.Secondary, theme.SecondaryHover
+	case ButtonDanger:
+		return theme.Danger, theme.DangerHover
+	default:
+		return theme.Accent, theme.AccentHover
+	}
+}
@@ -0,0 +1,39 @@
+package ui
+
+import "sync"
+
+// Clipboard is the text clipboard used by editable widgets.
+type Clipboard interface {
+	ReadText() (string, error)
+	WriteText(text string) error
+}
+
+// MemoryClipboard keeps clipboard contents in process memory. It is used
+// where there is no system clipboard, and by tests.
+type MemoryClipboard struct {
+	mu   sync.Mutex
+	text string
+}
+
+func NewMemoryClipboard() *MemoryClipboard {
+	return &MemoryClipboard{}
+}
+
+func (c *MemoryClipboard) ReadText() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.text, nil
+}
+
+func (c *MemoryClipboard) WriteText(text string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.text = text
+	return nil
+}
+
+// DefaultClipboard is shared by all widgets created without an explicit clipboard,
+// so text cut from one text box can be pasted into another. It is the system
+// clipboard, so that text can be copied to and from other apps; tests and
+// replays use a MemoryClipboard instead.
+var DefaultClipboard Clipboard = NewSystemClipboard()
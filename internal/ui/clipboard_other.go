@@ -0,0 +1,9 @@
+//go:build !(windows || (cgo && ((linux && !android) || (darwin && !ios))))
+
+package ui
+
+// NewSystemClipboard returns a MemoryClipboard, as there is no system
+// clipboard the app can reach on this platform.
+func NewSystemClipboard() Clipboard {
+	return NewMemoryClipboard()
+}
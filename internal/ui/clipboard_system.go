@@ -0,0 +1,49 @@
+//go:build windows || (cgo && ((linux && !android) || (darwin && !ios)))
+
+package ui
+
+import (
+	"errors"
+	"sync"
+
+	"golang.design/x/clipboard"
+)
+
+// systemClipboard is the clipboard of the operating system. It connects on
+// first use, and keeps text in memory when it cannot, such as on Linux
+// without a display.
+type systemClipboard struct {
+	once     sync.Once
+	err      error
+	fallback *MemoryClipboard
+}
+
+// NewSystemClipboard returns the clipboard of the operating system, shared
+// with other apps.
+func NewSystemClipboard() Clipboard {
+	return &systemClipboard{fallback: NewMemoryClipboard()}
+}
+
+func (c *systemClipboard) available() bool {
+	c.once.Do(func() {
+		c.err = clipboard.Init()
+	})
+	return c.err == nil
+}
+
+func (c *systemClipboard) ReadText() (string, error) {
+	if !c.available() {
+		return c.fallback.ReadText()
+	}
+	return string(clipboard.Read(clipboard.FmtText)), nil
+}
+
+func (c *systemClipboard) WriteText(text string) error {
+	if !c.available() {
+		return c.fallback.WriteText(text)
+	}
+	if clipboard.Write(clipboard.FmtText, []byte(text)) == nil {
+		return errors.New("failed to write to the system clipboard")
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	textv2 "github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/gomono"
+)
+
+// Widgets draw in UI pixels through DrawRect, DrawText and Clip, which
+// convert to the pixels of the image being drawn on. The game draws
+// straight onto the screen at Scale() screen pixels per UI pixel, so that
+// shapes and text are rendered at the display's resolution. Text uses Go
+// Mono, sized and spaced to fill the same cells as basicfont.Face7x13,
+// which widgets measure text with.
+//
+// When the font cannot be loaded, the game falls back to drawing the UI at
+// its own size with basicfont.Face7x13 and scaling the result.
+
+var (
+	drawScale = 1.0 // Pixels of the image drawn on per UI pixel
+
+	textSource *textv2.GoTextFaceSource // Nil when the font failed to load
+	textSize   float64                  // Font size at a draw scale of 1
+	textFace   *textv2.GoTextFace       // Sized for the current draw scale
+)
+
+func init() {
+	source, err := textv2.NewGoTextFaceSource(bytes.NewReader(gomono.TTF))
+	if err != nil {
+		return
+	}
+	// Advances are measured at a large size, as they are rounded to 1/64
+	// of a pixel
+	const measureSize = 1024
+	advance := float64(font.MeasureString(basicfont.Face7x13, "0")) / 64
+	textSource = source
+	textSize = advance * measureSize / textv2.Advance("0", &textv2.GoTextFace{Source: source, Size: measureSize})
+}
+
+// ScalableText reports whether text can be drawn at any draw scale.
+func ScalableText() bool {
+	return textSource != nil
+}
+
+// SetDrawScale sets the number of pixels of the image drawn on per UI
+// pixel, for the draws that follow. Values that are not positive are
+// ignored.
+func SetDrawScale(s float64) {
+	if s > 0 {
+		drawScale = s
+	}
+}
+
+// DrawRect fills a rectangle given in UI pixels. Its edges are rounded to
+// whole pixels so that neighbouring rectangles neither overlap nor leave
+// gaps.
+func DrawRect(dst *ebiten.Image, x, y, width, height float64, clr color.Color) {
+	x0, y0 := math.Round(x*drawScale), math.Round(y*drawScale)
+	x1, y1 := math.Round((x+width)*drawScale), math.Round((y+height)*drawScale)
+	ebitenutil.DrawRect(dst, x0, y0, x1-x0, y1-y0, clr)
+}
+
+// DrawText draws s with its baseline starting at (x, y) in UI pixels.
+func DrawText(dst *ebiten.Image, s string, x, y int, clr color.Color) {
+	drawText(dst, s, x, y, clr, 0)
+}
+
+// drawText draws s slanted by skew around its baseline.
+func drawText(dst *ebiten.Image, s string, x, y int, clr color.Color, skew float64) {
+	if textSource == nil {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Skew(skew, 0)
+		op.GeoM.Translate(float64(x), float64(y))
+		op.ColorScale.ScaleWithColor(clr)
+		text.DrawWithOptions(dst, s, basicfont.Face7x13, op)
+		return
+	}
+
+	size := textSize * drawScale
+	if textFace == nil || textFace.Size != size {
+		textFace = &textv2.GoTextFace{Source: textSource, Size: size}
+	}
+	// Each character is put in its own cell, as the shaped advances are not
+	// exactly those widgets measure with. Text is placed by the top of its
+	// line, so it is moved up by the ascent to put the baseline at y.
+	ascent := textFace.Metrics().HAscent
+	cell := float64(textWidth("0")) * drawScale
+	for i, r := range []rune(s) {
+		op := &textv2.DrawOptions{}
+		op.GeoM.Translate(0, -ascent)
+		op.GeoM.Skew(skew, 0)
+		op.GeoM.Translate(float64(x)*drawScale+float64(i)*cell, float64(y)*drawScale)
+		op.ColorScale.ScaleWithColor(clr)
+		textv2.Draw(dst, string(r), textFace, op)
+	}
+}
+
+// Clip returns the part of dst inside r, given in UI pixels.
+func Clip(dst *ebiten.Image, r image.Rectangle) *ebiten.Image {
+	scaled := image.Rect(
+		int(math.Round(float64(r.Min.X)*drawScale)), int(math.Round(float64(r.Min.Y)*drawScale)),
+		int(math.Round(float64(r.Max.X)*drawScale)), int(math.Round(float64(r.Max.Y)*drawScale)),
+	)
+	return dst.SubImage(scaled).(*ebiten.Image)
+}
@@ -0,0 +1,326 @@
+package ui
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// textEditor holds the text, cursor and selection state shared by the
+// single-line TextBox and the multiline TextArea, along with the editing
+// operations both support.
+type textEditor struct {
+	Text string
+	// CursorPos and SelectionAnchor are rune offsets into Text. The selection
+	// spans from SelectionAnchor to CursorPos and is empty when they are equal.
+	CursorPos        int
+	SelectionAnchor  int
+	ShowCursor       bool
+	MaxLength        int
+	Clipboard        Clipboard
+	Keyboard         *Keyboard
+	Clock            models.Clock // Times the cursor blink
+	lastCursorToggle time.Time
+	multiline        bool
+}
+
+// HasSelection reports whether a non-empty range of text is selected.
+func (e *textEditor) HasSelection() bool {
+	return e.SelectionAnchor != e.CursorPos
+}
+
+// Selection returns the selected rune range in ascending order.
+func (e *textEditor) Selection() (start, end int) {
+	if e.SelectionAnchor < e.CursorPos {
+		return e.SelectionAnchor, e.CursorPos
+	}
+	return e.CursorPos, e.SelectionAnchor
+}
+
+func (e *textEditor) SelectedText() string {
+	start, end := e.Selection()
+	return string([]rune(e.Text)[start:end])
+}
+
+func (e *textEditor) SelectAll() {
+	e.SelectionAnchor = 0
+	e.CursorPos = e.length()
+}
+
+// MoveCursor moves the cursor to pos. When extend is true the selection
+// anchor stays put so the selection grows or shrinks with the cursor.
+func (e *textEditor) MoveCursor(pos int, extend bool) {
+	e.CursorPos = clamp(pos, 0, e.length())
+	if !extend {
+		e.SelectionAnchor = e.CursorPos
+	}
+	e.ShowCursor = true
+	e.lastCursorToggle = e.clock().Now()
+}
+
+// InsertText replaces the selection with s, dropping characters the editor
+// cannot hold and truncating to MaxLength.
+func (e *textEditor) InsertText(s string) {
+	e.DeleteSelection()
+
+	insert := []rune(e.sanitize(s))
+	if room := e.MaxLength - e.length(); len(insert) > room {
+		if room <= 0 {
+			return
+		}
+		insert = insert[:room]
+	}
+
+	runes := []rune(e.Text)
+	runes = append(runes[:e.CursorPos], append(insert, runes[e.CursorPos:]...)...)
+	e.Text = string(runes)
+	e.MoveCursor(e.CursorPos+len(insert), false)
+}
+
+// DeleteSelection removes the selected text and reports whether anything was removed.
+func (e *textEditor) DeleteSelection() bool {
+	if !e.HasSelection() {
+		return false
+	}
+	start, end := e.Selection()
+	e.deleteRange(start, end)
+	return true
+}
+
+func (e *textEditor) Backspace() {
+	if e.DeleteSelection() {
+		return
+	}
+	if e.CursorPos > 0 {
+		e.deleteRange(e.CursorPos-1, e.CursorPos)
+	}
+}
+
+func (e *textEditor) DeleteForward() {
+	if e.DeleteSelection() {
+		return
+	}
+	if e.CursorPos < e.length() {
+		e.deleteRange(e.CursorPos, e.CursorPos+1)
+	}
+}
+
+func (e *textEditor) DeleteWordBackward() {
+	if e.DeleteSelection() {
+		return
+	}
+	e.deleteRange(e.wordStartBefore(e.CursorPos), e.CursorPos)
+}
+
+func (e *textEditor) DeleteWordForward() {
+	if e.DeleteSelection() {
+		return
+	}
+	e.deleteRange(e.CursorPos, e.wordEndAfter(e.CursorPos))
+}
+
+// Copy writes the selected text to the clipboard.
+func (e *textEditor) Copy() {
+	if !e.HasSelection() || e.Clipboard == nil {
+		return
+	}
+	_ = e.Clipboard.WriteText(e.SelectedText())
+}
+
+// Cut copies the selected text to the clipboard and removes it.
+func (e *textEditor) Cut() {
+	if !e.HasSelection() || e.Clipboard == nil {
+		return
+	}
+	if err := e.Clipboard.WriteText(e.SelectedText()); err != nil {
+		return
+	}
+	e.DeleteSelection()
+}
+
+// Paste replaces the selection with the clipboard contents.
+func (e *textEditor) Paste() {
+	if e.Clipboard == nil {
+		return
+	}
+	s, err := e.Clipboard.ReadText()
+	if err != nil || s == "" {
+		return
+	}
+	e.InsertText(s)
+}
+
+// updateCursorBlink toggles cursor visibility every half second.
+func (e *textEditor) updateCursorBlink() {
+	if now := e.clock().Now(); now.Sub(e.lastCursorToggle) > 500*time.Millisecond {
+		e.ShowCursor = !e.ShowCursor
+		e.lastCursorToggle = now
+	}
+}
+
+// handleEditingKeys applies typing, clipboard shortcuts, horizontal cursor
+// movement and deletion from the current keyboard frame.
+func (e *textEditor) handleEditingKeys() {
+	kb := e.keyboard()
+	shortcut := kb.IsShortcutModifierPressed()
+	shift := kb.IsShiftPressed()
+
+	// Handle text input
+	for _, r := range kb.Chars() {
+		e.InsertText(string(r))
+	}
+
+	// Handle clipboard and selection shortcuts
+	if shortcut {
+		if kb.IsJustPressed(ebiten.KeyA) {
+			e.SelectAll()
+		}
+		if kb.IsJustPressed(ebiten.KeyC) {
+			e.Copy()
+		}
+		if kb.IsJustPressed(ebiten.KeyX) {
+			e.Cut()
+		}
+		if kb.IsTriggered(ebiten.KeyV) {
+			e.Paste()
+		}
+	}
+
+	// Handle arrow keys
+	if kb.IsTriggered(ebiten.KeyArrowLeft) {
+		switch {
+		case shortcut:
+			e.MoveCursor(e.wordStartBefore(e.CursorPos), shift)
+		case e.HasSelection() && !shift:
+			start, _ := e.Selection()
+			e.MoveCursor(start, false)
+		default:
+			e.MoveCursor(e.CursorPos-1, shift)
+		}
+	}
+	if kb.IsTriggered(ebiten.KeyArrowRight) {
+		switch {
+		case shortcut:
+			e.MoveCursor(e.wordEndAfter(e.CursorPos), shift)
+		case e.HasSelection() && !shift:
+			_, end := e.Selection()
+			e.MoveCursor(end, false)
+		default:
+			e.MoveCursor(e.CursorPos+1, shift)
+		}
+	}
+
+	// Handle Delete and Backspace keys
+	if kb.IsTriggered(ebiten.KeyDelete) {
+		if shortcut {
+			e.DeleteWordForward()
+		} else {
+			e.DeleteForward()
+		}
+	}
+	if kb.IsTriggered(ebiten.KeyBackspace) {
+		if shortcut {
+			e.DeleteWordBackward()
+		} else {
+			e.Backspace()
+		}
+	}
+}
+
+func (e *textEditor) deleteRange(start, end int) {
+	runes := []rune(e.Text)
+	e.Text = string(append(runes[:start], runes[end:]...))
+	e.MoveCursor(start, false)
+}
+
+func (e *textEditor) length() int {
+	return len([]rune(e.Text))
+}
+
+// clampCursor keeps the cursor and anchor inside Text after it was assigned directly.
+func (e *textEditor) clampCursor() {
+	n := e.length()
+	e.CursorPos = clamp(e.CursorPos, 0, n)
+	e.SelectionAnchor = clamp(e.SelectionAnchor, 0, n)
+}
+
+// wordStartBefore returns the start of the word before pos, skipping any
+// separators directly in front of it.
+func (e *textEditor) wordStartBefore(pos int) int {
+	runes := []rune(e.Text)
+	for pos > 0 && !isWordRune(runes[pos-1]) {
+		pos--
+	}
+	for pos > 0 && isWordRune(runes[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// wordEndAfter returns the end of the word after pos, skipping any
+// separators directly behind it.
+func (e *textEditor) wordEndAfter(pos int) int {
+	runes := []rune(e.Text)
+	for pos < len(runes) && !isWordRune(runes[pos]) {
+		pos++
+	}
+	for pos < len(runes) && isWordRune(runes[pos]) {
+		pos++
+	}
+	return pos
+}
+
+func (e *textEditor) keyboard() *Keyboard {
+	if e.Keyboard != nil {
+		return e.Keyboard
+	}
+	return DefaultKeyboard
+}
+
+func (e *textEditor) clock() models.Clock {
+	if e.Clock != nil {
+		return e.Clock
+	}
+	return models.SystemClock
+}
+
+// sanitize removes characters the editor cannot hold. Single-line editors
+// turn line breaks into spaces.
+func (e *textEditor) sanitize(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' && e.multiline:
+			return r
+		case r == '\n' || r == '\r' || r == '\t':
+			return ' '
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func textWidth(s string) int {
+	return font.MeasureString(basicfont.Face7x13, s).Ceil()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"image"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Focusable is a widget that can hold keyboard focus.
+type Focusable interface {
+	SetFocus(focused bool)
+	Contains(x, y int) bool
+	// Bounds is the area the focus ring is drawn around.
+	Bounds() image.Rectangle
+}
+
+// FocusManager owns keyboard focus for a window. Widgets never take focus
+// themselves; the manager moves it on clicks, Tab and Shift+Tab, or when
+// asked to through Focus.
+type FocusManager struct {
+	widgets []Focusable
+	focused Focusable
+}
+
+func NewFocusManager() *FocusManager {
+	return &FocusManager{}
+}
+
+// SetWidgets sets the focusable widgets in tab order. Widgets later in the
+// order are treated as lying on top of earlier ones when hit-testing clicks.
+func (fm *FocusManager) SetWidgets(widgets ...Focusable) {
+	fm.widgets = widgets
+}
+
+func (fm *FocusManager) Focused() Focusable {
+	return fm.focused
+}
+
+func (fm *FocusManager) IsFocused(w Focusable) bool {
+	return fm.focused != nil && fm.focused == w
+}
+
+// HasWidget reports whether w is one of the managed widgets.
+func (fm *FocusManager) HasWidget(w Focusable) bool {
+	return slices.Contains(fm.widgets, w)
+}
+
+// Focus moves focus to w. A nil w clears focus.
+func (fm *FocusManager) Focus(w Focusable) {
+	if fm.focused == w {
+		return
+	}
+	if fm.focused != nil {
+		fm.focused.SetFocus(false)
+	}
+	fm.focused = w
+	if w != nil {
+		w.SetFocus(true)
+	}
+}
+
+// Next moves focus to the next widget in tab order, wrapping around.
+func (fm *FocusManager) Next() {
+	fm.step(1)
+}
+
+// Previous moves focus to the previous widget in tab order, wrapping around.
+func (fm *FocusManager) Previous() {
+	fm.step(-1)
+}
+
+func (fm *FocusManager) step(delta int) {
+	if len(fm.widgets) == 0 {
+		fm.Focus(nil)
+		return
+	}
+	i := slices.Index(fm.widgets, fm.focused)
+	if i < 0 {
+		if delta > 0 {
+			i = -1
+		} else {
+			i = len(fm.widgets)
+		}
+	}
+	i = (i + delta + len(fm.widgets)) % len(fm.widgets)
+	fm.Focus(fm.widgets[i])
+}
+
+// HandleClick focuses the topmost widget at (x, y), or clears focus when
+// the click hit none. It reports whether a widget was focused.
+func (fm *FocusManager) HandleClick(x, y int) bool {
+	for i := len(fm.widgets) - 1; i >= 0; i-- {
+		if fm.widgets[i].Contains(x, y) {
+			fm.Focus(fm.widgets[i])
+			return true
+		}
+	}
+	fm.Focus(nil)
+	return false
+}
+
+// HandleTab moves focus on Tab and Shift+Tab and reports whether it did.
+func (fm *FocusManager) HandleTab(kb *Keyboard) bool {
+	if !kb.IsTriggered(ebiten.KeyTab) {
+		return false
+	}
+	if kb.IsShiftPressed() {
+		fm.Previous()
+	} else {
+		fm.Next()
+	}
+	return true
+}
+
+// Draw draws a ring around the focused widget.
+func (fm *FocusManager) Draw(screen *ebiten.Image) {
+	if fm.focused == nil {
+		return
+	}
+	r := fm.focused.Bounds()
+	if r.Empty() {
+		return
+	}
+	r = r.Inset(-3)
+	ringColor := CurrentTheme().FocusRing
+	for i := 0; i < 2; i++ {
+		DrawRect(screen, float64(r.Min.X-i), float64(r.Min.Y-i), float64(r.Dx()+2*i), 1, ringColor)
+		DrawRect(screen, float64(r.Min.X-i), float64(r.Max.Y-1+i), float64(r.Dx()+2*i), 1, ringColor)
+		DrawRect(screen, float64(r.Min.X-i), float64(r.Min.Y-i), 1, float64(r.Dy()+2*i), ringColor)
+		DrawRect(screen, float64(r.Max.X-1+i), float64(r.Min.Y-i), 1, float64(r.Dy()+2*i), ringColor)
+	}
+}
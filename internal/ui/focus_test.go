@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+type fakeFocusable struct {
+	bounds  image.Rectangle
+	focused bool
+}
+
+func (f *fakeFocusable) SetFocus(focused bool)   { f.focused = focused }
+func (f *fakeFocusable) Contains(x, y int) bool  { return image.Pt(x, y).In(f.bounds) }
+func (f *fakeFocusable) Bounds() image.Rectangle { return f.bounds }
+
+func TestFocusManagerTabOrder(t *testing.T) {
+	a := &fakeFocusable{}
+	b := &fakeFocusable{}
+	c := &fakeFocusable{}
+	fm := NewFocusManager()
+	fm.SetWidgets(a, b, c)
+
+	kb := NewKeyboard(DefaultKeyRepeatDelay, DefaultKeyRepeatInterval)
+	clock := models.NewFakeClock(testNow)
+	press := func(keys ...ebiten.Key) {
+		kb.Update(clock.Now(), keys, nil)
+		fm.HandleTab(kb)
+		clock.Advance(time.Second)
+		kb.Update(clock.Now(), nil, nil)
+	}
+
+	press(ebiten.KeyTab)
+	if !fm.IsFocused(a) || !a.focused {
+		t.Fatal("Expected Tab to focus the first widget")
+	}
+	press(ebiten.KeyTab)
+	press(ebiten.KeyTab)
+	if !fm.IsFocused(c) || a.focused || b.focused {
+		t.Fatal("Expected focus on the third widget only")
+	}
+	press(ebiten.KeyTab)
+	if !fm.IsFocused(a) {
+		t.Error("Expected Tab to wrap around to the first widget")
+	}
+	press(ebiten.KeyShiftLeft, ebiten.KeyTab)
+	if !fm.IsFocused(c) {
+		t.Error("Expected Shift+Tab to wrap around to the last widget")
+	}
+}
+
+func TestFocusManagerHandleClick(t *testing.T) {
+	back := &fakeFocusable{bounds: image.Rect(0, 0, 100, 100)}
+	front := &fakeFocusable{bounds: image.Rect(10, 10, 20, 20)}
+	fm := NewFocusManager()
+	fm.SetWidgets(back, front)
+
+	fm.HandleClick(15, 15)
+	if !fm.IsFocused(front) {
+		t.Error("Expected the later widget to win where widgets overlap")
+	}
+	fm.HandleClick(50, 50)
+	if !fm.IsFocused(back) || front.focused {
+		t.Error("Expected focus to move to the widget under the click")
+	}
+	if fm.HandleClick(500, 500) || fm.Focused() != nil || back.focused {
+		t.Error("Expected a click outside all widgets to clear focus")
+	}
+}
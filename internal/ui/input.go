@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"image"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// InputSource is where widgets read the mouse and keyboard from. Widgets
+// take it as an argument of Update rather than asking Ebiten, so that tests
+// can drive them with a ScriptedInput. Positions are in screen pixels.
+type InputSource interface {
+	CursorPosition() (x, y int)
+	IsMouseButtonPressed(button ebiten.MouseButton) bool
+	IsMouseButtonJustPressed(button ebiten.MouseButton) bool
+	IsMouseButtonJustReleased(button ebiten.MouseButton) bool
+	// Wheel returns how far the wheel turned this frame.
+	Wheel() (dx, dy float64)
+	// AppendPressedKeys appends the keys held down to keys.
+	AppendPressedKeys(keys []ebiten.Key) []ebiten.Key
+	// AppendInputChars appends the characters typed this frame to chars.
+	AppendInputChars(chars []rune) []rune
+}
+
+type ebitenInput struct{}
+
+// EbitenInput reads the input of the running Ebiten game.
+var EbitenInput InputSource = ebitenInput{}
+
+func (ebitenInput) CursorPosition() (int, int) { return ebiten.CursorPosition() }
+
+func (ebitenInput) IsMouseButtonPressed(button ebiten.MouseButton) bool {
+	return ebiten.IsMouseButtonPressed(button)
+}
+
+func (ebitenInput) IsMouseButtonJustPressed(button ebiten.MouseButton) bool {
+	return inpututil.IsMouseButtonJustPressed(button)
+}
+
+func (ebitenInput) IsMouseButtonJustReleased(button ebiten.MouseButton) bool {
+	return inpututil.IsMouseButtonJustReleased(button)
+}
+
+func (ebitenInput) Wheel() (float64, float64) { return ebiten.Wheel() }
+
+func (ebitenInput) AppendPressedKeys(keys []ebiten.Key) []ebiten.Key {
+	return inpututil.AppendPressedKeys(keys)
+}
+
+func (ebitenInput) AppendInputChars(chars []rune) []rune {
+	return ebiten.AppendInputChars(chars)
+}
+
+// InputFrame is the state of the mouse and keyboard in one frame.
+type InputFrame struct {
+	Cursor image.Point `json:"cursor"`
+	// Buttons and Keys are the ones held down.
+	Buttons []ebiten.MouseButton `json:"buttons,omitempty"`
+	Keys    []ebiten.Key         `json:"keys,omitempty"`
+	Chars   string               `json:"chars,omitempty"`
+	WheelX  float64              `json:"wheel_x,omitempty"`
+	WheelY  float64              `json:"wheel_y,omitempty"`
+}
+
+// mouseButtons are the buttons CaptureFrame records.
+var mouseButtons = []ebiten.MouseButton{ebiten.MouseButtonLeft, ebiten.MouseButtonRight, ebiten.MouseButtonMiddle}
+
+// CaptureFrame returns the current state of in as a frame, which a
+// ScriptedInput plays back the same way.
+func CaptureFrame(in InputSource) InputFrame {
+	var frame InputFrame
+	frame.Cursor.X, frame.Cursor.Y = in.CursorPosition()
+	for _, button := range mouseButtons {
+		if in.IsMouseButtonPressed(button) {
+			frame.Buttons = append(frame.Buttons, button)
+		}
+	}
+	frame.Keys = in.AppendPressedKeys(nil)
+	frame.Chars = string(in.AppendInputChars(nil))
+	frame.WheelX, frame.WheelY = in.Wheel()
+	return frame
+}
+
+// ScriptedInput is an InputSource that plays back frames one at a time.
+// Buttons count as just pressed or released by comparing a frame with the
+// one before it. Once the script is over the input is idle at the last
+// cursor position until more frames are added.
+type ScriptedInput struct {
+	Frames []InputFrame
+	frame  int  // The current frame, or -1 before the first
+	over   bool // Whether Next ran out of frames
+}
+
+func NewScriptedInput(frames ...InputFrame) *ScriptedInput {
+	return &ScriptedInput{Frames: frames, frame: -1}
+}
+
+// Add appends frames to the script.
+func (s *ScriptedInput) Add(frames ...InputFrame) {
+	s.Frames = append(s.Frames, frames...)
+}
+
+// Next moves to the next frame. It reports false when the script is over.
+func (s *ScriptedInput) Next() bool {
+	if s.frame+1 >= len(s.Frames) {
+		s.over = true
+		return false
+	}
+	s.frame++
+	s.over = false
+	return true
+}
+
+// Run calls update once for each frame left in the script.
+func (s *ScriptedInput) Run(update func()) {
+	for s.Next() {
+		update()
+	}
+}
+
+// at returns frame i, or an empty frame before the start.
+func (s *ScriptedInput) at(i int) InputFrame {
+	if i < 0 || i >= len(s.Frames) {
+		return InputFrame{}
+	}
+	return s.Frames[i]
+}
+
+// current returns the frame being played and the one before it.
+func (s *ScriptedInput) current() (frame, previous InputFrame) {
+	if s.over {
+		last := s.at(s.frame)
+		return InputFrame{Cursor: last.Cursor}, last
+	}
+	return s.at(s.frame), s.at(s.frame - 1)
+}
+
+func (s *ScriptedInput) CursorPosition() (int, int) {
+	frame, _ := s.current()
+	return frame.Cursor.X, frame.Cursor.Y
+}
+
+func (s *ScriptedInput) IsMouseButtonPressed(button ebiten.MouseButton) bool {
+	frame, _ := s.current()
+	return slices.Contains(frame.Buttons, button)
+}
+
+func (s *ScriptedInput) IsMouseButtonJustPressed(button ebiten.MouseButton) bool {
+	frame, previous := s.current()
+	return slices.Contains(frame.Buttons, button) && !slices.Contains(previous.Buttons, button)
+}
+
+func (s *ScriptedInput) IsMouseButtonJustReleased(button ebiten.MouseButton) bool {
+	frame, previous := s.current()
+	return !slices.Contains(frame.Buttons, button) && slices.Contains(previous.Buttons, button)
+}
+
+func (s *ScriptedInput) Wheel() (float64, float64) {
+	frame, _ := s.current()
+	return frame.WheelX, frame.WheelY
+}
+
+func (s *ScriptedInput) AppendPressedKeys(keys []ebiten.Key) []ebiten.Key {
+	frame, _ := s.current()
+	return append(keys, frame.Keys...)
+}
+
+func (s *ScriptedInput) AppendInputChars(chars []rune) []rune {
+	frame, _ := s.current()
+	return append(chars, []rune(frame.Chars)...)
+}
+
+// Frames for scripts. Positions are in screen pixels.
+
+// Click returns the frames of a left click at x, y: pressed, then released.
+func Click(x, y int) []InputFrame {
+	at := image.Pt(x, y)
+	return []InputFrame{
+		{Cursor: at, Buttons: []ebiten.MouseButton{ebiten.MouseButtonLeft}},
+		{Cursor: at},
+	}
+}
+
+// DoubleClick returns the frames of two left clicks at x, y.
+func DoubleClick(x, y int) []InputFrame {
+	return append(Click(x, y), Click(x, y)...)
+}
+
+// Drag returns the frames of dragging with the left button from one point
+// to another in steps moves.
+func Drag(from, to image.Point, steps int) []InputFrame {
+	held := []ebiten.MouseButton{ebiten.MouseButtonLeft}
+	frames := []InputFrame{{Cursor: from, Buttons: held}}
+	for i := 1; i <= steps; i++ {
+		at := from.Add(to.Sub(from).Mul(i).Div(max(steps, 1)))
+		frames = append(frames, InputFrame{Cursor: at, Buttons: held})
+	}
+	return append(frames, InputFrame{Cursor: to})
+}
+
+// KeyPress returns the frames of pressing and releasing keys together,
+// such as ebiten.KeyControl and ebiten.KeyZ, with the cursor at at.
+func KeyPress(at image.Point, keys ...ebiten.Key) []InputFrame {
+	return []InputFrame{{Cursor: at, Keys: keys}, {Cursor: at}}
+}
+
+// Type returns the frame of typing s with the cursor at at.
+func Type(at image.Point, s string) []InputFrame {
+	return []InputFrame{{Cursor: at, Chars: s}}
+}
+
+// Scroll returns the frame of turning the wheel by dy notches at x, y;
+// positive dy scrolls up.
+func Scroll(x, y int, dy float64) []InputFrame {
+	return []InputFrame{{Cursor: image.Pt(x, y), WheelY: dy}}
+}
@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// idle is an input with nothing pressed, for tests that drive a Keyboard
+// directly.
+var idle = NewScriptedInput()
+
+// testNow is the time the tests' clocks start at, so that runs do not
+// depend on when they happen.
+var testNow = time.Date(2024, time.March, 13, 10, 30, 0, 0, time.UTC)
+
+const frameTime = time.Second / 60
+
+// play runs in frame by frame, polling kb and advancing clock like the game
+// loop does.
+func play(in *ScriptedInput, kb *Keyboard, clock *models.FakeClock, update func(InputSource)) {
+	in.Run(func() {
+		kb.Poll(in, clock.Now())
+		update(in)
+		clock.Advance(frameTime)
+	})
+}
+
+func TestScriptedInputButtonEdges(t *testing.T) {
+	in := NewScriptedInput(Click(10, 20)...)
+	left := ebiten.MouseButtonLeft
+
+	in.Next()
+	if !in.IsMouseButtonPressed(left) || !in.IsMouseButtonJustPressed(left) || in.IsMouseButtonJustReleased(left) {
+		t.Error("Expected the first frame to press the button")
+	}
+	in.Next()
+	if in.IsMouseButtonPressed(left) || in.IsMouseButtonJustPressed(left) || !in.IsMouseButtonJustReleased(left) {
+		t.Error("Expected the second frame to release the button")
+	}
+	if in.Next() {
+		t.Error("Expected the script to be over")
+	}
+	if x, y := in.CursorPosition(); x != 10 || y != 20 || in.IsMouseButtonPressed(left) {
+		t.Errorf("Expected an idle cursor at 10,20 after the script, got %d,%d", x, y)
+	}
+}
+
+func TestDrag(t *testing.T) {
+	frames := Drag(image.Pt(0, 0), image.Pt(30, 60), 3)
+	if len(frames) != 5 {
+		t.Fatalf("Expected 5 frames, got %d", len(frames))
+	}
+	if got := frames[2].Cursor; got != image.Pt(20, 40) {
+		t.Errorf("Expected the second move to 20,40, got %v", got)
+	}
+	if len(frames[3].Buttons) != 1 || len(frames[4].Buttons) != 0 {
+		t.Error("Expected the button held until the last frame")
+	}
+}
+
+func TestButtonClick(t *testing.T) {
+	clicks := 0
+	b := NewButton(10, 10, 80, 30, "Add", func() { clicks++ })
+	clock := models.NewFakeClock(testNow)
+	kb := NewKeyboard(DefaultKeyRepeatDelay, DefaultKeyRepeatInterval)
+
+	// Pressing inside and releasing outside cancels the click
+	in := NewScriptedInput(Click(50, 20)...)
+	in.Add(Drag(image.Pt(50, 20), image.Pt(200, 20), 2)...)
+	play(in, kb, clock, b.Update)
+
+	if clicks != 1 {
+		t.Errorf("Expected 1 click, got %d", clicks)
+	}
+}
+
+func newTestTodoItem(text string) (*TodoItem, *Keyboard, *models.FakeClock) {
+	clock := models.NewFakeClock(testNow)
+	todo := models.NewTodo(text, clock, &models.SequentialIDs{Prefix: "todo-"})
+	item := NewTodoItem(&todo, 0, 100, 400, 40)
+	item.SetClock(clock)
+	kb := NewKeyboard(DefaultKeyRepeatDelay, DefaultKeyRepeatInterval)
+	item.EditTextBox.Keyboard = kb
+	return item, kb, clock
+}
+
+func TestTodoItemDoubleClickEditsAndEnterSaves(t *testing.T) {
+	item, kb, clock := newTestTodoItem("Buy milk")
+
+	in := NewScriptedInput(DoubleClick(200, 120)...)
+	play(in, kb, clock, item.Update)
+	if !item.Editing || !item.EditTextBox.Focused {
+		t.Fatal("Expected a double-click to start editing")
+	}
+
+	in.Add(Type(image.Pt(200, 120), " and eggs")...)
+	in.Add(KeyPress(image.Pt(200, 120), ebiten.KeyEnter)...)
+	play(in, kb, clock, item.Update)
+
+	if item.Editing {
+		t.Error("Expected Enter to finish editing")
+	}
+	if item.Todo.Text != "Buy milk and eggs" {
+		t.Errorf("Expected 'Buy milk and eggs', got %q", item.Todo.Text)
+	}
+	if item.Todo.Completed {
+		t.Error("Expected the double-click not to toggle the todo")
+	}
+}
+
+func TestTodoItemEscapeCancelsEdit(t *testing.T) {
+	item, kb, clock := newTestTodoItem("Buy milk")
+
+	in := NewScriptedInput(DoubleClick(200, 120)...)
+	in.Add(Type(image.Pt(200, 120), " and eggs")...)
+	in.Add(KeyPress(image.Pt(200, 120), ebiten.KeyEscape)...)
+	play(in, kb, clock, item.Update)
+
+	if item.Editing {
+		t.Error("Expected Escape to stop editing")
+	}
+	if item.Todo.Text != "Buy milk" {
+		t.Errorf("Expected the text to stay 'Buy milk', got %q", item.Todo.Text)
+	}
+}
+
+func TestTodoItemSlowClicksDoNotEdit(t *testing.T) {
+	item, kb, clock := newTestTodoItem("Buy milk")
+
+	in := NewScriptedInput(Click(200, 120)...)
+	play(in, kb, clock, item.Update)
+	clock.Advance(time.Second)
+	in.Add(Click(200, 120)...)
+	play(in, kb, clock, item.Update)
+
+	if item.Editing {
+		t.Error("Expected two slow clicks not to start editing")
+	}
+}
+
+func TestListViewScrollsWithWheel(t *testing.T) {
+	l, _ := newTestList(100)
+	clock := models.NewFakeClock(testNow)
+	kb := NewKeyboard(DefaultKeyRepeatDelay, DefaultKeyRepeatInterval)
+
+	// The wheel only scrolls the list under the cursor
+	in := NewScriptedInput(Scroll(200, 50, -3)...)
+	play(in, kb, clock, l.Update)
+	settle(l)
+	if l.ScrollOffset() != 0 {
+		t.Errorf("Expected the wheel outside the list to do nothing, got offset %d", l.ScrollOffset())
+	}
+
+	in.Add(Scroll(200, 300, -3)...)
+	play(in, kb, clock, l.Update)
+	settle(l)
+	down := l.ScrollOffset()
+	if down <= 0 {
+		t.Fatalf("Expected scrolling down to move the list, got offset %d", down)
+	}
+
+	in.Add(Scroll(200, 300, 1)...)
+	play(in, kb, clock, l.Update)
+	settle(l)
+	if up := l.ScrollOffset(); up >= down {
+		t.Errorf("Expected scrolling up to move back from %d, got %d", down, up)
+	}
+}
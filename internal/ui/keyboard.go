@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"time"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	DefaultKeyRepeatDelay    = 500 * time.Millisecond
+	DefaultKeyRepeatInterval = 33 * time.Millisecond
+)
+
+// Keyboard turns the raw set of pressed keys and typed characters of each
+// frame into press, auto-repeat and character events. Widgets read keyboard
+// input only through a Keyboard so that every key is handled exactly once
+// and held keys repeat consistently.
+type Keyboard struct {
+	RepeatDelay    time.Duration
+	RepeatInterval time.Duration
+
+	pressedAt   map[ebiten.Key]time.Time
+	lastFired   map[ebiten.Key]time.Time
+	justPressed map[ebiten.Key]bool
+	triggered   map[ebiten.Key]bool
+	chars       []rune
+}
+
+func NewKeyboard(repeatDelay, repeatInterval time.Duration) *Keyboard {
+	return &Keyboard{
+		RepeatDelay:    repeatDelay,
+		RepeatInterval: repeatInterval,
+		pressedAt:      make(map[ebiten.Key]time.Time),
+		lastFired:      make(map[ebiten.Key]time.Time),
+		justPressed:    make(map[ebiten.Key]bool),
+		triggered:      make(map[ebiten.Key]bool),
+	}
+}
+
+// DefaultKeyboard is shared by all widgets created without an explicit
+// keyboard. The owner of the main loop polls it once per frame.
+var DefaultKeyboard = NewKeyboard(DefaultKeyRepeatDelay, DefaultKeyRepeatInterval)
+
+// Poll reads the key state of in at now. Call it once per frame before
+// updating widgets.
+func (k *Keyboard) Poll(in InputSource, now time.Time) {
+	k.Update(now, in.AppendPressedKeys(nil), in.AppendInputChars(nil))
+}
+
+// Update advances the keyboard to a new frame given the keys held down and
+// the characters typed since the previous frame.
+func (k *Keyboard) Update(now time.Time, pressed []ebiten.Key, chars []rune) {
+	clear(k.justPressed)
+	clear(k.triggered)
+
+	held := make(map[ebiten.Key]bool, len(pressed))
+	for _, key := range pressed {
+		held[key] = true
+		if modifier, ok := modifierKeys[key]; ok {
+			held[modifier] = true
+		}
+	}
+
+	for key := range k.pressedAt {
+		if !held[key] {
+			delete(k.pressedAt, key)
+			delete(k.lastFired, key)
+		}
+	}
+
+	for key := range held {
+		pressedAt, ok := k.pressedAt[key]
+		if !ok {
+			k.pressedAt[key] = now
+			k.lastFired[key] = now
+			k.justPressed[key] = true
+			k.triggered[key] = true
+			continue
+		}
+		if now.Sub(pressedAt) >= k.RepeatDelay && now.Sub(k.lastFired[key]) >= k.RepeatInterval {
+			k.lastFired[key] = now
+			k.triggered[key] = true
+		}
+	}
+
+	// Control characters such as '\b' and '\r' are delivered as key events,
+	// and characters typed while a shortcut modifier is held belong to the
+	// shortcut, so neither is reported as text. Characters typed with AltGr
+	// are text.
+	k.chars = k.chars[:0]
+	if k.IsShortcutModifierPressed() {
+		return
+	}
+	for _, r := range chars {
+		if !unicode.IsControl(r) {
+			k.chars = append(k.chars, r)
+		}
+	}
+}
+
+func (k *Keyboard) IsPressed(key ebiten.Key) bool {
+	_, ok := k.pressedAt[key]
+	return ok
+}
+
+// IsJustPressed reports whether key went down in the current frame.
+func (k *Keyboard) IsJustPressed(key ebiten.Key) bool {
+	return k.justPressed[key]
+}
+
+// IsTriggered reports whether key went down in the current frame or is
+// held down long enough to auto-repeat.
+func (k *Keyboard) IsTriggered(key ebiten.Key) bool {
+	return k.triggered[key]
+}
+
+// Chars returns the printable characters typed in the current frame.
+func (k *Keyboard) Chars() []rune {
+	return k.chars
+}
+
+func (k *Keyboard) IsShiftPressed() bool {
+	return k.IsPressed(ebiten.KeyShift)
+}
+
+// IsShortcutModifierPressed reports whether Ctrl (or Cmd on macOS) is held.
+// Ctrl held with Alt does not count, as that is how Windows reports AltGr,
+// which types characters such as @ and { on many layouts.
+func (k *Keyboard) IsShortcutModifierPressed() bool {
+	ctrl := k.IsPressed(ebiten.KeyControl) && !k.IsPressed(ebiten.KeyAlt)
+	return ctrl || k.IsPressed(ebiten.KeyMeta)
+}
+
+// modifierKeys maps sided modifier keys to their side-independent key so
+// that synthetic input may report either.
+var modifierKeys = map[ebiten.Key]ebiten.Key{
+	ebiten.KeyShiftLeft:    ebiten.KeyShift,
+	ebiten.KeyShiftRight:   ebiten.KeyShift,
+	ebiten.KeyControlLeft:  ebiten.KeyControl,
+	ebiten.KeyControlRight: ebiten.KeyControl,
+	ebiten.KeyAltLeft:      ebiten.KeyAlt,
+	ebiten.KeyAltRight:     ebiten.KeyAlt,
+	ebiten.KeyMetaLeft:     ebiten.KeyMeta,
+	ebiten.KeyMetaRight:    ebiten.KeyMeta,
+}
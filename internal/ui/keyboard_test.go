@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// keyFrame is one frame of a synthetic key timeline.
+type keyFrame struct {
+	at      time.Duration
+	pressed []ebiten.Key
+	chars   []rune
+}
+
+func runKeyTimeline(kb *Keyboard, frames []keyFrame, each func(i int)) {
+	for i, f := range frames {
+		kb.Update(testNow.Add(f.at), f.pressed, f.chars)
+		each(i)
+	}
+}
+
+func TestKeyboardAutoRepeat(t *testing.T) {
+	kb := NewKeyboard(500*time.Millisecond, 50*time.Millisecond)
+	left := []ebiten.Key{ebiten.KeyArrowLeft}
+
+	frames := []keyFrame{
+		{at: 0, pressed: left},
+		{at: 100 * time.Millisecond, pressed: left},
+		{at: 499 * time.Millisecond, pressed: left},
+		{at: 500 * time.Millisecond, pressed: left},
+		{at: 520 * time.Millisecond, pressed: left},
+		{at: 550 * time.Millisecond, pressed: left},
+		{at: 600 * time.Millisecond},
+		{at: 700 * time.Millisecond, pressed: left},
+	}
+	want := []bool{true, false, false, true, false, true, false, true}
+
+	runKeyTimeline(kb, frames, func(i int) {
+		if got := kb.IsTriggered(ebiten.KeyArrowLeft); got != want[i] {
+			t.Errorf("frame %d: expected triggered=%v, got %v", i, want[i], got)
+		}
+	})
+}
+
+func TestKeyboardJustPressedDoesNotRepeat(t *testing.T) {
+	kb := NewKeyboard(100*time.Millisecond, 10*time.Millisecond)
+	enter := []ebiten.Key{ebiten.KeyEnter}
+
+	frames := []keyFrame{
+		{at: 0, pressed: enter},
+		{at: 200 * time.Millisecond, pressed: enter},
+	}
+	want := []bool{true, false}
+
+	runKeyTimeline(kb, frames, func(i int) {
+		if got := kb.IsJustPressed(ebiten.KeyEnter); got != want[i] {
+			t.Errorf("frame %d: expected just pressed=%v, got %v", i, want[i], got)
+		}
+	})
+}
+
+func TestKeyboardCharsDeduplicated(t *testing.T) {
+	kb := NewKeyboard(DefaultKeyRepeatDelay, DefaultKeyRepeatInterval)
+	kb.Update(testNow, []ebiten.Key{ebiten.KeyBackspace}, []rune{'a', '\b', '\r'})
+	if got := string(kb.Chars()); got != "a" {
+		t.Errorf("Expected control characters to be dropped, got %q", got)
+	}
+
+	kb.Update(testNow, []ebiten.Key{ebiten.KeyControlLeft, ebiten.KeyV}, []rune{'v'})
+	if len(kb.Chars()) != 0 {
+		t.Errorf("Expected no chars while Ctrl is held, got %q", string(kb.Chars()))
+	}
+	if !kb.IsShortcutModifierPressed() {
+		t.Error("Expected left Control to count as shortcut modifier")
+	}
+}
+
+func TestTextBoxBackspaceRepeatsOnce(t *testing.T) {
+	kb := NewKeyboard(300*time.Millisecond, 100*time.Millisecond)
+	tb := newTestTextBox("abcdef")
+	tb.Keyboard = kb
+	tb.Focused = true
+	backspace := []ebiten.Key{ebiten.KeyBackspace}
+
+	frames := []keyFrame{
+		{at: 0, pressed: backspace, chars: []rune{'\b'}},
+		{at: 100 * time.Millisecond, pressed: backspace},
+		{at: 300 * time.Millisecond, pressed: backspace},
+		{at: 400 * time.Millisecond, pressed: backspace},
+		{at: 450 * time.Millisecond},
+	}
+	want := []string{"abcde", "abcde", "abcd", "abc", "abc"}
+
+	runKeyTimeline(kb, frames, func(i int) {
+		tb.Update(idle)
+		if tb.Text != want[i] {
+			t.Errorf("frame %d: expected %q, got %q", i, want[i], tb.Text)
+		}
+	})
+}
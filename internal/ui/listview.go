@@ -0,0 +1,406 @@
+package ui
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ListRow is a widget shown as a row of a ListView.
+type ListRow interface {
+	Update(in InputSource)
+	Draw(screen *ebiten.Image)
+	SetPosition(x, y int)
+	SetWidth(width int)
+}
+
+const (
+	ScrollbarWidth  = 8
+	scrollbarGap    = 4
+	minThumbHeight  = 20
+	wheelImpulse    = 12   // Velocity added per wheel notch, in pixels per frame
+	scrollFriction  = 0.85 // Fraction of the velocity kept each frame
+	scrollEasing    = 0.35 // Fraction of the distance to the target covered each frame
+	scrollRestSpeed = 0.5
+)
+
+// ListView is a vertically scrolling list that only creates widgets for
+// the rows in view, so its cost does not grow with the number of rows.
+// Rows may have different heights. Scrolling with the wheel carries on
+// with decaying speed, programmatic scrolling eases to its target, and the
+// scroll offset is always kept within the content.
+type ListView struct {
+	X, Y, Width, Height int
+	// RowHeight returns the height of row i.
+	RowHeight func(i int) int
+	// NewRow creates the widget for row i when it comes into view.
+	NewRow func(i int) ListRow
+	// DropRow, when set, is called with a row widget about to be dropped,
+	// so that state the widget holds can be kept for the next one.
+	DropRow func(i int, row ListRow)
+
+	count    int
+	offsets  []int // offsets[i] is the top of row i, offsets[count] the content height
+	rows     map[int]ListRow
+	first    int // Rows first to last-1 are in view
+	last     int
+	scroll   float64
+	target   float64
+	velocity float64
+
+	dragging   bool
+	dragOffset int // Cursor position within the thumb when the drag started
+}
+
+func NewListView(x, y, width, height int) *ListView {
+	return &ListView{
+		X:      x,
+		Y:      y,
+		Width:  width,
+		Height: height,
+		rows:   make(map[int]ListRow),
+	}
+}
+
+// SetCount sets the number of rows and drops every row widget, so that
+// the rows are created afresh from the current data. Call it whenever the
+// rows or their heights change.
+func (l *ListView) SetCount(count int) {
+	l.count = count
+	if cap(l.offsets) < count+1 {
+		l.offsets = make([]int, count+1)
+	}
+	l.offsets = l.offsets[:count+1]
+	for i := 0; i < count; i++ {
+		l.offsets[i+1] = l.offsets[i] + l.rowHeight(i)
+	}
+
+	l.dropRows(func(int) bool { return true })
+	l.scroll = l.clampScroll(l.scroll)
+	l.target = l.clampScroll(l.target)
+	l.syncRows()
+}
+
+func (l *ListView) Count() int {
+	return l.count
+}
+
+// SetBounds moves and resizes the list, keeping the scroll offset.
+func (l *ListView) SetBounds(r image.Rectangle) {
+	l.X, l.Y, l.Width, l.Height = r.Min.X, r.Min.Y, r.Dx(), r.Dy()
+	l.scroll = l.clampScroll(l.scroll)
+	l.target = l.clampScroll(l.target)
+	l.syncRows()
+}
+
+func (l *ListView) Bounds() image.Rectangle {
+	return image.Rect(l.X, l.Y, l.X+l.Width, l.Y+l.Height)
+}
+
+func (l *ListView) Contains(x, y int) bool {
+	return image.Pt(x, y).In(l.Bounds())
+}
+
+// ContentHeight returns the height of all rows together.
+func (l *ListView) ContentHeight() int {
+	if len(l.offsets) == 0 {
+		return 0
+	}
+	return l.offsets[l.count]
+}
+
+// MaxScroll returns the largest scroll offset, where the last row touches
+// the bottom of the list.
+func (l *ListView) MaxScroll() int {
+	return max(l.ContentHeight()-l.Height, 0)
+}
+
+func (l *ListView) ScrollOffset() int {
+	return int(math.Round(l.scroll))
+}
+
+// SetScrollOffset scrolls to offset at once.
+func (l *ListView) SetScrollOffset(offset int) {
+	l.scroll = l.clampScroll(float64(offset))
+	l.target = l.scroll
+	l.velocity = 0
+	l.syncRows()
+}
+
+// ScrollTo eases to offset over the next frames.
+func (l *ListView) ScrollTo(offset int) {
+	l.target = l.clampScroll(float64(offset))
+	l.velocity = 0
+}
+
+// ScrollBy adds momentum as a wheel notch does; positive dy scrolls down.
+func (l *ListView) ScrollBy(dy float64) {
+	l.velocity += dy
+}
+
+// ScrollToRow eases the least distance that brings row i fully into view.
+func (l *ListView) ScrollToRow(i int) {
+	if i < 0 || i >= l.count {
+		return
+	}
+	top, bottom := l.offsets[i], l.offsets[i+1]
+	target := int(math.Round(l.target))
+	if top < target {
+		l.ScrollTo(top)
+	} else if bottom > target+l.Height {
+		l.ScrollTo(bottom - l.Height)
+	}
+}
+
+// IsScrolling reports whether the list is still moving towards its target
+// or coasting.
+func (l *ListView) IsScrolling() bool {
+	return l.velocity != 0 || l.scroll != l.target
+}
+
+// VisibleRange returns the rows in view as first and one past the last.
+func (l *ListView) VisibleRange() (int, int) {
+	return l.first, l.last
+}
+
+// Row returns the widget of row i, creating it if it is not in view.
+// Rows out of view are dropped again when the list next scrolls.
+func (l *ListView) Row(i int) ListRow {
+	if i < 0 || i >= l.count {
+		return nil
+	}
+	if row, ok := l.rows[i]; ok {
+		return row
+	}
+	row := l.NewRow(i)
+	l.rows[i] = row
+	l.placeRow(i, row)
+	return row
+}
+
+// ForEachRow calls fn for every row that currently has a widget.
+func (l *ListView) ForEachRow(fn func(i int, row ListRow)) {
+	for i, row := range l.rows {
+		fn(i, row)
+	}
+}
+
+// RowBounds returns where row i is drawn, which may be outside the list.
+func (l *ListView) RowBounds(i int) image.Rectangle {
+	if i < 0 || i >= l.count {
+		return image.Rectangle{}
+	}
+	y := l.Y + l.offsets[i] - l.ScrollOffset()
+	return image.Rect(l.X, y, l.X+l.rowWidth(), y+l.offsets[i+1]-l.offsets[i])
+}
+
+// RowAt returns the row under a point inside the list.
+func (l *ListView) RowAt(x, y int) (int, bool) {
+	if !l.Contains(x, y) || x >= l.X+l.rowWidth() {
+		return 0, false
+	}
+	i := l.rowAtOffset(y - l.Y + l.ScrollOffset())
+	if i >= l.count {
+		return 0, false
+	}
+	return i, true
+}
+
+func (l *ListView) Update(in InputSource) {
+	mouseX, mouseY := CursorPosition(in)
+	if l.Contains(mouseX, mouseY) {
+		if _, dy := in.Wheel(); dy != 0 {
+			l.ScrollBy(-dy * wheelImpulse)
+		}
+	}
+	l.updateScrollbar(in, mouseX, mouseY)
+	l.step()
+	l.syncRows()
+
+	// Rows may rebuild the list while updating, so update a snapshot
+	rows := make([]ListRow, 0, l.last-l.first)
+	for i := l.first; i < l.last; i++ {
+		rows = append(rows, l.rows[i])
+	}
+	for _, row := range rows {
+		row.Update(in)
+	}
+}
+
+func (l *ListView) Draw(screen *ebiten.Image) {
+	// Rows are clipped to the list
+	clip := Clip(screen, l.Bounds())
+	for i := l.first; i < l.last; i++ {
+		if row, ok := l.rows[i]; ok {
+			row.Draw(clip)
+		}
+	}
+	l.drawScrollbar(screen)
+}
+
+// step advances coasting and easing by one frame.
+func (l *ListView) step() {
+	if l.dragging {
+		return
+	}
+	if l.velocity != 0 {
+		l.scroll += l.velocity
+		l.velocity *= scrollFriction
+		if math.Abs(l.velocity) < scrollRestSpeed {
+			l.velocity = 0
+		}
+		if clamped := l.clampScroll(l.scroll); clamped != l.scroll {
+			l.scroll = clamped
+			l.velocity = 0
+		}
+		l.target = l.scroll
+		return
+	}
+	if l.scroll != l.target {
+		l.scroll += (l.target - l.scroll) * scrollEasing
+		if math.Abs(l.target-l.scroll) < scrollRestSpeed {
+			l.scroll = l.target
+		}
+	}
+}
+
+// syncRows creates the rows that came into view, drops those that left,
+// and positions the rest.
+func (l *ListView) syncRows() {
+	if l.count == 0 {
+		l.first, l.last = 0, 0
+		l.dropRows(func(int) bool { return true })
+		return
+	}
+	l.first, l.last = l.rangeAt(l.ScrollOffset())
+
+	// Rows at the scroll target are kept as well, so a row that is being
+	// scrolled to can be used before it arrives
+	targetFirst, targetLast := l.rangeAt(int(math.Round(l.target)))
+	l.dropRows(func(i int) bool {
+		inView := i >= l.first && i < l.last
+		atTarget := i >= targetFirst && i < targetLast
+		return !inView && !atTarget
+	})
+	for i := l.first; i < l.last; i++ {
+		l.placeRow(i, l.Row(i))
+	}
+}
+
+// dropRows drops the row widgets for which drop returns true.
+func (l *ListView) dropRows(drop func(i int) bool) {
+	for i, row := range l.rows {
+		if !drop(i) {
+			continue
+		}
+		delete(l.rows, i)
+		if l.DropRow != nil {
+			l.DropRow(i, row)
+		}
+	}
+}
+
+func (l *ListView) placeRow(i int, row ListRow) {
+	r := l.RowBounds(i)
+	row.SetWidth(r.Dx())
+	row.SetPosition(r.Min.X, r.Min.Y)
+}
+
+// rangeAt returns the rows in view at the scroll offset, as first and one
+// past the last.
+func (l *ListView) rangeAt(offset int) (int, int) {
+	return l.rowAtOffset(offset), min(l.rowAtOffset(offset+l.Height-1)+1, l.count)
+}
+
+// rowAtOffset returns the row that covers the content offset y.
+func (l *ListView) rowAtOffset(y int) int {
+	return max(sort.SearchInts(l.offsets[1:], y+1), 0)
+}
+
+// rowWidth leaves room for the scrollbar.
+func (l *ListView) rowWidth() int {
+	return max(l.Width-ScrollbarWidth-scrollbarGap, 0)
+}
+
+func (l *ListView) rowHeight(i int) int {
+	if l.RowHeight == nil {
+		return 0
+	}
+	return l.RowHeight(i)
+}
+
+func (l *ListView) clampScroll(offset float64) float64 {
+	return math.Max(0, math.Min(offset, float64(l.MaxScroll())))
+}
+
+// thumbRect returns the scrollbar thumb, or an empty rectangle when all
+// rows fit.
+func (l *ListView) thumbRect() image.Rectangle {
+	content := l.ContentHeight()
+	if content <= l.Height || l.Height <= 0 {
+		return image.Rectangle{}
+	}
+	thumbHeight := max(l.Height*l.Height/content, minThumbHeight)
+	thumbY := l.Y + int(l.scroll/float64(l.MaxScroll())*float64(l.Height-thumbHeight))
+	x := l.X + l.Width - ScrollbarWidth
+	return image.Rect(x, thumbY, x+ScrollbarWidth, thumbY+thumbHeight)
+}
+
+// scrollForThumb returns the scroll offset that puts the top of the thumb
+// at y.
+func (l *ListView) scrollForThumb(y int) float64 {
+	track := l.Height - l.thumbRect().Dy()
+	if track <= 0 {
+		return 0
+	}
+	return l.clampScroll(float64(y-l.Y) / float64(track) * float64(l.MaxScroll()))
+}
+
+// updateScrollbar drags the thumb, or pages towards a click on the track.
+func (l *ListView) updateScrollbar(in InputSource, mouseX, mouseY int) {
+	thumb := l.thumbRect()
+	if l.dragging {
+		if !in.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+			l.dragging = false
+			return
+		}
+		l.scroll = l.scrollForThumb(mouseY - l.dragOffset)
+		l.target = l.scroll
+		return
+	}
+	if thumb.Empty() || !in.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+
+	track := image.Rect(thumb.Min.X, l.Y, thumb.Max.X, l.Y+l.Height)
+	switch pt := image.Pt(mouseX, mouseY); {
+	case pt.In(thumb):
+		l.dragging = true
+		l.dragOffset = mouseY - thumb.Min.Y
+		l.velocity = 0
+	case pt.In(track) && mouseY < thumb.Min.Y:
+		l.ScrollTo(int(l.target) - l.Height)
+	case pt.In(track):
+		l.ScrollTo(int(l.target) + l.Height)
+	}
+}
+
+func (l *ListView) drawScrollbar(screen *ebiten.Image) {
+	thumb := l.thumbRect()
+	if thumb.Empty() {
+		return
+	}
+	theme := CurrentTheme()
+
+	// Draw track
+	DrawRect(screen, float64(thumb.Min.X), float64(l.Y), ScrollbarWidth, float64(l.Height), theme.SurfaceHover)
+
+	// Draw thumb
+	thumbColor := theme.Secondary
+	if l.dragging {
+		thumbColor = theme.SecondaryHover
+	}
+	DrawRect(screen, float64(thumb.Min.X), float64(thumb.Min.Y), float64(thumb.Dx()), float64(thumb.Dy()), thumbColor)
+}
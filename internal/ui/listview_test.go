@@ -0,0 +1,225 @@
+package ui
+
+import (
+	"image"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type fakeRow struct {
+	x, y, width int
+	updates     int
+}
+
+func (r *fakeRow) Update(InputSource)   { r.updates++ }
+func (r *fakeRow) Draw(*ebiten.Image)   {}
+func (r *fakeRow) SetPosition(x, y int) { r.x, r.y = x, y }
+func (r *fakeRow) SetWidth(width int)   { r.width = width }
+
+// newTestList returns a 500px high list of count rows that are 50px high,
+// and counts the rows it creates.
+func newTestList(count int) (*ListView, *int) {
+	created := 0
+	l := NewListView(0, 100, 412, 500)
+	l.RowHeight = func(int) int { return 50 }
+	l.NewRow = func(int) ListRow {
+		created++
+		return &fakeRow{}
+	}
+	l.SetCount(count)
+	return l, &created
+}
+
+// settle runs frames until the list stops moving.
+func settle(l *ListView) {
+	for i := 0; i < 200 && l.IsScrolling(); i++ {
+		l.step()
+		l.syncRows()
+	}
+}
+
+func TestListViewCreatesOnlyVisibleRows(t *testing.T) {
+	l, created := newTestList(50000)
+
+	if first, last := l.VisibleRange(); first != 0 || last != 10 {
+		t.Errorf("Expected rows 0-10 in view, got %d-%d", first, last)
+	}
+	if *created != 10 {
+		t.Errorf("Expected 10 rows to be created, got %d", *created)
+	}
+
+	l.SetScrollOffset(25 * 50)
+	rows := 0
+	l.ForEachRow(func(int, ListRow) { rows++ })
+	if first, last := l.VisibleRange(); first != 25 || last != 35 || rows != 10 {
+		t.Errorf("Expected only rows 25-35 to exist, got %d-%d with %d rows", first, last, rows)
+	}
+
+	// Rows are placed at their offset and leave room for the scrollbar
+	row := l.Row(26).(*fakeRow)
+	if row.y != 150 || row.width != 400 {
+		t.Errorf("Expected row 26 at y=150 with width 400, got y=%d width=%d", row.y, row.width)
+	}
+	if i, ok := l.RowAt(10, 160); !ok || i != 26 {
+		t.Errorf("Expected row 26 under (10,160), got %d, %v", i, ok)
+	}
+	if _, ok := l.RowAt(10, 99); ok {
+		t.Error("Expected no row above the list")
+	}
+}
+
+func TestListViewDropRow(t *testing.T) {
+	l, _ := newTestList(100)
+	var dropped []int
+	l.DropRow = func(i int, _ ListRow) { dropped = append(dropped, i) }
+
+	// Scrolling drops the rows that left the view
+	l.SetScrollOffset(5 * 50)
+	if len(dropped) != 5 {
+		t.Errorf("Expected the 5 rows scrolled past to be dropped, got %v", dropped)
+	}
+
+	// Refreshing drops every row
+	dropped = nil
+	l.SetCount(100)
+	if len(dropped) != 10 {
+		t.Errorf("Expected the 10 rows in view to be dropped, got %v", dropped)
+	}
+}
+
+func TestListViewVariableHeights(t *testing.T) {
+	l, _ := newTestList(0)
+	l.RowHeight = func(i int) int {
+		if i == 1 {
+			return 220
+		}
+		return 50
+	}
+	l.SetCount(20)
+
+	if got := l.ContentHeight(); got != 19*50+220 {
+		t.Errorf("Expected content height %d, got %d", 19*50+220, got)
+	}
+	if got := l.RowBounds(2); got != image.Rect(0, 370, 400, 420) {
+		t.Errorf("Expected row 2 below the tall row, got %v", got)
+	}
+}
+
+func TestListViewScrollIsClamped(t *testing.T) {
+	l, _ := newTestList(20)
+
+	l.SetScrollOffset(-100)
+	if got := l.ScrollOffset(); got != 0 {
+		t.Errorf("Expected scroll clamped to 0, got %d", got)
+	}
+	l.SetScrollOffset(100000)
+	if got := l.ScrollOffset(); got != 500 {
+		t.Errorf("Expected scroll clamped to 500, got %d", got)
+	}
+
+	// Coasting stops at the end instead of running past it
+	l.ScrollBy(400)
+	settle(l)
+	if got := l.ScrollOffset(); got != 500 {
+		t.Errorf("Expected coasting to stop at 500, got %d", got)
+	}
+
+	// Shrinking the list pulls the offset back
+	l.SetCount(12)
+	if got := l.ScrollOffset(); got != 100 {
+		t.Errorf("Expected scroll 100 after shrinking, got %d", got)
+	}
+	l.SetCount(3)
+	if got := l.ScrollOffset(); got != 0 {
+		t.Errorf("Expected scroll 0 when everything fits, got %d", got)
+	}
+}
+
+func TestListViewKineticScroll(t *testing.T) {
+	l, _ := newTestList(1000)
+
+	l.ScrollBy(wheelImpulse)
+	l.step()
+	first := l.ScrollOffset()
+	settle(l)
+	if first <= 0 || l.ScrollOffset() <= first {
+		t.Errorf("Expected the list to keep moving after the wheel, got %d then %d", first, l.ScrollOffset())
+	}
+	if l.IsScrolling() {
+		t.Error("Expected the list to come to rest")
+	}
+}
+
+func TestListViewScrollToRow(t *testing.T) {
+	l, _ := newTestList(1000)
+
+	l.ScrollToRow(500)
+	// The target row exists before the list gets there
+	if _, ok := l.Row(500).(*fakeRow); !ok {
+		t.Fatal("Expected row 500 to be available while scrolling")
+	}
+	l.step()
+	l.syncRows()
+	if got := l.ScrollOffset(); got <= 0 || got >= 500*50 {
+		t.Errorf("Expected the list to ease towards the row, got %d", got)
+	}
+	settle(l)
+	if got, want := l.ScrollOffset(), 501*50-500; got != want {
+		t.Errorf("Expected row 500 at the bottom (scroll %d), got %d", want, got)
+	}
+
+	// A row already in view does not scroll
+	l.ScrollToRow(495)
+	settle(l)
+	if got, want := l.ScrollOffset(), 501*50-500; got != want {
+		t.Errorf("Expected no scroll for a visible row, got %d", got)
+	}
+
+	l.ScrollToRow(10)
+	settle(l)
+	if got := l.ScrollOffset(); got != 500 {
+		t.Errorf("Expected row 10 at the top, got %d", got)
+	}
+}
+
+func TestListViewScrollbarThumb(t *testing.T) {
+	l, _ := newTestList(20) // 1000px of rows in a 500px list
+
+	thumb := l.thumbRect()
+	if thumb != image.Rect(404, 100, 412, 350) {
+		t.Errorf("Expected a half-height thumb at the top, got %v", thumb)
+	}
+
+	// Dragging the thumb to the bottom of the track scrolls to the end
+	if got := l.scrollForThumb(350); got != 500 {
+		t.Errorf("Expected scroll 500 with the thumb at the bottom, got %v", got)
+	}
+	if got := l.scrollForThumb(225); got != 250 {
+		t.Errorf("Expected scroll 250 with the thumb half way, got %v", got)
+	}
+	if got := l.scrollForThumb(1000); got != 500 {
+		t.Errorf("Expected the drag to be clamped, got %v", got)
+	}
+
+	l.SetCount(5)
+	if !l.thumbRect().Empty() {
+		t.Error("Expected no scrollbar when all rows fit")
+	}
+}
+
+func BenchmarkListViewScroll50k(b *testing.B) {
+	l, _ := newTestList(50000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.SetScrollOffset(i * 137 % l.MaxScroll())
+	}
+}
+
+func BenchmarkListViewSetCount50k(b *testing.B) {
+	l, _ := newTestList(50000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.SetCount(50000)
+	}
+}
@@ -0,0 +1,258 @@
+package ui
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/fuzzy"
+)
+
+// PaletteItem is an entry of the command palette. Detail is shown dimmed on
+// the right, for example the shortcut of a command.
+type PaletteItem struct {
+	Label  string
+	Detail string
+	Run    func()
+}
+
+const (
+	paletteRowHeight  = 28
+	paletteInputSpace = 55
+)
+
+// CommandPalette is a modal list of commands filtered by fuzzy matching on
+// what is typed into its input. The owner focuses Input when opening it.
+type CommandPalette struct {
+	X, Y, Width int
+	MaxVisible  int
+	Visible     bool
+	Input       *TextBox
+	Keyboard    *Keyboard
+	// OnClose is called when the palette closes, before a chosen item runs.
+	OnClose func()
+
+	items    []PaletteItem
+	results  []fuzzy.Result
+	query    string
+	selected int
+	scroll   int
+}
+
+func NewCommandPalette(x, y, width int) *CommandPalette {
+	return &CommandPalette{
+		X:          x,
+		Y:          y,
+		Width:      width,
+		MaxVisible: 8,
+		Input:      NewTextBox(x+10, y+10, width-20, 35, "Type a command or todo..."),
+		Keyboard:   DefaultKeyboard,
+	}
+}
+
+// Open shows the palette with the given items and an empty query.
+func (p *CommandPalette) Open(items []PaletteItem) {
+	p.items = items
+	p.Input.Clear()
+	p.query = ""
+	p.rank()
+	p.Visible = true
+}
+
+func (p *CommandPalette) Close() {
+	if !p.Visible {
+		return
+	}
+	p.Visible = false
+	if p.OnClose != nil {
+		p.OnClose()
+	}
+}
+
+// Results returns the items matching the query, best first.
+func (p *CommandPalette) Results() []PaletteItem {
+	items := make([]PaletteItem, len(p.results))
+	for i, r := range p.results {
+		items[i] = p.items[r.Index]
+	}
+	return items
+}
+
+// Selected returns the index of the selected result.
+func (p *CommandPalette) Selected() int {
+	return p.selected
+}
+
+func (p *CommandPalette) Update(in InputSource) {
+	if !p.Visible {
+		return
+	}
+
+	p.Input.Update(in)
+	if query := p.Input.GetText(); query != p.query {
+		p.query = query
+		p.rank()
+	}
+
+	kb := p.keyboard()
+	switch {
+	case kb.IsJustPressed(ebiten.KeyEscape):
+		p.Close()
+		return
+	case kb.IsJustPressed(ebiten.KeyEnter):
+		p.execute(p.selected)
+		return
+	case kb.IsTriggered(ebiten.KeyArrowDown):
+		p.move(1)
+	case kb.IsTriggered(ebiten.KeyArrowUp):
+		p.move(-1)
+	case kb.IsTriggered(ebiten.KeyPageDown):
+		p.move(p.MaxVisible)
+	case kb.IsTriggered(ebiten.KeyPageUp):
+		p.move(-p.MaxVisible)
+	}
+
+	// Run the clicked row, or close on a click outside the palette
+	if in.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		x, y := CursorPosition(in)
+		if i, ok := p.rowAt(x, y); ok {
+			p.execute(i)
+		} else if !p.Contains(x, y) {
+			p.Close()
+		}
+	}
+}
+
+func (p *CommandPalette) Draw(screen *ebiten.Image) {
+	if !p.Visible {
+		return
+	}
+	theme := CurrentTheme()
+
+	// Draw panel background and border
+	height := p.height()
+	DrawRect(screen, float64(p.X), float64(p.Y), float64(p.Width), float64(height), theme.Surface)
+	DrawRect(screen, float64(p.X), float64(p.Y), float64(p.Width), 1, theme.Border)
+	DrawRect(screen, float64(p.X), float64(p.Y+height-1), float64(p.Width), 1, theme.Border)
+	DrawRect(screen, float64(p.X), float64(p.Y), 1, float64(height), theme.Border)
+	DrawRect(screen, float64(p.X+p.Width-1), float64(p.Y), 1, float64(height), theme.Border)
+
+	p.Input.Draw(screen)
+
+	if len(p.results) == 0 {
+		DrawText(screen, "No matching commands", p.X+20, p.Y+paletteInputSpace+18, theme.TextMuted)
+		return
+	}
+
+	for row := 0; row < p.visibleRows(); row++ {
+		i := p.scroll + row
+		result := p.results[i]
+		item := p.items[result.Index]
+		rowY := p.Y + paletteInputSpace + row*paletteRowHeight
+
+		if i == p.selected {
+			DrawRect(screen, float64(p.X+1), float64(rowY), float64(p.Width-2), paletteRowHeight, theme.SelectedRow)
+		}
+
+		// Draw the detail right-aligned, and the label in the space left
+		baseline := rowY + 18
+		detailWidth := textWidth(item.Detail)
+		DrawText(screen, item.Detail, p.X+p.Width-20-detailWidth, baseline, theme.TextMuted)
+		label := []rune(TruncateText(item.Label, p.Width-60-detailWidth))
+		DrawText(screen, string(label), p.X+20, baseline, theme.Text)
+
+		// Redraw the matched runes in the highlight color
+		for _, pos := range result.Positions {
+			if pos < len(label) {
+				x := p.X + 20 + textWidth(string(label[:pos]))
+				DrawText(screen, string(label[pos]), x, baseline, theme.Accent)
+			}
+		}
+	}
+}
+
+// SetBounds moves the palette to the top left of r and makes it as wide
+// as r. The palette's height follows its results.
+func (p *CommandPalette) SetBounds(r image.Rectangle) {
+	p.X, p.Y, p.Width = r.Min.X, r.Min.Y, r.Dx()
+	p.Input.SetBounds(image.Rect(p.X+10, p.Y+10, p.X+p.Width-10, p.Y+45))
+}
+
+func (p *CommandPalette) Contains(x, y int) bool {
+	return x >= p.X && x <= p.X+p.Width && y >= p.Y && y <= p.Y+p.height()
+}
+
+func (p *CommandPalette) rank() {
+	labels := make([]string, len(p.items))
+	for i, item := range p.items {
+		labels[i] = item.Label
+	}
+	p.results = fuzzy.Rank(p.query, labels)
+	p.selected = 0
+	p.scroll = 0
+}
+
+// move moves the selection by delta rows and scrolls it into view.
+func (p *CommandPalette) move(delta int) {
+	if len(p.results) == 0 {
+		return
+	}
+	p.selected = clamp(p.selected+delta, 0, len(p.results)-1)
+	if p.selected < p.scroll {
+		p.scroll = p.selected
+	} else if p.selected >= p.scroll+p.MaxVisible {
+		p.scroll = p.selected - p.MaxVisible + 1
+	}
+}
+
+func (p *CommandPalette) execute(i int) {
+	if i < 0 || i >= len(p.results) {
+		return
+	}
+	item := p.items[p.results[i].Index]
+	p.Close()
+	if item.Run != nil {
+		item.Run()
+	}
+}
+
+func (p *CommandPalette) rowAt(x, y int) (int, bool) {
+	top := p.Y + paletteInputSpace
+	if x < p.X || x > p.X+p.Width || y < top {
+		return 0, false
+	}
+	row := (y - top) / paletteRowHeight
+	if row >= p.visibleRows() {
+		return 0, false
+	}
+	return p.scroll + row, true
+}
+
+func (p *CommandPalette) visibleRows() int {
+	return min(len(p.results)-p.scroll, p.MaxVisible)
+}
+
+func (p *CommandPalette) height() int {
+	rows := max(p.visibleRows(), 1)
+	return paletteInputSpace + rows*paletteRowHeight + 10
+}
+
+func (p *CommandPalette) keyboard() *Keyboard {
+	if p.Keyboard != nil {
+		return p.Keyboard
+	}
+	return DefaultKeyboard
+}
+
+// TruncateText cuts s to fit in width pixels, ending with an ellipsis when
+// anything was cut.
+func TruncateText(s string, width int) string {
+	if textWidth(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes))+textWidth("...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+func TestCommandPaletteFiltersAndRuns(t *testing.T) {
+	kb := NewKeyboard(DefaultKeyRepeatDelay, DefaultKeyRepeatInterval)
+	p := NewCommandPalette(0, 0, 400)
+	p.Keyboard = kb
+	p.Input.Keyboard = kb
+	p.Input.SetFocus(true)
+
+	var ran []string
+	item := func(label string) PaletteItem {
+		return PaletteItem{Label: label, Run: func() { ran = append(ran, label) }}
+	}
+	p.Open([]PaletteItem{item("Search todos"), item("Show active todos"), item("Show all todos"), item("New todo")})
+
+	clock := models.NewFakeClock(testNow)
+	frame := func(keys []ebiten.Key, chars string) {
+		kb.Update(clock.Now(), keys, []rune(chars))
+		p.Update(idle)
+		clock.Advance(time.Second)
+		kb.Update(clock.Now(), nil, nil)
+	}
+
+	frame(nil, "sa")
+	results := p.Results()
+	if len(results) != 3 || results[0].Label != "Show active todos" {
+		t.Fatalf("Expected 3 results led by 'Show active todos', got %v", results)
+	}
+
+	frame([]ebiten.Key{ebiten.KeyArrowDown}, "")
+	frame([]ebiten.Key{ebiten.KeyArrowDown}, "")
+	frame([]ebiten.Key{ebiten.KeyArrowDown}, "")
+	if p.Selected() != 2 {
+		t.Errorf("Expected the selection to stop at the last result, got %d", p.Selected())
+	}
+	frame([]ebiten.Key{ebiten.KeyArrowUp}, "")
+
+	frame([]ebiten.Key{ebiten.KeyEnter}, "")
+	if len(ran) != 1 || ran[0] != "Show all todos" {
+		t.Errorf("Expected 'Show all todos' to run, got %v", ran)
+	}
+	if p.Visible {
+		t.Error("Expected the palette to close after running an item")
+	}
+}
+
+func TestCommandPaletteEscapeCloses(t *testing.T) {
+	kb := NewKeyboard(DefaultKeyRepeatDelay, DefaultKeyRepeatInterval)
+	p := NewCommandPalette(0, 0, 400)
+	p.Keyboard = kb
+	p.Input.Keyboard = kb
+
+	closed := false
+	p.OnClose = func() { closed = true }
+	p.Open([]PaletteItem{{Label: "New todo"}})
+
+	kb.Update(testNow, []ebiten.Key{ebiten.KeyEscape}, nil)
+	p.Update(idle)
+
+	if p.Visible || !closed {
+		t.Error("Expected Escape to close the palette and call OnClose")
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	if got := TruncateText("Buy milk", 100); got != "Buy milk" {
+		t.Errorf("Expected text that fits to be kept, got %q", got)
+	}
+	// Text is cut between characters, not inside one
+	got := TruncateText("Due Wed 3/13 23:59   #買い物 #仕事", 182)
+	if !utf8.ValidString(got) || !strings.HasSuffix(got, "...") || textWidth(got) > 182 {
+		t.Errorf("Expected valid text cut to 182 pixels with an ellipsis, got %q", got)
+	}
+	if !strings.HasPrefix(got, "Due Wed 3/13 23:59   #買") {
+		t.Errorf("Expected the cut inside the tag, got %q", got)
+	}
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// RestorableItem is a row for a todo that has been put away, such as an
+// archived todo. It shows the todo text and a dimmed detail, for example
+// when it was put away, with buttons to restore or permanently delete it.
+type RestorableItem struct {
+	Todo       *models.Todo
+	Detail     string
+	X, Y       int
+	Width      int
+	Height     int
+	Hovered    bool
+	Selected   bool
+	RestoreBtn *Button
+	DeleteBtn  *Button
+}
+
+const restoreButtonWidth = 70
+
+func NewRestorableItem(todo *models.Todo, detail string, x, y, width, height int) *RestorableItem {
+	item := &RestorableItem{
+		Todo:   todo,
+		Detail: detail,
+		X:      x,
+		Y:      y,
+		Width:  width,
+		Height: height,
+	}
+	item.RestoreBtn = NewButton(0, 0, restoreButtonWidth, 24, "Restore", nil)
+	item.RestoreBtn.SetVariant(ButtonSecondary)
+	item.DeleteBtn = NewButton(0, 0, 24, 24, "×", nil)
+	item.DeleteBtn.SetVariant(ButtonDanger)
+	item.updateComponentPositions()
+	return item
+}
+
+func (ri *RestorableItem) Update(in InputSource) {
+	mouseX, mouseY := CursorPosition(in)
+	ri.Hovered = mouseX >= ri.X && mouseX <= ri.X+ri.Width &&
+		mouseY >= ri.Y && mouseY <= ri.Y+ri.Height
+	ri.RestoreBtn.Update(in)
+	ri.DeleteBtn.Update(in)
+}
+
+func (ri *RestorableItem) Draw(screen *ebiten.Image) {
+	theme := CurrentTheme()
+
+	bgColor := theme.Surface
+	if ri.Selected {
+		bgColor = theme.SelectedRow
+	} else if ri.Hovered {
+		bgColor = theme.SurfaceHover
+	}
+	DrawRect(screen, float64(ri.X), float64(ri.Y), float64(ri.Width), float64(ri.Height), bgColor)
+
+	// Draw the detail right-aligned before the buttons, and the text in the
+	// space left
+	baseline := ri.Y + (ri.Height+10)/2
+	detailWidth := textWidth(ri.Detail)
+	detailX := ri.RestoreBtn.X - 12 - detailWidth
+	DrawText(screen, ri.Detail, detailX, baseline, theme.TextMuted)
+	title := TruncateText(ri.Todo.Text, detailX-ri.X-24)
+	DrawText(screen, title, ri.X+12, baseline, theme.Text)
+
+	ri.RestoreBtn.Draw(screen)
+	ri.DeleteBtn.Draw(screen)
+
+	DrawRect(screen, float64(ri.X), float64(ri.Y+ri.Height-1), float64(ri.Width), 1, theme.Border)
+}
+
+func (ri *RestorableItem) updateComponentPositions() {
+	buttonY := ri.Y + (ri.Height-24)/2
+	ri.DeleteBtn.SetPosition(ri.X+ri.Width-24-8, buttonY)
+	ri.RestoreBtn.SetPosition(ri.X+ri.Width-24-restoreButtonWidth-16, buttonY)
+}
+
+func (ri *RestorableItem) SetPosition(x, y int) {
+	ri.X = x
+	ri.Y = y
+	ri.updateComponentPositions()
+}
+
+func (ri *RestorableItem) SetWidth(width int) {
+	ri.Width = width
+	ri.updateComponentPositions()
+}
@@ -0,0 +1,280 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/markdown"
+)
+
+// RichTextColors are the colors used to draw Markdown-styled text.
+type RichTextColors struct {
+	Text           color.RGBA
+	Link           color.RGBA
+	Code           color.RGBA
+	CodeBackground color.RGBA
+}
+
+func themeRichTextColors(theme *Theme) RichTextColors {
+	return RichTextColors{
+		Text:           theme.Text,
+		Link:           theme.Link,
+		Code:           theme.Code,
+		CodeBackground: theme.CodeBackground,
+	}
+}
+
+const (
+	richTextLineHeight = 16
+	richTextBaseline   = 12 // Offset of the text baseline from the top of a line
+	bulletIndent       = 14
+	italicSkew         = -0.2
+)
+
+// textFragment is a piece of styled text placed by the layout, relative to
+// the layout origin. Y is the top of its line.
+type textFragment struct {
+	X, Y  int
+	Width int
+	Text  string
+	Style markdown.Style
+	URL   string
+}
+
+// RichText draws a Markdown document with word wrapping and reports which
+// link, if any, lies under a point.
+type RichText struct {
+	X, Y, Width, Height int
+	source              string
+	blocks              []markdown.Block
+	fragments           []textFragment
+	bullets             []image.Point
+	layoutWidth         int
+}
+
+func NewRichText(x, y, width, height int) *RichText {
+	return &RichText{
+		X:      x,
+		Y:      y,
+		Width:  width,
+		Height: height,
+	}
+}
+
+// SetSource replaces the Markdown source of the text.
+func (rt *RichText) SetSource(source string) {
+	if source == rt.source && rt.blocks != nil {
+		return
+	}
+	rt.source = source
+	rt.blocks = markdown.Parse(source)
+	rt.layoutWidth = -1
+}
+
+func (rt *RichText) Draw(screen *ebiten.Image) {
+	rt.layout()
+	colors := themeRichTextColors(CurrentTheme())
+	for _, b := range rt.bullets {
+		if b.Y+richTextLineHeight <= rt.Height {
+			DrawRect(screen, float64(rt.X+b.X), float64(rt.Y+b.Y+richTextBaseline-5), 4, 4, colors.Text)
+		}
+	}
+	drawFragments(screen, rt.visibleFragments(), rt.X, rt.Y, colors)
+}
+
+// LinkAt returns the URL of the link at screen position (x, y).
+func (rt *RichText) LinkAt(x, y int) (string, bool) {
+	rt.layout()
+	return fragmentLinkAt(rt.visibleFragments(), x-rt.X, y-rt.Y)
+}
+
+func (rt *RichText) layout() {
+	if rt.layoutWidth == rt.Width {
+		return
+	}
+	rt.layoutWidth = rt.Width
+	rt.fragments, rt.bullets = layoutBlocks(rt.blocks, rt.Width)
+}
+
+// visibleFragments returns the fragments whose lines fit inside Height.
+func (rt *RichText) visibleFragments() []textFragment {
+	for i, f := range rt.fragments {
+		if f.Y+richTextLineHeight > rt.Height {
+			return rt.fragments[:i]
+		}
+	}
+	return rt.fragments
+}
+
+// layoutBlocks lays out a document, indenting bullet items. It returns the
+// fragments and the positions of the bullet markers.
+func layoutBlocks(blocks []markdown.Block, maxWidth int) ([]textFragment, []image.Point) {
+	var fragments []textFragment
+	var bullets []image.Point
+	top := 0
+	for _, block := range blocks {
+		indent := 0
+		if block.Kind == markdown.Bullet {
+			indent = bulletIndent
+			bullets = append(bullets, image.Point{X: 4, Y: top})
+		}
+		var lines int
+		fragments, lines = layoutRuns(fragments, block.Runs, indent, top, maxWidth)
+		top += lines * richTextLineHeight
+	}
+	return fragments, bullets
+}
+
+// layoutRuns word-wraps runs into lines no wider than maxWidth, starting at
+// the given indent and line top, and returns the number of lines used.
+func layoutRuns(fragments []textFragment, runs []markdown.Run, indent, top, maxWidth int) ([]textFragment, int) {
+	x, y := indent, top
+	lines := 1
+	for _, run := range runs {
+		for _, token := range splitWords(run.Text) {
+			width := textWidth(strings.TrimRightFunc(token, unicode.IsSpace))
+			if x > indent && x+width > maxWidth {
+				x, y = indent, y+richTextLineHeight
+				lines++
+			}
+			// Break words that are wider than a whole line.
+			for x == indent && width > maxWidth-indent && len([]rune(token)) > 1 {
+				head, tail := splitAtWidth(token, maxWidth-indent)
+				fragments = appendFragment(fragments, x, y, head, run)
+				token = tail
+				width = textWidth(strings.TrimRightFunc(token, unicode.IsSpace))
+				y += richTextLineHeight
+				lines++
+			}
+			fragments = appendFragment(fragments, x, y, token, run)
+			x += textWidth(token)
+		}
+	}
+	return fragments, lines
+}
+
+// appendFragment adds text at (x, y), merging it into the previous fragment
+// when that one has the same style and ends where this one starts.
+func appendFragment(fragments []textFragment, x, y int, s string, run markdown.Run) []textFragment {
+	if n := len(fragments); n > 0 {
+		last := &fragments[n-1]
+		if last.Y == y && last.X+last.Width == x && last.Style == run.Style && last.URL == run.URL {
+			last.Text += s
+			last.Width = textWidth(last.Text)
+			return fragments
+		}
+	}
+	return append(fragments, textFragment{X: x, Y: y, Width: textWidth(s), Text: s, Style: run.Style, URL: run.URL})
+}
+
+// truncateFragments cuts single-line fragments to maxWidth, ending with an
+// ellipsis when anything was cut.
+func truncateFragments(fragments []textFragment, maxWidth int) []textFragment {
+	if len(fragments) == 0 {
+		return fragments
+	}
+	if last := fragments[len(fragments)-1]; last.X+last.Width <= maxWidth {
+		return fragments
+	}
+
+	const ellipsis = "..."
+	limit := maxWidth - textWidth(ellipsis)
+	var out []textFragment
+	for _, f := range fragments {
+		if f.X+f.Width <= limit {
+			out = append(out, f)
+			continue
+		}
+		runes := []rune(f.Text)
+		for len(runes) > 0 && f.X+textWidth(string(runes)) > limit {
+			runes = runes[:len(runes)-1]
+		}
+		if len(runes) > 0 {
+			f.Text = string(runes)
+			f.Width = textWidth(f.Text)
+			out = append(out, f)
+		}
+		break
+	}
+
+	x := 0
+	if n := len(out); n > 0 {
+		x = out[n-1].X + out[n-1].Width
+	}
+	return append(out, textFragment{X: x, Width: textWidth(ellipsis), Text: ellipsis})
+}
+
+// drawFragments draws laid-out fragments with their origin at (x, y).
+func drawFragments(screen *ebiten.Image, fragments []textFragment, x, y int, colors RichTextColors) {
+	for _, f := range fragments {
+		fx := x + f.X
+		top := y + f.Y
+		baseline := top + richTextBaseline
+
+		clr := colors.Text
+		switch {
+		case f.Style.Has(markdown.Code):
+			DrawRect(screen, float64(fx-1), float64(top+1), float64(f.Width+2), richTextLineHeight-2, colors.CodeBackground)
+			clr = colors.Code
+		case f.Style.Has(markdown.Link):
+			clr = colors.Link
+		}
+
+		drawStyledString(screen, f.Text, fx, baseline, clr, f.Style.Has(markdown.Italic))
+		if f.Style.Has(markdown.Bold) {
+			// The bitmap font has no bold face, so embolden by overdrawing.
+			drawStyledString(screen, f.Text, fx+1, baseline, clr, f.Style.Has(markdown.Italic))
+		}
+		if f.Style.Has(markdown.Link) {
+			DrawRect(screen, float64(fx), float64(baseline+2), float64(f.Width), 1, clr)
+		}
+	}
+}
+
+func drawStyledString(screen *ebiten.Image, s string, x, baseline int, clr color.Color, italic bool) {
+	skew := 0.0
+	if italic {
+		skew = italicSkew
+	}
+	drawText(screen, s, x, baseline, clr, skew)
+}
+
+// fragmentLinkAt returns the URL of the link fragment containing the point
+// (x, y), relative to the layout origin.
+func fragmentLinkAt(fragments []textFragment, x, y int) (string, bool) {
+	for _, f := range fragments {
+		if f.URL != "" && x >= f.X && x < f.X+f.Width && y >= f.Y && y < f.Y+richTextLineHeight {
+			return f.URL, true
+		}
+	}
+	return "", false
+}
+
+// splitWords splits s into words, each keeping its trailing whitespace.
+func splitWords(s string) []string {
+	var words []string
+	start := 0
+	runes := []rune(s)
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || (unicode.IsSpace(runes[i-1]) && !unicode.IsSpace(runes[i])) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return words
+}
+
+// splitAtWidth splits s so that the head is at most width pixels wide and
+// holds at least one rune.
+func splitAtWidth(s string, width int) (string, string) {
+	runes := []rune(s)
+	n := 1
+	for n < len(runes) && textWidth(string(runes[:n+1])) <= width {
+		n++
+	}
+	return string(runes[:n]), string(runes[n:])
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lapis2411/todo/internal/markdown"
+	"github.com/lapis2411/todo/internal/models"
+)
+
+func TestLayoutRunsWrapsAcrossStyles(t *testing.T) {
+	runs := markdown.ParseInline("buy **oat milk** and bread")
+
+	// 7px per rune: 70px fits "buy oat " and "milk and " but not more.
+	fragments, lines := layoutRuns(nil, runs, 0, 0, 70)
+
+	if lines != 3 {
+		t.Fatalf("Expected 3 lines, got %d: %+v", lines, fragments)
+	}
+	want := []textFragment{
+		{X: 0, Y: 0, Text: "buy "},
+		{X: 28, Y: 0, Text: "oat ", Style: markdown.Bold},
+		{X: 0, Y: 16, Text: "milk", Style: markdown.Bold},
+		{X: 28, Y: 16, Text: " and "},
+		{X: 0, Y: 32, Text: "bread"},
+	}
+	if len(fragments) != len(want) {
+		t.Fatalf("Expected %d fragments, got %d: %+v", len(want), len(fragments), fragments)
+	}
+	for i, f := range fragments {
+		if f.X != want[i].X || f.Y != want[i].Y || f.Text != want[i].Text || f.Style != want[i].Style {
+			t.Errorf("fragment %d: expected %+v, got %+v", i, want[i], f)
+		}
+	}
+}
+
+func TestTruncateFragments(t *testing.T) {
+	fragments, _ := layoutRuns(nil, markdown.ParseInline("`code` and more text"), 0, 0, math.MaxInt32)
+
+	truncated := truncateFragments(fragments, 70)
+
+	last := truncated[len(truncated)-1]
+	if last.Text != "..." {
+		t.Fatalf("Expected trailing ellipsis, got %+v", truncated)
+	}
+	if last.X+last.Width > 70 {
+		t.Errorf("Expected truncated text to fit in 70px, ends at %d", last.X+last.Width)
+	}
+	if truncated[0].Style != markdown.Code {
+		t.Errorf("Expected first fragment to keep code style, got %+v", truncated[0])
+	}
+}
+
+func TestTodoItemLinkAt(t *testing.T) {
+	todo := models.NewTodo("Read [spec](https://example.com/spec) today", models.SystemClock, models.UUIDs)
+	item := NewTodoItem(&todo, 0, 0, 600, 50)
+
+	linkX := 40 + textWidth("Read ") + 3
+	url, ok := item.linkAt(linkX, item.titleTop()+8)
+	if !ok || url != "https://example.com/spec" {
+		t.Errorf("Expected link under cursor, got %q, %v", url, ok)
+	}
+
+	if _, ok := item.linkAt(42, item.titleTop()+8); ok {
+		t.Error("Expected no link over plain text")
+	}
+}
+
+func TestRichTextLinkAtInNotes(t *testing.T) {
+	rt := NewRichText(10, 100, 300, 64)
+	rt.SetSource("- see https://example.com/a\n- done")
+
+	url, ok := rt.LinkAt(10+bulletIndent+textWidth("see ")+2, 100+4)
+	if !ok || url != "https://example.com/a" {
+		t.Errorf("Expected bullet link, got %q, %v", url, ok)
+	}
+}
@@ -0,0 +1,32 @@
+package ui
+
+// Widgets lay out, draw and hit-test in UI pixels. The game draws the UI
+// at the scale factor, which combines the display's device scale with the
+// user's zoom, so text and widgets keep their size on high-density
+// displays and stay sharp at any zoom.
+var scale = 1.0
+
+// Scale returns the number of screen pixels per UI pixel.
+func Scale() float64 {
+	return scale
+}
+
+// SetScale sets the number of screen pixels per UI pixel. Values that are
+// not positive are ignored.
+func SetScale(s float64) {
+	if s > 0 {
+		scale = s
+	}
+}
+
+// CursorPosition returns the mouse position of in, in UI pixels. Widgets
+// use it instead of in.CursorPosition so that hit-testing agrees with what
+// is drawn at any scale.
+func CursorPosition(in InputSource) (int, int) {
+	return ToUI(in.CursorPosition())
+}
+
+// ToUI converts a position in screen pixels to UI pixels.
+func ToUI(x, y int) (int, int) {
+	return int(float64(x) / scale), int(float64(y) / scale)
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"math"
+	"testing"
+
+	textv2 "github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+func TestHitTestingUnderScale(t *testing.T) {
+	t.Cleanup(func() { SetScale(1) })
+	button := NewButton(100, 50, 80, 30, "OK", nil)
+	box := NewTextBox(200, 50, 100, 30, "")
+
+	tests := []struct {
+		scale            float64
+		screenX, screenY int
+		inButton, inBox  bool
+	}{
+		{1, 120, 60, true, false},
+		{2, 240, 120, true, false},  // the button is drawn at 200-360 x 100-160
+		{2, 120, 60, false, false},  // the unscaled position is now empty
+		{2, 500, 150, false, true},  // the text box is drawn at 400-600
+		{1.5, 255, 90, true, false}, // (170, 60) in UI pixels
+		{1.5, 310, 90, false, true}, // (206, 60) in UI pixels
+		{0, 255, 90, true, false},   // ignored, the scale stays 1.5
+	}
+
+	for _, tt := range tests {
+		SetScale(tt.scale)
+		x, y := ToUI(tt.screenX, tt.screenY)
+		if got := button.Contains(x, y); got != tt.inButton {
+			t.Errorf("Scale %v, (%d,%d): expected button hit %v, got %v", tt.scale, tt.screenX, tt.screenY, tt.inButton, got)
+		}
+		if got := box.Contains(x, y); got != tt.inBox {
+			t.Errorf("Scale %v, (%d,%d): expected text box hit %v, got %v", tt.scale, tt.screenX, tt.screenY, tt.inBox, got)
+		}
+	}
+}
+
+func TestScalableTextFitsCells(t *testing.T) {
+	if !ScalableText() {
+		t.Fatal("Expected the scalable font to load")
+	}
+
+	// Characters keep close to the cells of the bitmap font at any scale
+	for _, scale := range []float64{1, 1.5, 2, 2.75} {
+		face := &textv2.GoTextFace{Source: textSource, Size: textSize * scale}
+		cell := float64(textWidth("0")) * scale
+		if got := textv2.Advance("W", face); math.Abs(got-cell) > 0.5*scale {
+			t.Errorf("Scale %v: expected a character about %vpx wide, got %v", scale, cell, got)
+		}
+		if got, want := face.Metrics().HAscent, 11*scale; math.Abs(got-want) > scale {
+			t.Errorf("Scale %v: expected an ascent of about %v, got %v", scale, want, got)
+		}
+	}
+}
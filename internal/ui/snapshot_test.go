@@ -0,0 +1,113 @@
+//go:build snapshot
+
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/models"
+	"github.com/lapis2411/todo/internal/snapshot"
+	"github.com/lapis2411/todo/internal/snapshot/headless"
+)
+
+// The snapshot tests draw widgets in the light and dark themes and compare
+// them with the goldens in testdata/golden. See package headless for how
+// to run them.
+
+func TestMain(m *testing.M) {
+	headless.Main(m)
+}
+
+func matchWidget(t *testing.T, name string, width, height int, draw func(screen *ebiten.Image)) {
+	t.Helper()
+	SetScale(1)
+	defer SetTheme(LightTheme())
+	for _, theme := range []*Theme{LightTheme(), DarkTheme()} {
+		SetTheme(theme)
+		img := headless.Render(width, height, func(screen *ebiten.Image) {
+			screen.Fill(theme.Background)
+			draw(screen)
+		})
+		snapshot.Match(t, name+"-"+strings.ToLower(theme.Name), img, snapshot.DefaultOptions)
+	}
+}
+
+func TestButtonSnapshots(t *testing.T) {
+	variants := map[string]ButtonVariant{
+		"primary":   ButtonPrimary,
+		"secondary": ButtonSecondary,
+		"danger":    ButtonDanger,
+	}
+	for name, variant := range variants {
+		b := NewButton(10, 10, 100, 30, "Add", nil)
+		b.SetVariant(variant)
+		matchWidget(t, "button-"+name, 120, 50, b.Draw)
+
+		b.Hovered = true
+		matchWidget(t, "button-"+name+"-hovered", 120, 50, b.Draw)
+	}
+
+	disabled := NewButton(10, 10, 100, 30, "Add", nil)
+	disabled.Enabled = false
+	matchWidget(t, "button-disabled", 120, 50, disabled.Draw)
+}
+
+func TestTextBoxSnapshots(t *testing.T) {
+	clock := models.NewFakeClock(testNow)
+
+	empty := NewTextBox(10, 10, 260, 30, "Add a new todo...")
+	matchWidget(t, "textbox-placeholder", 280, 50, empty.Draw)
+
+	tb := NewTextBox(10, 10, 260, 30, "Add a new todo...")
+	tb.Clock = clock
+	tb.SetText("Buy milk and eggs")
+	tb.SetFocus(true)
+	matchWidget(t, "textbox-focused", 280, 50, tb.Draw)
+
+	tb.MoveCursor(4, false)
+	tb.MoveCursor(8, true)
+	matchWidget(t, "textbox-selection", 280, 50, tb.Draw)
+}
+
+func TestTodoItemSnapshots(t *testing.T) {
+	clock := models.NewFakeClock(testNow)
+	ids := &models.SequentialIDs{Prefix: "todo-"}
+	item := func(text string, change func(todo *models.Todo)) *TodoItem {
+		todo := models.NewTodo(text, clock, ids)
+		if change != nil {
+			change(&todo)
+		}
+		item := NewTodoItem(&todo, 0, 0, 500, 40)
+		item.SetClock(clock)
+		return item
+	}
+
+	matchWidget(t, "todoitem-active", 500, 40, item("Buy milk", nil).Draw)
+	matchWidget(t, "todoitem-completed", 500, 40, item("Buy milk", func(todo *models.Todo) {
+		todo.Toggle(clock.Now())
+	}).Draw)
+	matchWidget(t, "todoitem-priority-tags", 500, 40, item("Pay rent", func(todo *models.Todo) {
+		todo.SetPriority(models.PriorityHigh, clock.Now())
+		todo.AddTag("home", clock.Now())
+		todo.AddTag("money", clock.Now())
+	}).Draw)
+	matchWidget(t, "todoitem-markup", 500, 40, item("Read **the** [docs](https://example.com)", nil).Draw)
+
+	selected := item("Buy milk", nil)
+	selected.Selected = true
+	selected.Hovered = true
+	matchWidget(t, "todoitem-selected", 500, 40, selected.Draw)
+
+	editing := item("Buy milk", nil)
+	editing.StartEditing()
+	matchWidget(t, "todoitem-editing", 500, 40, editing.Draw)
+
+	notes := item("Buy milk", func(todo *models.Todo) {
+		todo.SetNotes("- 2 liters\n- **oat** milk", clock.Now())
+	})
+	notes.SetExpanded(true)
+	matchWidget(t, "todoitem-notes", 500, notes.TotalHeight(), notes.Draw)
+}
@@ -0,0 +1,355 @@
+package ui
+
+import (
+	"image"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// TextArea is a multiline, word-wrapping text editor with vertical scrolling.
+type TextArea struct {
+	textEditor
+	X, Y, Width, Height int
+	Focused             bool
+	scrollLine          int
+	goalX               int
+	goalCursor          int
+	dragging            bool
+	PlaceholderText     string
+}
+
+const (
+	textAreaPadding    = 8
+	textAreaLineHeight = 16
+	textAreaBaseline   = 12 // Offset of the text baseline from the top of a line
+)
+
+// textLine is one visual line of wrapped text as a rune range. The range
+// excludes the line break that ends a paragraph.
+type textLine struct {
+	start, end int
+}
+
+func NewTextArea(x, y, width, height int, placeholder string) *TextArea {
+	return &TextArea{
+		textEditor: textEditor{
+			ShowCursor:       true,
+			lastCursorToggle: models.SystemClock.Now(),
+			MaxLength:        5000,
+			Clipboard:        DefaultClipboard,
+			Keyboard:         DefaultKeyboard,
+			Clock:            models.SystemClock,
+			multiline:        true,
+		},
+		X:               x,
+		Y:               y,
+		Width:           width,
+		Height:          height,
+		goalX:           -1,
+		PlaceholderText: placeholder,
+	}
+}
+
+func (ta *TextArea) Update(in InputSource) {
+	// Focus is given by the FocusManager; a click only places the cursor,
+	// and dragging extends the selection
+	mouseX, mouseY := CursorPosition(in)
+	if in.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		ta.dragging = ta.Focused && ta.Contains(mouseX, mouseY)
+		if ta.dragging {
+			ta.MoveCursor(ta.indexAt(mouseX, mouseY), ta.keyboard().IsShiftPressed())
+		}
+	} else if ta.dragging {
+		if in.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+			ta.MoveCursor(ta.indexAt(mouseX, mouseY), true)
+			ta.ensureCursorVisible()
+		} else {
+			ta.dragging = false
+		}
+	}
+
+	// Handle mouse wheel scrolling
+	if ta.Contains(mouseX, mouseY) {
+		if _, dy := in.Wheel(); dy != 0 {
+			ta.scrollLine -= int(dy)
+			ta.clampScroll()
+		}
+	}
+
+	if !ta.Focused {
+		return
+	}
+
+	ta.updateCursorBlink()
+
+	prevText, prevCursor := ta.Text, ta.CursorPos
+	ta.handleEditingKeys()
+
+	kb := ta.keyboard()
+	shift := kb.IsShiftPressed()
+	if kb.IsTriggered(ebiten.KeyEnter) && !kb.IsShortcutModifierPressed() {
+		ta.InsertText("\n")
+	}
+	if kb.IsTriggered(ebiten.KeyArrowUp) {
+		ta.moveVertical(-1, shift)
+	}
+	if kb.IsTriggered(ebiten.KeyArrowDown) {
+		ta.moveVertical(1, shift)
+	}
+	if kb.IsTriggered(ebiten.KeyHome) {
+		lines := ta.lines()
+		ta.MoveCursor(lines[lineIndexOf(lines, ta.CursorPos)].start, shift)
+	}
+	if kb.IsTriggered(ebiten.KeyEnd) {
+		lines := ta.lines()
+		ta.MoveCursor(lines[lineIndexOf(lines, ta.CursorPos)].end, shift)
+	}
+
+	if ta.Text != prevText || ta.CursorPos != prevCursor {
+		ta.ensureCursorVisible()
+	}
+}
+
+func (ta *TextArea) Draw(screen *ebiten.Image) {
+	theme := CurrentTheme()
+
+	// Draw background
+	DrawRect(screen, float64(ta.X), float64(ta.Y), float64(ta.Width), float64(ta.Height), theme.Surface)
+
+	// Draw border with 2px width for focused state
+	borderColor := theme.InputBorder
+	borderWidth := 1
+	if ta.Focused {
+		borderColor = theme.Accent
+		borderWidth = 2
+	}
+	for i := 0; i < borderWidth; i++ {
+		DrawRect(screen, float64(ta.X-i), float64(ta.Y-i), float64(ta.Width+2*i), 1, borderColor)
+		DrawRect(screen, float64(ta.X-i), float64(ta.Y-i), 1, float64(ta.Height+2*i), borderColor)
+		DrawRect(screen, float64(ta.X+ta.Width-1+i), float64(ta.Y-i), 1, float64(ta.Height+2*i), borderColor)
+		DrawRect(screen, float64(ta.X-i), float64(ta.Y+ta.Height-1+i), float64(ta.Width+2*i), 1, borderColor)
+	}
+
+	textX := ta.X + textAreaPadding
+
+	// Draw placeholder when empty
+	if ta.Text == "" && !ta.Focused {
+		DrawText(screen, ta.PlaceholderText, textX, ta.Y+textAreaPadding+textAreaBaseline, theme.TextMuted)
+		return
+	}
+
+	ta.clampCursor()
+	ta.clampScroll()
+	runes := []rune(ta.Text)
+	lines := ta.lines()
+	selStart, selEnd := ta.Selection()
+	cursorLine := lineIndexOf(lines, ta.CursorPos)
+
+	last := min(len(lines), ta.scrollLine+ta.visibleLineCount())
+	for i := ta.scrollLine; i < last; i++ {
+		line := lines[i]
+		top := ta.Y + textAreaPadding + (i-ta.scrollLine)*textAreaLineHeight
+
+		// Draw selection highlight behind the text
+		if ta.Focused && ta.HasSelection() && selStart <= line.end && selEnd >= line.start {
+			from := max(selStart, line.start)
+			to := min(selEnd, line.end)
+			startX := textX + textWidth(string(runes[line.start:from]))
+			endX := textX + textWidth(string(runes[line.start:to]))
+			if selEnd > line.end {
+				endX += 4 // Show that the line break is selected
+			}
+			if endX > startX {
+				DrawRect(screen, float64(startX), float64(top), float64(endX-startX), textAreaLineHeight, theme.Selection)
+			}
+		}
+
+		DrawText(screen, string(runes[line.start:line.end]), textX, top+textAreaBaseline, theme.Text)
+
+		// Draw cursor
+		if ta.Focused && ta.ShowCursor && i == cursorLine {
+			cursorX := textX + textWidth(string(runes[line.start:ta.CursorPos]))
+			DrawRect(screen, float64(cursorX), float64(top+1), 1, textAreaLineHeight-2, theme.Text)
+		}
+	}
+
+	// Draw scroll indicator when the text overflows
+	if visible := ta.visibleLineCount(); len(lines) > visible {
+		trackHeight := ta.Height - 4
+		thumbHeight := max(trackHeight*visible/len(lines), 8)
+		thumbY := ta.Y + 2 + (trackHeight-thumbHeight)*ta.scrollLine/(len(lines)-visible)
+		DrawRect(screen, float64(ta.X+ta.Width-5), float64(thumbY), 3, float64(thumbHeight), theme.InputBorder)
+	}
+}
+
+func (ta *TextArea) Contains(x, y int) bool {
+	return x >= ta.X && x <= ta.X+ta.Width && y >= ta.Y && y <= ta.Y+ta.Height
+}
+
+func (ta *TextArea) Bounds() image.Rectangle {
+	return image.Rect(ta.X, ta.Y, ta.X+ta.Width, ta.Y+ta.Height)
+}
+
+func (ta *TextArea) SetFocus(focused bool) {
+	ta.Focused = focused
+	if focused {
+		ta.ShowCursor = true
+		ta.lastCursorToggle = ta.clock().Now()
+	}
+}
+
+func (ta *TextArea) GetText() string {
+	return ta.Text
+}
+
+func (ta *TextArea) SetText(text string) {
+	ta.Text = ta.sanitize(text)
+	ta.MoveCursor(ta.length(), false)
+	ta.ensureCursorVisible()
+}
+
+func (ta *TextArea) Clear() {
+	ta.Text = ""
+	ta.MoveCursor(0, false)
+	ta.scrollLine = 0
+}
+
+// IsSaveRequested reports whether Ctrl+Enter (Cmd+Enter on macOS) was pressed.
+func (ta *TextArea) IsSaveRequested() bool {
+	kb := ta.keyboard()
+	return ta.Focused && kb.IsShortcutModifierPressed() && kb.IsJustPressed(ebiten.KeyEnter)
+}
+
+func (ta *TextArea) IsEscapePressed() bool {
+	return ta.Focused && ta.keyboard().IsJustPressed(ebiten.KeyEscape)
+}
+
+// moveVertical moves the cursor by delta visual lines, keeping the
+// horizontal position of the first vertical move.
+func (ta *TextArea) moveVertical(delta int, extend bool) {
+	lines := ta.lines()
+	current := lineIndexOf(lines, ta.CursorPos)
+	if ta.goalX < 0 || ta.goalCursor != ta.CursorPos {
+		ta.goalX = textWidth(string([]rune(ta.Text)[lines[current].start:ta.CursorPos]))
+	}
+
+	target := current + delta
+	switch {
+	case target < 0:
+		ta.MoveCursor(0, extend)
+	case target >= len(lines):
+		ta.MoveCursor(ta.length(), extend)
+	default:
+		ta.MoveCursor(ta.columnIndexAt(lines, target, ta.goalX), extend)
+	}
+	ta.goalCursor = ta.CursorPos
+}
+
+// indexAt returns the rune offset closest to the screen position.
+func (ta *TextArea) indexAt(x, y int) int {
+	lines := ta.lines()
+	line := ta.scrollLine + (y-ta.Y-textAreaPadding)/textAreaLineHeight
+	if y < ta.Y+textAreaPadding {
+		line = ta.scrollLine - 1
+	}
+	line = clamp(line, 0, len(lines)-1)
+	return ta.columnIndexAt(lines, line, x-ta.X-textAreaPadding)
+}
+
+// columnIndexAt returns the rune offset on visual line i closest to offset
+// pixels from the left edge of the text.
+func (ta *TextArea) columnIndexAt(lines []textLine, i, offset int) int {
+	runes := []rune(ta.Text)
+	line := lines[i]
+
+	// A wrapped line shares its end offset with the start of the next line,
+	// so stop before the break to keep the cursor on this line.
+	limit := line.end
+	if i+1 < len(lines) && lines[i+1].start == line.end && line.end > line.start {
+		limit--
+	}
+
+	for j := line.start; j < limit; j++ {
+		left := textWidth(string(runes[line.start:j]))
+		right := textWidth(string(runes[line.start : j+1]))
+		if offset < (left+right)/2 {
+			return j
+		}
+	}
+	return limit
+}
+
+func (ta *TextArea) lines() []textLine {
+	return wrapText([]rune(ta.Text), ta.Width-textAreaPadding*2-6)
+}
+
+func (ta *TextArea) visibleLineCount() int {
+	return max((ta.Height-textAreaPadding*2)/textAreaLineHeight, 1)
+}
+
+func (ta *TextArea) ensureCursorVisible() {
+	line := lineIndexOf(ta.lines(), ta.CursorPos)
+	if line < ta.scrollLine {
+		ta.scrollLine = line
+	}
+	if visible := ta.visibleLineCount(); line >= ta.scrollLine+visible {
+		ta.scrollLine = line - visible + 1
+	}
+	ta.clampScroll()
+}
+
+func (ta *TextArea) clampScroll() {
+	ta.scrollLine = clamp(ta.scrollLine, 0, max(len(ta.lines())-ta.visibleLineCount(), 0))
+}
+
+// wrapText splits runes into visual lines no wider than maxWidth, breaking
+// after whitespace where possible and at explicit line breaks.
+func wrapText(runes []rune, maxWidth int) []textLine {
+	var lines []textLine
+	for start := 0; start <= len(runes); {
+		end := start
+		for end < len(runes) && runes[end] != '\n' {
+			end++
+		}
+		lines = appendWrappedLines(lines, runes, start, end, maxWidth)
+		start = end + 1
+	}
+	return lines
+}
+
+func appendWrappedLines(lines []textLine, runes []rune, start, end, maxWidth int) []textLine {
+	lineStart := start
+	width := 0
+	lastBreak := -1
+	for i := start; i < end; i++ {
+		advance := textWidth(string(runes[i]))
+		if width+advance > maxWidth && i > lineStart {
+			breakAt := i
+			if lastBreak > lineStart {
+				breakAt = lastBreak
+			}
+			lines = append(lines, textLine{start: lineStart, end: breakAt})
+			lineStart = breakAt
+			width = textWidth(string(runes[lineStart:i]))
+			lastBreak = -1
+		}
+		width += advance
+		if unicode.IsSpace(runes[i]) {
+			lastBreak = i + 1
+		}
+	}
+	return append(lines, textLine{start: lineStart, end: end})
+}
+
+// lineIndexOf returns the visual line containing the rune offset pos.
+func lineIndexOf(lines []textLine, pos int) int {
+	for i := len(lines) - 1; i > 0; i-- {
+		if lines[i].start <= pos {
+			return i
+		}
+	}
+	return 0
+}
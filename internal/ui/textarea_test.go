@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+func TestWrapText(t *testing.T) {
+	// basicfont.Face7x13 is monospaced with 7px advances, so 70px fits 10 runes.
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"empty", "", []string{""}},
+		{"short", "milk", []string{"milk"}},
+		{"newlines", "a\n\nb\n", []string{"a", "", "b", ""}},
+		{"word wrap", "buy oat milk today", []string{"buy oat ", "milk today"}},
+		{"hard wrap", "abcdefghijklmno", []string{"abcdefghij", "klmno"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runes := []rune(tt.text)
+			lines := wrapText(runes, 70)
+			if len(lines) != len(tt.want) {
+				t.Fatalf("Expected %d lines, got %d", len(tt.want), len(lines))
+			}
+			for i, line := range lines {
+				if got := string(runes[line.start:line.end]); got != tt.want[i] {
+					t.Errorf("line %d: expected %q, got %q", i, tt.want[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestTextAreaEnterAndVerticalMovement(t *testing.T) {
+	kb := NewKeyboard(DefaultKeyRepeatDelay, DefaultKeyRepeatInterval)
+	ta := NewTextArea(0, 0, 300, 100, "")
+	ta.Keyboard = kb
+	ta.SetFocus(true)
+	ta.SetText("first")
+
+	clock := models.NewFakeClock(testNow)
+	press := func(keys ...ebiten.Key) {
+		kb.Update(clock.Now(), keys, nil)
+		ta.Update(idle)
+		clock.Advance(time.Second)
+		kb.Update(clock.Now(), nil, nil)
+	}
+
+	press(ebiten.KeyEnter)
+	kb.Update(clock.Now(), nil, []rune("second"))
+	ta.Update(idle)
+
+	if ta.Text != "first\nsecond" {
+		t.Fatalf("Expected 'first\\nsecond', got %q", ta.Text)
+	}
+
+	press(ebiten.KeyArrowUp)
+	if ta.CursorPos != 5 {
+		t.Errorf("Expected cursor at end of first line (5), got %d", ta.CursorPos)
+	}
+
+	press(ebiten.KeyHome)
+	press(ebiten.KeyArrowDown)
+	if ta.CursorPos != 6 {
+		t.Errorf("Expected cursor at start of second line (6), got %d", ta.CursorPos)
+	}
+}
+
+func TestTextAreaSaveShortcutDoesNotInsertNewline(t *testing.T) {
+	kb := NewKeyboard(DefaultKeyRepeatDelay, DefaultKeyRepeatInterval)
+	ta := NewTextArea(0, 0, 300, 100, "")
+	ta.Keyboard = kb
+	ta.SetFocus(true)
+	ta.SetText("notes")
+
+	kb.Update(testNow, []ebiten.Key{ebiten.KeyControlLeft, ebiten.KeyEnter}, nil)
+	ta.Update(idle)
+
+	if !ta.IsSaveRequested() {
+		t.Error("Expected Ctrl+Enter to request save")
+	}
+	if ta.Text != "notes" {
+		t.Errorf("Expected text unchanged, got %q", ta.Text)
+	}
+}
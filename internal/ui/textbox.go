@@ -1,216 +1,141 @@
 package ui
 
 import (
-	"image/color"
-	"time"
+	"image"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/image/font/basicfont"
+
+	"github.com/lapis2411/todo/internal/models"
 )
 
 type TextBox struct {
+	textEditor
 	X, Y, Width, Height int
-	Text               string
-	Focused            bool
-	CursorPos          int
-	ShowCursor         bool
-	lastCursorToggle   time.Time
-	BackgroundColor    color.RGBA
-	BorderColor        color.RGBA
-	FocusedBorderColor color.RGBA
-	TextColor          color.RGBA
-	PlaceholderText    string
-	PlaceholderColor   color.RGBA
-	MaxLength          int
+	Focused             bool
+	scrollPos           int
+	dragging            bool
+	PlaceholderText     string
 }
 
+const textBoxPadding = 8
+
 func NewTextBox(x, y, width, height int, placeholder string) *TextBox {
 	return &TextBox{
-		X:                  x,
-		Y:                  y,
-		Width:              width,
-		Height:             height,
-		Text:               "",
-		PlaceholderText:    placeholder,
-		CursorPos:          0,
-		ShowCursor:         true,
-		lastCursorToggle:   time.Now(),
-		BackgroundColor:    color.RGBA{255, 255, 255, 255},
-		BorderColor:        color.RGBA{108, 117, 125, 255},
-		FocusedBorderColor: color.RGBA{0, 123, 255, 255},
-		TextColor:          color.RGBA{33, 37, 41, 255},
-		PlaceholderColor:   color.RGBA{108, 117, 125, 255},
-		MaxLength:          100,
-	}
-}
-
-func (tb *TextBox) Update() {
-	// Handle mouse click for focus
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-		x, y := ebiten.CursorPosition()
-		tb.Focused = tb.Contains(x, y)
-	}
-
-	if !tb.Focused {
-		return
-	}
-
-	// Handle cursor blinking
-	if time.Since(tb.lastCursorToggle) > 500*time.Millisecond {
-		tb.ShowCursor = !tb.ShowCursor
-		tb.lastCursorToggle = time.Now()
+		textEditor: textEditor{
+			ShowCursor:       true,
+			lastCursorToggle: models.SystemClock.Now(),
+			MaxLength:        100,
+			Clipboard:        DefaultClipboard,
+			Keyboard:         DefaultKeyboard,
+			Clock:            models.SystemClock,
+		},
+		X:               x,
+		Y:               y,
+		Width:           width,
+		Height:          height,
+		PlaceholderText: placeholder,
 	}
+}
 
-	// Handle text input
-	runes := ebiten.AppendInputChars(nil)
-	for _, r := range runes {
-		if r == '\n' || r == '\r' {
-			// Enter key handling is done by caller
-			continue
+func (tb *TextBox) Update(in InputSource) {
+	// Focus is given by the FocusManager; a click only places the cursor,
+	// and dragging extends the selection
+	mouseX, mouseY := CursorPosition(in)
+	if in.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		tb.dragging = tb.Focused && tb.Contains(mouseX, mouseY)
+		if tb.dragging {
+			tb.MoveCursor(tb.indexAt(mouseX), tb.keyboard().IsShiftPressed())
 		}
-		if r == '\b' {
-			// Backspace
-			if tb.CursorPos > 0 {
-				tb.Text = tb.Text[:tb.CursorPos-1] + tb.Text[tb.CursorPos:]
-				tb.CursorPos--
-			}
-		} else if len(tb.Text) < tb.MaxLength && r >= 32 {
-			// Regular character input
-			tb.Text = tb.Text[:tb.CursorPos] + string(r) + tb.Text[tb.CursorPos:]
-			tb.CursorPos++
+	} else if tb.dragging {
+		if in.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+			tb.MoveCursor(tb.indexAt(mouseX), true)
+		} else {
+			tb.dragging = false
 		}
 	}
 
-	// Handle arrow keys
-	if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) && tb.CursorPos > 0 {
-		tb.CursorPos--
-	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) && tb.CursorPos < len(tb.Text) {
-		tb.CursorPos++
-	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyHome) {
-		tb.CursorPos = 0
-	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyEnd) {
-		tb.CursorPos = len(tb.Text)
+	if !tb.Focused {
+		return
 	}
 
-	// Handle Delete key
-	if inpututil.IsKeyJustPressed(ebiten.KeyDelete) && tb.CursorPos < len(tb.Text) {
-		tb.Text = tb.Text[:tb.CursorPos] + tb.Text[tb.CursorPos+1:]
-	}
+	tb.updateCursorBlink()
+	tb.handleEditingKeys()
 
-	// Handle Backspace key (additional handling for better responsiveness)
-	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && tb.CursorPos > 0 {
-		tb.Text = tb.Text[:tb.CursorPos-1] + tb.Text[tb.CursorPos:]
-		tb.CursorPos--
+	kb := tb.keyboard()
+	if kb.IsTriggered(ebiten.KeyHome) {
+		tb.MoveCursor(0, kb.IsShiftPressed())
+	}
+	if kb.IsTriggered(ebiten.KeyEnd) {
+		tb.MoveCursor(tb.length(), kb.IsShiftPressed())
 	}
 }
 
 func (tb *TextBox) Draw(screen *ebiten.Image) {
+	theme := CurrentTheme()
+
 	// Draw background
-	ebitenutil.DrawRect(screen, float64(tb.X), float64(tb.Y), float64(tb.Width), float64(tb.Height), tb.BackgroundColor)
+	DrawRect(screen, float64(tb.X), float64(tb.Y), float64(tb.Width), float64(tb.Height), theme.Surface)
 
 	// Draw border
-	borderColor := tb.BorderColor
+	borderColor := theme.InputBorder
 	if tb.Focused {
-		borderColor = tb.FocusedBorderColor
+		borderColor = theme.Accent
 	}
-	
+
 	// Draw border with 2px width for focused state
 	borderWidth := 1
 	if tb.Focused {
 		borderWidth = 2
 	}
-	
-	for i := 0; i < borderWidth; i++ {
-		ebitenutil.DrawRect(screen, float64(tb.X-i), float64(tb.Y-i), float64(tb.Width+2*i), 1, borderColor)
-		ebitenutil.DrawRect(screen, float64(tb.X-i), float64(tb.Y-i), 1, float64(tb.Height+2*i), borderColor)
-		ebitenutil.DrawRect(screen, float64(tb.X+tb.Width-1+i), float64(tb.Y-i), 1, float64(tb.Height+2*i), borderColor)
-		ebitenutil.DrawRect(screen, float64(tb.X-i), float64(tb.Y+tb.Height-1+i), float64(tb.Width+2*i), 1, borderColor)
-	}
 
-	// Draw text or placeholder
-	displayText := tb.Text
-	textColor := tb.TextColor
-	
-	if displayText == "" && !tb.Focused {
-		displayText = tb.PlaceholderText
-		textColor = tb.PlaceholderColor
+	for i := 0; i < borderWidth; i++ {
+		DrawRect(screen, float64(tb.X-i), float64(tb.Y-i), float64(tb.Width+2*i), 1, borderColor)
+		DrawRect(screen, float64(tb.X-i), float64(tb.Y-i), 1, float64(tb.Height+2*i), borderColor)
+		DrawRect(screen, float64(tb.X+tb.Width-1+i), float64(tb.Y-i), 1, float64(tb.Height+2*i), borderColor)
+		DrawRect(screen, float64(tb.X-i), float64(tb.Y+tb.Height-1+i), float64(tb.Width+2*i), 1, borderColor)
 	}
 
 	// Calculate text position (with padding)
-	padding := 8
-	textX := tb.X + padding
+	textX := tb.X + textBoxPadding
 	textY := tb.Y + (tb.Height+text.BoundString(basicfont.Face7x13, "A").Max.Y)/2
 
+	// Draw placeholder when empty
+	if tb.Text == "" && !tb.Focused {
+		DrawText(screen, tb.PlaceholderText, textX, textY, theme.TextMuted)
+		return
+	}
+
 	// Draw visible portion of text (simple horizontal scrolling)
-	visibleText := displayText
-	maxVisibleWidth := tb.Width - padding*2 - 10 // Reserve space for cursor
-	
-	if text.BoundString(basicfont.Face7x13, visibleText).Max.X > maxVisibleWidth {
-		// Adjust visible text to keep cursor visible
-		startPos := 0
-		for startPos < len(displayText) {
-			testText := displayText[startPos:]
-			testWidth := text.BoundString(basicfont.Face7x13, testText).Max.X
-			testCursorOffset := 0
-			
-			if tb.CursorPos >= startPos {
-				relativeCursor := tb.CursorPos - startPos
-				if relativeCursor <= len(testText) {
-					testCursorOffset = text.BoundString(basicfont.Face7x13, testText[:relativeCursor]).Max.X
-				}
-			}
-			
-			if testWidth <= maxVisibleWidth && testCursorOffset <= maxVisibleWidth {
-				break
-			}
-			startPos++
-		}
-		
-		visibleText = displayText[startPos:]
-		if text.BoundString(basicfont.Face7x13, visibleText).Max.X > maxVisibleWidth {
-			// Truncate from the end
-			for len(visibleText) > 0 {
-				if text.BoundString(basicfont.Face7x13, visibleText).Max.X <= maxVisibleWidth {
-					break
-				}
-				visibleText = visibleText[:len(visibleText)-1]
-			}
+	tb.updateScroll()
+	runes := []rune(tb.Text)
+	visible := runes[tb.scrollPos:]
+	maxVisibleWidth := tb.maxVisibleWidth()
+	for len(visible) > 0 && textWidth(string(visible)) > maxVisibleWidth {
+		visible = visible[:len(visible)-1]
+	}
+
+	// Draw selection highlight behind the text
+	if tb.Focused && tb.HasSelection() {
+		start, end := tb.Selection()
+		start = clamp(start, tb.scrollPos, tb.scrollPos+len(visible))
+		end = clamp(end, tb.scrollPos, tb.scrollPos+len(visible))
+		if end > start {
+			startX := textX + textWidth(string(runes[tb.scrollPos:start]))
+			endX := textX + textWidth(string(runes[tb.scrollPos:end]))
+			DrawRect(screen, float64(startX), float64(tb.Y+4), float64(endX-startX), float64(tb.Height-8), theme.Selection)
 		}
 	}
 
-	text.Draw(screen, visibleText, basicfont.Face7x13, textX, textY, textColor)
+	DrawText(screen, string(visible), textX, textY, theme.Text)
 
 	// Draw cursor
-	if tb.Focused && tb.ShowCursor && tb.CursorPos >= 0 {
-		cursorX := textX
-		if tb.CursorPos > 0 && tb.CursorPos <= len(tb.Text) {
-			textBeforeCursor := tb.Text[:tb.CursorPos]
-			// Adjust for visible portion
-			if len(visibleText) < len(tb.Text) {
-				startPos := len(tb.Text) - len(visibleText)
-				if tb.CursorPos >= startPos {
-					relativeCursor := tb.CursorPos - startPos
-					if relativeCursor <= len(visibleText) {
-						textBeforeCursor = visibleText[:relativeCursor]
-					} else {
-						textBeforeCursor = visibleText
-					}
-				} else {
-					textBeforeCursor = ""
-				}
-			}
-			cursorX += text.BoundString(basicfont.Face7x13, textBeforeCursor).Max.X
-		}
-		
+	if tb.Focused && tb.ShowCursor {
+		cursorX := textX + textWidth(string(runes[tb.scrollPos:tb.CursorPos]))
 		cursorY := tb.Y + 4
 		cursorHeight := tb.Height - 8
-		ebitenutil.DrawRect(screen, float64(cursorX), float64(cursorY), 1, float64(cursorHeight), tb.TextColor)
+		DrawRect(screen, float64(cursorX), float64(cursorY), 1, float64(cursorHeight), theme.Text)
 	}
 }
 
@@ -218,11 +143,22 @@ func (tb *TextBox) Contains(x, y int) bool {
 	return x >= tb.X && x <= tb.X+tb.Width && y >= tb.Y && y <= tb.Y+tb.Height
 }
 
+func (tb *TextBox) Bounds() image.Rectangle {
+	return image.Rect(tb.X, tb.Y, tb.X+tb.Width, tb.Y+tb.Height)
+}
+
+// SetBounds moves and resizes the text box to r.
+func (tb *TextBox) SetBounds(r image.Rectangle) {
+	tb.X, tb.Y = r.Min.X, r.Min.Y
+	tb.Width, tb.Height = r.Dx(), r.Dy()
+	tb.updateScroll()
+}
+
 func (tb *TextBox) SetFocus(focused bool) {
 	tb.Focused = focused
 	if focused {
 		tb.ShowCursor = true
-		tb.lastCursorToggle = time.Now()
+		tb.lastCursorToggle = tb.clock().Now()
 	}
 }
 
@@ -232,18 +168,53 @@ func (tb *TextBox) GetText() string {
 
 func (tb *TextBox) SetText(text string) {
 	tb.Text = text
-	tb.CursorPos = len(text)
+	tb.CursorPos = tb.length()
+	tb.SelectionAnchor = tb.CursorPos
 }
 
 func (tb *TextBox) Clear() {
 	tb.Text = ""
 	tb.CursorPos = 0
+	tb.SelectionAnchor = 0
+	tb.scrollPos = 0
 }
 
 func (tb *TextBox) IsEnterPressed() bool {
-	return tb.Focused && inpututil.IsKeyJustPressed(ebiten.KeyEnter)
+	return tb.Focused && tb.keyboard().IsJustPressed(ebiten.KeyEnter)
 }
 
 func (tb *TextBox) IsEscapePressed() bool {
-	return tb.Focused && inpututil.IsKeyJustPressed(ebiten.KeyEscape)
-}
\ No newline at end of file
+	return tb.Focused && tb.keyboard().IsJustPressed(ebiten.KeyEscape)
+}
+
+func (tb *TextBox) maxVisibleWidth() int {
+	return tb.Width - textBoxPadding*2 - 10 // Reserve space for cursor
+}
+
+// updateScroll adjusts the first visible rune so that the cursor stays in view.
+func (tb *TextBox) updateScroll() {
+	tb.clampCursor()
+	runes := []rune(tb.Text)
+	tb.scrollPos = clamp(tb.scrollPos, 0, len(runes))
+	if tb.CursorPos < tb.scrollPos {
+		tb.scrollPos = tb.CursorPos
+	}
+	for tb.scrollPos < tb.CursorPos && textWidth(string(runes[tb.scrollPos:tb.CursorPos])) > tb.maxVisibleWidth() {
+		tb.scrollPos++
+	}
+}
+
+// indexAt returns the rune offset closest to the screen x coordinate.
+func (tb *TextBox) indexAt(x int) int {
+	tb.updateScroll()
+	runes := []rune(tb.Text)
+	offset := x - (tb.X + textBoxPadding)
+	for i := tb.scrollPos; i < len(runes); i++ {
+		left := textWidth(string(runes[tb.scrollPos:i]))
+		right := textWidth(string(runes[tb.scrollPos : i+1]))
+		if offset < (left+right)/2 {
+			return i
+		}
+	}
+	return len(runes)
+}
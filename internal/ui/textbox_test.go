@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+func newTestTextBox(text string) *TextBox {
+	tb := NewTextBox(0, 0, 200, 30, "")
+	tb.Clipboard = NewMemoryClipboard()
+	tb.SetText(text)
+	return tb
+}
+
+func TestTextBoxSelectAllAndCopy(t *testing.T) {
+	tb := newTestTextBox("hello world")
+
+	tb.SelectAll()
+	if !tb.HasSelection() {
+		t.Fatal("Expected a selection after SelectAll")
+	}
+	tb.Copy()
+
+	got, _ := tb.Clipboard.ReadText()
+	if got != "hello world" {
+		t.Errorf("Expected clipboard 'hello world', got %q", got)
+	}
+	if tb.Text != "hello world" {
+		t.Errorf("Copy should not modify text, got %q", tb.Text)
+	}
+}
+
+func TestTextBoxCutAndPaste(t *testing.T) {
+	tb := newTestTextBox("hello world")
+
+	tb.MoveCursor(0, false)
+	tb.MoveCursor(6, true)
+	tb.Cut()
+
+	if tb.Text != "world" {
+		t.Errorf("Expected 'world' after cut, got %q", tb.Text)
+	}
+
+	tb.MoveCursor(tb.length(), false)
+	tb.InsertText(" ")
+	tb.Paste()
+
+	if tb.Text != "world hello " {
+		t.Errorf("Expected 'world hello ' after paste, got %q", tb.Text)
+	}
+	if tb.CursorPos != tb.length() {
+		t.Errorf("Expected cursor at end, got %d", tb.CursorPos)
+	}
+}
+
+func TestTextBoxPasteSanitizesAndTruncates(t *testing.T) {
+	tb := newTestTextBox("")
+	tb.MaxLength = 8
+	_ = tb.Clipboard.WriteText("line1\nline2\x07")
+
+	tb.Paste()
+
+	if tb.Text != "line1 li" {
+		t.Errorf("Expected 'line1 li', got %q", tb.Text)
+	}
+}
+
+func TestTextBoxTypingReplacesSelection(t *testing.T) {
+	tb := newTestTextBox("buy milk")
+
+	tb.MoveCursor(4, false)
+	tb.MoveCursor(8, true)
+	tb.InsertText("bread")
+
+	if tb.Text != "buy bread" {
+		t.Errorf("Expected 'buy bread', got %q", tb.Text)
+	}
+	if tb.HasSelection() {
+		t.Error("Selection should be collapsed after typing")
+	}
+}
+
+func TestTextBoxWordNavigation(t *testing.T) {
+	tb := newTestTextBox("pay the  rent")
+
+	if got := tb.wordStartBefore(tb.length()); got != 9 {
+		t.Errorf("Expected word start 9, got %d", got)
+	}
+	if got := tb.wordStartBefore(9); got != 4 {
+		t.Errorf("Expected word start 4 when skipping spaces, got %d", got)
+	}
+	if got := tb.wordEndAfter(0); got != 3 {
+		t.Errorf("Expected word end 3, got %d", got)
+	}
+	if got := tb.wordEndAfter(3); got != 7 {
+		t.Errorf("Expected word end 7, got %d", got)
+	}
+}
+
+func TestTextBoxDeleteWordBackward(t *testing.T) {
+	tb := newTestTextBox("pay the rent")
+
+	tb.DeleteWordBackward()
+	if tb.Text != "pay the " {
+		t.Errorf("Expected 'pay the ', got %q", tb.Text)
+	}
+
+	tb.DeleteWordBackward()
+	if tb.Text != "pay " {
+		t.Errorf("Expected 'pay ', got %q", tb.Text)
+	}
+}
+
+func TestTextBoxMultibyteEditing(t *testing.T) {
+	tb := newTestTextBox("買い物")
+
+	tb.Backspace()
+	if tb.Text != "買い" {
+		t.Errorf("Expected '買い', got %q", tb.Text)
+	}
+
+	tb.MoveCursor(0, false)
+	tb.MoveCursor(1, true)
+	if got := tb.SelectedText(); got != "買" {
+		t.Errorf("Expected selected '買', got %q", got)
+	}
+}
+
+func TestTextBoxCursorBlinksWithClock(t *testing.T) {
+	clock := models.NewFakeClock(testNow)
+	tb := newTestTextBox("hello")
+	tb.Clock = clock
+	tb.SetFocus(true)
+
+	clock.Advance(500 * time.Millisecond)
+	tb.updateCursorBlink()
+	if !tb.ShowCursor {
+		t.Error("Expected the cursor to stay on for half a second")
+	}
+	clock.Advance(time.Millisecond)
+	tb.updateCursorBlink()
+	if tb.ShowCursor {
+		t.Error("Expected the cursor to turn off after half a second")
+	}
+
+	// Moving the cursor shows it again and restarts the blink
+	tb.MoveCursor(0, false)
+	clock.Advance(400 * time.Millisecond)
+	tb.updateCursorBlink()
+	if !tb.ShowCursor {
+		t.Error("Expected moving the cursor to show it")
+	}
+}
+
+func TestTextBoxTypesWithAltGr(t *testing.T) {
+	tb := newTestTextBox("mail")
+	tb.Focused = true
+	kb := NewKeyboard(DefaultKeyRepeatDelay, DefaultKeyRepeatInterval)
+	tb.Keyboard = kb
+	clock := models.NewFakeClock(testNow)
+
+	// Windows reports AltGr as Ctrl+Alt, here typing @ on a German layout
+	// and ą on a Polish one, where A would otherwise select all
+	altGr := []ebiten.Key{ebiten.KeyControlLeft, ebiten.KeyAltRight}
+	in := NewScriptedInput(
+		InputFrame{Keys: append(altGr, ebiten.KeyQ), Chars: "@"},
+		InputFrame{},
+		InputFrame{Keys: append(altGr, ebiten.KeyA), Chars: "ą"},
+		InputFrame{},
+	)
+	play(in, kb, clock, tb.Update)
+
+	if tb.Text != "mail@ą" || tb.HasSelection() {
+		t.Errorf("Expected AltGr to type text, got %q with selection %v", tb.Text, tb.HasSelection())
+	}
+
+	// Ctrl alone is still a shortcut
+	in.Add(InputFrame{Keys: []ebiten.Key{ebiten.KeyControlLeft, ebiten.KeyA}, Chars: "a"})
+	play(in, kb, clock, tb.Update)
+	if tb.Text != "mail@ą" || !tb.HasSelection() {
+		t.Errorf("Expected Ctrl+A to select all without typing, got %q with selection %v", tb.Text, tb.HasSelection())
+	}
+}
@@ -0,0 +1,318 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// Theme is a set of semantic colors that widgets draw with. Widgets read
+// the active theme every frame, so switching themes takes effect at once.
+type Theme struct {
+	Name string
+
+	Background     color.RGBA // Window background
+	Surface        color.RGBA // Header, footer, rows, inputs and panels
+	SurfaceHover   color.RGBA
+	Border         color.RGBA // Separators and panel borders
+	InputBorder    color.RGBA // Borders of inputs and checkboxes
+	Text           color.RGBA
+	TextMuted      color.RGBA // Placeholders, counts and completed todos
+	TextDisabled   color.RGBA
+	TextOnAccent   color.RGBA // Text on accent, secondary and danger buttons
+	Accent         color.RGBA
+	AccentHover    color.RGBA
+	Secondary      color.RGBA
+	SecondaryHover color.RGBA
+	Danger         color.RGBA
+	DangerHover    color.RGBA
+	Success        color.RGBA
+	Warning        color.RGBA // Medium priority
+	ButtonBorder   color.RGBA
+	Selection      color.RGBA // Selected text
+	SelectedRow    color.RGBA
+	FocusRing      color.RGBA
+	Link           color.RGBA
+	Code           color.RGBA
+	CodeBackground color.RGBA
+	ErrorSurface   color.RGBA
+	ErrorText      color.RGBA
+	Overlay        color.RGBA // Dims the window behind modal overlays
+}
+
+func LightTheme() *Theme {
+	return &Theme{
+		Name:           "Light",
+		Background:     color.RGBA{248, 249, 250, 255},
+		Surface:        color.RGBA{255, 255, 255, 255},
+		SurfaceHover:   color.RGBA{248, 249, 250, 255},
+		Border:         color.RGBA{200, 200, 200, 255},
+		InputBorder:    color.RGBA{108, 117, 125, 255},
+		Text:           color.RGBA{33, 37, 41, 255},
+		TextMuted:      color.RGBA{108, 117, 125, 255},
+		TextDisabled:   color.RGBA{200, 200, 200, 255},
+		TextOnAccent:   color.RGBA{255, 255, 255, 255},
+		Accent:         color.RGBA{0, 123, 255, 255},
+		AccentHover:    color.RGBA{0, 86, 179, 255},
+		Secondary:      color.RGBA{108, 117, 125, 255},
+		SecondaryHover: color.RGBA{90, 98, 104, 255},
+		Danger:         color.RGBA{220, 53, 69, 255},
+		DangerHover:    color.RGBA{200, 35, 51, 255},
+		Success:        color.RGBA{40, 167, 69, 255},
+		Warning:        color.RGBA{255, 193, 7, 255},
+		ButtonBorder:   color.RGBA{0, 0, 0, 100},
+		Selection:      color.RGBA{179, 215, 255, 255},
+		SelectedRow:    color.RGBA{232, 242, 255, 255},
+		FocusRing:      color.RGBA{0, 123, 255, 160},
+		Link:           color.RGBA{0, 123, 255, 255},
+		Code:           color.RGBA{214, 51, 132, 255},
+		CodeBackground: color.RGBA{233, 236, 239, 255},
+		ErrorSurface:   color.RGBA{248, 215, 218, 255},
+		ErrorText:      color.RGBA{114, 28, 36, 255},
+		Overlay:        color.RGBA{0, 0, 0, 128},
+	}
+}
+
+func DarkTheme() *Theme {
+	return &Theme{
+		Name:           "Dark",
+		Background:     color.RGBA{24, 26, 27, 255},
+		Surface:        color.RGBA{36, 39, 42, 255},
+		SurfaceHover:   color.RGBA{45, 49, 53, 255},
+		Border:         color.RGBA{64, 68, 72, 255},
+		InputBorder:    color.RGBA{108, 117, 125, 255},
+		Text:           color.RGBA{230, 232, 234, 255},
+		TextMuted:      color.RGBA{150, 158, 165, 255},
+		TextDisabled:   color.RGBA{110, 114, 118, 255},
+		TextOnAccent:   color.RGBA{255, 255, 255, 255},
+		Accent:         color.RGBA{61, 139, 253, 255},
+		AccentHover:    color.RGBA{40, 110, 220, 255},
+		Secondary:      color.RGBA{80, 88, 96, 255},
+		SecondaryHover: color.RGBA{96, 104, 112, 255},
+		Danger:         color.RGBA{220, 53, 69, 255},
+		DangerHover:    color.RGBA{240, 80, 95, 255},
+		Success:        color.RGBA{75, 191, 107, 255},
+		Warning:        color.RGBA{255, 202, 44, 255},
+		ButtonBorder:   color.RGBA{0, 0, 0, 100},
+		Selection:      color.RGBA{38, 79, 120, 255},
+		SelectedRow:    color.RGBA{30, 52, 80, 255},
+		FocusRing:      color.RGBA{61, 139, 253, 180},
+		Link:           color.RGBA{110, 168, 254, 255},
+		Code:           color.RGBA{240, 120, 170, 255},
+		CodeBackground: color.RGBA{52, 56, 60, 255},
+		ErrorSurface:   color.RGBA{66, 28, 32, 255},
+		ErrorText:      color.RGBA{248, 180, 186, 255},
+		Overlay:        color.RGBA{0, 0, 0, 160},
+	}
+}
+
+// HighContrastTheme uses pure black and white with saturated accents so
+// that every element stands out from its background.
+func HighContrastTheme() *Theme {
+	return &Theme{
+		Name:           "High Contrast",
+		Background:     color.RGBA{0, 0, 0, 255},
+		Surface:        color.RGBA{0, 0, 0, 255},
+		SurfaceHover:   color.RGBA{40, 40, 40, 255},
+		Border:         color.RGBA{255, 255, 255, 255},
+		InputBorder:    color.RGBA{255, 255, 255, 255},
+		Text:           color.RGBA{255, 255, 255, 255},
+		TextMuted:      color.RGBA{220, 220, 220, 255},
+		TextDisabled:   color.RGBA{160, 160, 160, 255},
+		TextOnAccent:   color.RGBA{0, 0, 0, 255},
+		Accent:         color.RGBA{255, 255, 0, 255},
+		AccentHover:    color.RGBA{255, 255, 160, 255},
+		Secondary:      color.RGBA{200, 200, 200, 255},
+		SecondaryHover: color.RGBA{255, 255, 255, 255},
+		Danger:         color.RGBA{255, 100, 100, 255},
+		DangerHover:    color.RGBA{255, 150, 150, 255},
+		Success:        color.RGBA{0, 255, 0, 255},
+		Warning:        color.RGBA{255, 165, 0, 255},
+		ButtonBorder:   color.RGBA{255, 255, 255, 255},
+		Selection:      color.RGBA{0, 90, 255, 255},
+		SelectedRow:    color.RGBA{0, 60, 140, 255},
+		FocusRing:      color.RGBA{0, 255, 255, 255},
+		Link:           color.RGBA{0, 255, 255, 255},
+		Code:           color.RGBA{255, 170, 255, 255},
+		CodeBackground: color.RGBA{50, 50, 50, 255},
+		ErrorSurface:   color.RGBA{0, 0, 0, 255},
+		ErrorText:      color.RGBA{255, 100, 100, 255},
+		Overlay:        color.RGBA{0, 0, 0, 200},
+	}
+}
+
+// BuiltinThemes returns the themes that ship with the app.
+func BuiltinThemes() []*Theme {
+	return []*Theme{LightTheme(), DarkTheme(), HighContrastTheme()}
+}
+
+var currentTheme = LightTheme()
+
+// CurrentTheme returns the active theme.
+func CurrentTheme() *Theme {
+	return currentTheme
+}
+
+// SetTheme makes t the active theme.
+func SetTheme(t *Theme) {
+	currentTheme = t
+}
+
+// FindTheme returns the theme with the given name, ignoring case.
+func FindTheme(themes []*Theme, name string) (*Theme, bool) {
+	for _, t := range themes {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// tokens maps the names used in theme files to the colors of t.
+func (t *Theme) tokens() map[string]*color.RGBA {
+	return map[string]*color.RGBA{
+		"background":      &t.Background,
+		"surface":         &t.Surface,
+		"surface_hover":   &t.SurfaceHover,
+		"border":          &t.Border,
+		"input_border":    &t.InputBorder,
+		"text":            &t.Text,
+		"text_muted":      &t.TextMuted,
+		"text_disabled":   &t.TextDisabled,
+		"text_on_accent":  &t.TextOnAccent,
+		"accent":          &t.Accent,
+		"accent_hover":    &t.AccentHover,
+		"secondary":       &t.Secondary,
+		"secondary_hover": &t.SecondaryHover,
+		"danger":          &t.Danger,
+		"danger_hover":    &t.DangerHover,
+		"success":         &t.Success,
+		"warning":         &t.Warning,
+		"button_border":   &t.ButtonBorder,
+		"selection":       &t.Selection,
+		"selected_row":    &t.SelectedRow,
+		"focus_ring":      &t.FocusRing,
+		"link":            &t.Link,
+		"code":            &t.Code,
+		"code_background": &t.CodeBackground,
+		"error_surface":   &t.ErrorSurface,
+		"error_text":      &t.ErrorText,
+		"overlay":         &t.Overlay,
+	}
+}
+
+// themeFile is the JSON form of a custom theme. Colors override the tokens
+// of the base theme and are written as "#RRGGBB" or "#RRGGBBAA":
+//
+//	{"name": "Solarized", "base": "Dark", "colors": {"accent": "#268bd2"}}
+type themeFile struct {
+	Name   string            `json:"name"`
+	Base   string            `json:"base"`
+	Colors map[string]string `json:"colors"`
+}
+
+// ParseTheme reads a custom theme. The base theme is looked up in bases and
+// defaults to Light.
+func ParseTheme(data []byte, bases []*Theme) (*Theme, error) {
+	var file themeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, &models.AppError{
+			Type:    models.ErrorValidation,
+			Message: "Failed to parse theme",
+			Err:     err,
+		}
+	}
+	if strings.TrimSpace(file.Name) == "" {
+		return nil, &models.AppError{Type: models.ErrorValidation, Message: "Theme has no name"}
+	}
+
+	baseName := file.Base
+	if baseName == "" {
+		baseName = "Light"
+	}
+	base, ok := FindTheme(bases, baseName)
+	if !ok {
+		return nil, &models.AppError{
+			Type:    models.ErrorValidation,
+			Message: fmt.Sprintf("Theme %q has unknown base %q", file.Name, file.Base),
+		}
+	}
+
+	theme := *base
+	theme.Name = file.Name
+	tokens := theme.tokens()
+	for name, value := range file.Colors {
+		dst, ok := tokens[name]
+		if !ok {
+			return nil, &models.AppError{
+				Type:    models.ErrorValidation,
+				Message: fmt.Sprintf("Theme %q has unknown color %q", file.Name, name),
+			}
+		}
+		c, err := parseHexColor(value)
+		if err != nil {
+			return nil, &models.AppError{
+				Type:    models.ErrorValidation,
+				Message: fmt.Sprintf("Theme %q has invalid color %q", file.Name, name),
+				Err:     err,
+			}
+		}
+		*dst = c
+	}
+	return &theme, nil
+}
+
+// LoadThemeDir loads every .json file in dir as a custom theme based on
+// bases. A missing directory yields no themes. Files that fail to load are
+// skipped and reported together in the error.
+func LoadThemeDir(dir string, bases []*Theme) ([]*Theme, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var themes []*Theme
+	var failures []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var theme *Theme
+			if theme, err = ParseTheme(data, bases); err == nil {
+				themes = append(themes, theme)
+				continue
+			}
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", filepath.Base(path), err))
+	}
+
+	if len(failures) > 0 {
+		return themes, &models.AppError{
+			Type:    models.ErrorStorage,
+			Message: "Failed to load themes: " + strings.Join(failures, "; "),
+		}
+	}
+	return themes, nil
+}
+
+func parseHexColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return color.RGBA{}, fmt.Errorf("expected #RRGGBB or #RRGGBBAA, got %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("expected #RRGGBB or #RRGGBBAA, got %q", s)
+	}
+	if len(hex) == 6 {
+		v = v<<8 | 0xff
+	}
+	return color.RGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestThemeTokensCoverEveryColor(t *testing.T) {
+	theme := LightTheme()
+	tokens := theme.tokens()
+
+	covered := make(map[*color.RGBA]bool)
+	for _, c := range tokens {
+		covered[c] = true
+	}
+
+	v := reflect.ValueOf(theme).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Type() != reflect.TypeOf(color.RGBA{}) {
+			continue
+		}
+		if !covered[field.Addr().Interface().(*color.RGBA)] {
+			t.Errorf("Color %s cannot be set from a theme file", v.Type().Field(i).Name)
+		}
+	}
+}
+
+func TestParseTheme(t *testing.T) {
+	data := []byte(`{"name": "Ocean", "base": "dark", "colors": {"accent": "#268bd2", "overlay": "#00000080"}}`)
+
+	theme, err := ParseTheme(data, BuiltinThemes())
+	if err != nil {
+		t.Fatalf("Failed to parse theme: %v", err)
+	}
+	if theme.Name != "Ocean" {
+		t.Errorf("Expected name Ocean, got %q", theme.Name)
+	}
+	if want := (color.RGBA{0x26, 0x8b, 0xd2, 0xff}); theme.Accent != want {
+		t.Errorf("Expected accent %v, got %v", want, theme.Accent)
+	}
+	if want := (color.RGBA{0, 0, 0, 0x80}); theme.Overlay != want {
+		t.Errorf("Expected overlay %v, got %v", want, theme.Overlay)
+	}
+	if theme.Surface != DarkTheme().Surface {
+		t.Errorf("Expected unset colors to come from the base theme, got surface %v", theme.Surface)
+	}
+}
+
+func TestParseThemeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"invalid json", `{`},
+		{"missing name", `{"colors": {}}`},
+		{"unknown base", `{"name": "x", "base": "Sepia"}`},
+		{"unknown color", `{"name": "x", "colors": {"accnet": "#000000"}}`},
+		{"bad hex", `{"name": "x", "colors": {"accent": "#12345"}}`},
+		{"not hex", `{"name": "x", "colors": {"accent": "#zzzzzz"}}`},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseTheme([]byte(tt.data), BuiltinThemes()); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestLoadThemeDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ocean.json":  `{"name": "Ocean", "colors": {"accent": "#268bd2"}}`,
+		"broken.json": `{"name": "Broken", "colors": {"nope": "#000000"}}`,
+		"notes.txt":   `not a theme`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	themes, err := LoadThemeDir(dir, BuiltinThemes())
+	if err == nil {
+		t.Error("Expected the broken theme to be reported")
+	}
+	if len(themes) != 1 || themes[0].Name != "Ocean" {
+		t.Errorf("Expected only the Ocean theme to load, got %v", themes)
+	}
+
+	// A missing directory is not an error
+	themes, err = LoadThemeDir(filepath.Join(dir, "missing"), BuiltinThemes())
+	if err != nil || len(themes) != 0 {
+		t.Errorf("Expected no themes and no error, got %v, %v", themes, err)
+	}
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/lapis2411/todo/internal/models"
+)
+
+// TodoChip is a todo shown as a single line, such as on a day of the
+// calendar. Completed todos are dimmed and overdue ones shown in the
+// danger color.
+type TodoChip struct {
+	Todo    *models.Todo
+	X, Y    int
+	Width   int
+	Height  int
+	Overdue bool
+	// Dragging draws the chip with an accent border while it follows the
+	// cursor.
+	Dragging bool
+}
+
+func NewTodoChip(todo *models.Todo, x, y, width, height int) *TodoChip {
+	return &TodoChip{
+		Todo:   todo,
+		X:      x,
+		Y:      y,
+		Width:  width,
+		Height: height,
+	}
+}
+
+func (c *TodoChip) Draw(screen *ebiten.Image) {
+	theme := CurrentTheme()
+
+	if c.Dragging {
+		DrawRect(screen, float64(c.X-1), float64(c.Y-1), float64(c.Width+2), float64(c.Height+2), theme.Accent)
+	}
+	DrawRect(screen, float64(c.X), float64(c.Y), float64(c.Width), float64(c.Height), theme.SurfaceHover)
+	if barColor, ok := priorityColor(c.Todo.Priority); ok {
+		DrawRect(screen, float64(c.X), float64(c.Y), 3, float64(c.Height), barColor)
+	}
+
+	textColor := theme.Text
+	switch {
+	case c.Todo.Completed:
+		textColor = theme.TextMuted
+	case c.Overdue:
+		textColor = theme.Danger
+	}
+	label := TruncateText(c.Todo.Text, c.Width-8)
+	DrawText(screen, label, c.X+5, c.Y+(c.Height+10)/2, textColor)
+}
+
+func (c *TodoChip) Bounds() image.Rectangle {
+	return image.Rect(c.X, c.Y, c.X+c.Width, c.Y+c.Height)
+}
+
+func (c *TodoChip) Contains(x, y int) bool {
+	return image.Pt(x, y).In(c.Bounds())
+}
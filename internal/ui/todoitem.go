@@ -1,15 +1,15 @@
 package ui
 
 import (
+	"image"
 	"image/color"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
-	"github.com/hajimehoshi/ebiten/v2/text"
-	"golang.org/x/image/font/basicfont"
 
+	"github.com/lapis2411/todo/internal/markdown"
 	"github.com/lapis2411/todo/internal/models"
 )
 
@@ -23,10 +23,52 @@ type TodoItem struct {
 	EditTextBox   *TextBox
 	Checkbox      *Button
 	DeleteBtn     *Button
+	NotesBtn      *Button
 	lastClickTime time.Time
 	Hovered       bool
+
+	// Expanded shows the notes pane below the row. The pane renders the
+	// notes as Markdown until EditingNotes switches it to the editor.
+	Expanded       bool
+	EditingNotes   bool
+	NotesView      *RichText
+	NotesArea      *TextArea
+	EditNotesBtn   *Button
+	SaveNotesBtn   *Button
+	CancelNotesBtn *Button
+	// OnNotesSave and OnNotesCancel let the parent persist or discard notes.
+	// Without them the item updates its todo and collapses itself.
+	OnNotesSave   func(notes string)
+	OnNotesCancel func()
+	// OnLinkClick is called with the URL of a link clicked in the todo text or notes.
+	OnLinkClick func(url string)
+	// OnEdit lets the parent persist edited text. Without it the item
+	// updates its todo directly.
+	OnEdit func(text string)
+
+	// Selected marks the row picked with the arrow keys.
+	Selected bool
+	// FocusManager, when set, receives the item's focus requests for its
+	// editors instead of the item focusing them itself.
+	FocusManager *FocusManager
+	// Clock times double-clicks and the changes the item makes to its todo.
+	// Set it with SetClock so that the editors share it.
+	Clock models.Clock
+
+	titleSource    string
+	titleWidth     int
+	titleFragments []textFragment
 }
 
+const (
+	// NotesPaneHeight is the height an expanded item adds below its row
+	NotesPaneHeight   = 170
+	notesAreaHeight   = 120
+	notesButtonWidth  = 50
+	notesActionHeight = 28
+	priorityBarWidth  = 4
+)
+
 func NewTodoItem(todo *models.Todo, x, y, width, height int) *TodoItem {
 	item := &TodoItem{
 		Todo:   todo,
@@ -43,14 +85,9 @@ func NewTodoItem(todo *models.Todo, x, y, width, height int) *TodoItem {
 		checkboxSize, checkboxSize,
 		"", // No text, will draw custom checkbox
 		func() {
-			todo.Toggle()
+			todo.Toggle(item.clock().Now())
 		},
 	)
-	item.Checkbox.SetColors(
-		color.RGBA{255, 255, 255, 255}, // White background
-		color.RGBA{240, 240, 240, 255}, // Light gray hover
-		color.RGBA{0, 0, 0, 255},       // Black text
-	)
 
 	// Create delete button
 	deleteSize := 24
@@ -62,11 +99,30 @@ func NewTodoItem(todo *models.Todo, x, y, width, height int) *TodoItem {
 			// Delete functionality will be handled by parent
 		},
 	)
-	item.DeleteBtn.SetColors(
-		color.RGBA{220, 53, 69, 255},   // Red background
-		color.RGBA{200, 35, 51, 255},   // Darker red hover
-		color.RGBA{255, 255, 255, 255}, // White text
+	item.DeleteBtn.SetVariant(ButtonDanger)
+
+	// Create notes toggle button
+	item.NotesBtn = NewButton(
+		x+width-deleteSize-notesButtonWidth-16, y+(height-deleteSize)/2,
+		notesButtonWidth, deleteSize,
+		"Notes",
+		func() {
+			item.SetExpanded(!item.Expanded)
+			item.FocusNotes()
+		},
 	)
+	item.updateNotesButtonColors()
+
+	// Create notes view, editor and their buttons (initially hidden)
+	item.NotesView = NewRichText(x+40, y+height, width-80, notesAreaHeight)
+	item.NotesArea = NewTextArea(x+40, y+height, width-80, notesAreaHeight, "Add notes...")
+	item.EditNotesBtn = NewButton(x+40, y+height+notesAreaHeight+8, 70, notesActionHeight, "Edit", func() {
+		item.setEditingNotes(true)
+		item.FocusNotes()
+	})
+	item.SaveNotesBtn = NewButton(x+40, y+height+notesAreaHeight+8, 70, notesActionHeight, "Save", item.saveNotes)
+	item.CancelNotesBtn = NewButton(x+118, y+height+notesAreaHeight+8, 70, notesActionHeight, "Cancel", item.cancelNotes)
+	item.CancelNotesBtn.SetVariant(ButtonSecondary)
 
 	// Create edit textbox (initially hidden)
 	textboxX := x + 40
@@ -76,62 +132,226 @@ func NewTodoItem(todo *models.Todo, x, y, width, height int) *TodoItem {
 	return item
 }
 
-func (ti *TodoItem) Update() {
+func (ti *TodoItem) Update(in InputSource) {
 	// Update position-dependent components if position changed
 	ti.updateComponentPositions()
 
 	// Handle mouse hover
-	mouseX, mouseY := ebiten.CursorPosition()
+	mouseX, mouseY := CursorPosition(in)
 	ti.Hovered = mouseX >= ti.X && mouseX <= ti.X+ti.Width && 
 		       mouseY >= ti.Y && mouseY <= ti.Y+ti.Height
 
+	// Handle link clicks in the todo text and notes
+	linkClicked := false
+	if in.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && !ti.Editing {
+		if url, ok := ti.linkAt(mouseX, mouseY); ok {
+			linkClicked = true
+			if ti.OnLinkClick != nil {
+				ti.OnLinkClick(url)
+			}
+		}
+	}
+
+	if ti.Expanded && ti.EditingNotes {
+		ti.NotesArea.Update(in)
+		ti.SaveNotesBtn.Update(in)
+		ti.CancelNotesBtn.Update(in)
+
+		// Handle Ctrl+Enter to save and Escape to cancel
+		if ti.NotesArea.IsSaveRequested() {
+			ti.saveNotes()
+		} else if ti.NotesArea.IsEscapePressed() {
+			ti.cancelNotes()
+		}
+	} else if ti.Expanded {
+		ti.EditNotesBtn.Update(in)
+		ti.CancelNotesBtn.Update(in)
+	}
+
 	if ti.Editing {
-		ti.EditTextBox.Update()
+		ti.EditTextBox.Update(in)
 		
 		// Handle Enter key to save
 		if ti.EditTextBox.IsEnterPressed() {
 			newText := ti.EditTextBox.GetText()
 			if len(newText) > 0 {
-				ti.Todo.SetText(newText)
 				ti.Editing = false
+				ti.releaseFocus(ti.EditTextBox)
+				if ti.OnEdit != nil {
+					ti.OnEdit(newText)
+				} else {
+					ti.Todo.SetText(newText, ti.clock().Now())
+				}
 			}
 		}
 		
 		// Handle Escape key to cancel
 		if ti.EditTextBox.IsEscapePressed() {
 			ti.Editing = false
+			ti.releaseFocus(ti.EditTextBox)
 		}
 	} else {
 		// Update checkbox and delete button only when not editing
-		ti.Checkbox.Update()
-		ti.DeleteBtn.Update()
+		ti.Checkbox.Update(in)
+		ti.DeleteBtn.Update(in)
+		ti.NotesBtn.Update(in)
 
 		// Handle double-click to edit
-		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && ti.Hovered {
-			currentTime := time.Now()
+		if in.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && ti.Hovered && !linkClicked {
+			currentTime := ti.clock().Now()
 			if currentTime.Sub(ti.lastClickTime) < 300*time.Millisecond {
 				// Double-click detected
-				ti.startEditing()
+				ti.StartEditing()
 			}
 			ti.lastClickTime = currentTime
 		}
 	}
 }
 
-func (ti *TodoItem) startEditing() {
+// StartEditing switches the row to the inline text editor.
+func (ti *TodoItem) StartEditing() {
+	ti.ResumeEditing(ti.Todo.Text)
+	ti.requestFocus(ti.EditTextBox)
+}
+
+// ResumeEditing opens the inline text editor with text in place of the
+// saved text, such as an edit kept from an earlier row or session. It
+// leaves focus where it is.
+func (ti *TodoItem) ResumeEditing(text string) {
 	ti.Editing = true
 	ti.EditText = ti.Todo.Text
-	ti.EditTextBox.SetText(ti.Todo.Text)
-	ti.EditTextBox.SetFocus(true)
+	ti.EditTextBox.SetText(text)
+}
+
+// SetClock sets the clock of the item and its editors.
+func (ti *TodoItem) SetClock(clock models.Clock) {
+	ti.Clock = clock
+	ti.EditTextBox.Clock = clock
+	ti.NotesArea.Clock = clock
+}
+
+func (ti *TodoItem) clock() models.Clock {
+	if ti.Clock != nil {
+		return ti.Clock
+	}
+	return models.SystemClock
+}
+
+// requestFocus focuses w through the focus manager when there is one.
+func (ti *TodoItem) requestFocus(w Focusable) {
+	if ti.FocusManager != nil {
+		ti.FocusManager.Focus(w)
+	} else {
+		w.SetFocus(true)
+	}
+}
+
+// releaseFocus drops focus from a hidden editor. With a focus manager the
+// owner decides where focus goes next.
+func (ti *TodoItem) releaseFocus(w Focusable) {
+	if ti.FocusManager == nil {
+		w.SetFocus(false)
+	}
+}
+
+// SetExpanded shows or hides the notes pane. Existing notes open rendered,
+// and a todo without notes opens straight into the editor.
+func (ti *TodoItem) SetExpanded(expanded bool) {
+	ti.Expanded = expanded
+	ti.NotesView.SetSource(ti.Todo.Notes)
+	ti.setEditingNotes(expanded && ti.Todo.Notes == "")
+}
+
+func (ti *TodoItem) setEditingNotes(editing bool) {
+	ti.EditingNotes = editing
+	if editing {
+		ti.NotesArea.SetText(ti.Todo.Notes)
+		ti.CancelNotesBtn.SetText("Cancel")
+	} else {
+		ti.CancelNotesBtn.SetText("Close")
+	}
+	if !editing {
+		ti.releaseFocus(ti.NotesArea)
+	}
+}
+
+// EditNotes opens the notes editor with notes in place of the saved ones,
+// such as an edit kept from an earlier row or session. It leaves focus
+// where it is.
+func (ti *TodoItem) EditNotes(notes string) {
+	ti.Expanded = true
+	ti.NotesView.SetSource(ti.Todo.Notes)
+	ti.setEditingNotes(true)
+	ti.NotesArea.SetText(notes)
+}
+
+// FocusNotes focuses the notes editor if it is open.
+func (ti *TodoItem) FocusNotes() {
+	if ti.Expanded && ti.EditingNotes {
+		ti.requestFocus(ti.NotesArea)
+	}
+}
+
+// linkAt returns the URL of the link under the given screen position.
+func (ti *TodoItem) linkAt(x, y int) (string, bool) {
+	if url, ok := fragmentLinkAt(ti.layoutTitle(), x-ti.X-40, y-ti.titleTop()); ok {
+		return url, true
+	}
+	if ti.Expanded && !ti.EditingNotes {
+		return ti.NotesView.LinkAt(x, y)
+	}
+	return "", false
+}
+
+func (ti *TodoItem) saveNotes() {
+	notes := strings.TrimRight(ti.NotesArea.GetText(), " \n")
+	if ti.OnNotesSave != nil {
+		ti.OnNotesSave(notes)
+		return
+	}
+	ti.Todo.SetNotes(notes, ti.clock().Now())
+	ti.updateNotesButtonColors()
+	ti.SetExpanded(false)
+}
+
+func (ti *TodoItem) cancelNotes() {
+	ti.NotesArea.SetText(ti.Todo.Notes)
+	if ti.OnNotesCancel != nil {
+		ti.OnNotesCancel()
+		return
+	}
+	ti.SetExpanded(false)
+}
+
+// updateNotesButtonColors highlights the notes button when the todo has notes.
+func (ti *TodoItem) updateNotesButtonColors() {
+	if ti.Todo.Notes != "" {
+		ti.NotesBtn.SetVariant(ButtonPrimary)
+	} else {
+		ti.NotesBtn.SetVariant(ButtonSecondary)
+	}
+}
+
+// TotalHeight returns the height of the row including the notes pane when expanded.
+func (ti *TodoItem) TotalHeight() int {
+	if ti.Expanded {
+		return ti.Height + NotesPaneHeight
+	}
+	return ti.Height
 }
 
 func (ti *TodoItem) Draw(screen *ebiten.Image) {
+	theme := CurrentTheme()
+
 	// Draw background
-	bgColor := color.RGBA{255, 255, 255, 255}
-	if ti.Hovered {
-		bgColor = color.RGBA{248, 249, 250, 255}
+	bgColor := theme.Surface
+	if ti.Selected {
+		bgColor = theme.SelectedRow
+	} else if ti.Hovered {
+		bgColor = theme.SurfaceHover
 	}
-	ebitenutil.DrawRect(screen, float64(ti.X), float64(ti.Y), float64(ti.Width), float64(ti.Height), bgColor)
+	DrawRect(screen, float64(ti.X), float64(ti.Y), float64(ti.Width), float64(ti.TotalHeight()), bgColor)
+	ti.drawPriority(screen)
 
 	if ti.Editing {
 		// Draw edit mode
@@ -140,12 +360,25 @@ func (ti *TodoItem) Draw(screen *ebiten.Image) {
 		// Draw normal mode
 		ti.drawCheckbox(screen)
 		ti.drawTodoText(screen)
+		ti.drawTags(screen)
+		ti.NotesBtn.Draw(screen)
 		ti.DeleteBtn.Draw(screen)
 	}
 
+	// Draw notes pane
+	if ti.Expanded && ti.EditingNotes {
+		ti.NotesArea.Draw(screen)
+		ti.SaveNotesBtn.Draw(screen)
+		ti.CancelNotesBtn.Draw(screen)
+	} else if ti.Expanded {
+		ti.NotesView.Draw(screen)
+		ti.EditNotesBtn.Draw(screen)
+		ti.CancelNotesBtn.Draw(screen)
+	}
+
 	// Draw separator line
-	separatorColor := color.RGBA{200, 200, 200, 255}
-	ebitenutil.DrawRect(screen, float64(ti.X), float64(ti.Y+ti.Height-1), float64(ti.Width), 1, separatorColor)
+	separatorColor := theme.Border
+	DrawRect(screen, float64(ti.X), float64(ti.Y+ti.TotalHeight()-1), float64(ti.Width), 1, separatorColor)
 }
 
 func (ti *TodoItem) drawCheckbox(screen *ebiten.Image) {
@@ -153,74 +386,142 @@ func (ti *TodoItem) drawCheckbox(screen *ebiten.Image) {
 	x := ti.X + 8
 	y := ti.Y + (ti.Height-checkboxSize)/2
 
+	theme := CurrentTheme()
+
 	// Draw checkbox background
-	bgColor := color.RGBA{255, 255, 255, 255}
+	bgColor := theme.Surface
 	if ti.Checkbox.Hovered {
-		bgColor = color.RGBA{240, 240, 240, 255}
+		bgColor = theme.SurfaceHover
 	}
-	ebitenutil.DrawRect(screen, float64(x), float64(y), float64(checkboxSize), float64(checkboxSize), bgColor)
+	DrawRect(screen, float64(x), float64(y), float64(checkboxSize), float64(checkboxSize), bgColor)
 
 	// Draw checkbox border
-	borderColor := color.RGBA{108, 117, 125, 255}
-	ebitenutil.DrawRect(screen, float64(x), float64(y), float64(checkboxSize), 1, borderColor)
-	ebitenutil.DrawRect(screen, float64(x), float64(y), 1, float64(checkboxSize), borderColor)
-	ebitenutil.DrawRect(screen, float64(x+checkboxSize-1), float64(y), 1, float64(checkboxSize), borderColor)
-	ebitenutil.DrawRect(screen, float64(x), float64(y+checkboxSize-1), float64(checkboxSize), 1, borderColor)
+	borderColor := theme.InputBorder
+	DrawRect(screen, float64(x), float64(y), float64(checkboxSize), 1, borderColor)
+	DrawRect(screen, float64(x), float64(y), 1, float64(checkboxSize), borderColor)
+	DrawRect(screen, float64(x+checkboxSize-1), float64(y), 1, float64(checkboxSize), borderColor)
+	DrawRect(screen, float64(x), float64(y+checkboxSize-1), float64(checkboxSize), 1, borderColor)
 
 	// Draw checkmark if completed
 	if ti.Todo.Completed {
-		checkColor := color.RGBA{40, 167, 69, 255} // Green
+		checkColor := theme.Success
 		// Simple checkmark using rectangles
-		ebitenutil.DrawRect(screen, float64(x+4), float64(y+10), 3, 2, checkColor)
-		ebitenutil.DrawRect(screen, float64(x+6), float64(y+12), 2, 2, checkColor)
-		ebitenutil.DrawRect(screen, float64(x+8), float64(y+8), 2, 6, checkColor)
-		ebitenutil.DrawRect(screen, float64(x+10), float64(y+6), 2, 4, checkColor)
-		ebitenutil.DrawRect(screen, float64(x+12), float64(y+4), 2, 4, checkColor)
-		ebitenutil.DrawRect(screen, float64(x+14), float64(y+6), 2, 2, checkColor)
+		DrawRect(screen, float64(x+4), float64(y+10), 3, 2, checkColor)
+		DrawRect(screen, float64(x+6), float64(y+12), 2, 2, checkColor)
+		DrawRect(screen, float64(x+8), float64(y+8), 2, 6, checkColor)
+		DrawRect(screen, float64(x+10), float64(y+6), 2, 4, checkColor)
+		DrawRect(screen, float64(x+12), float64(y+4), 2, 4, checkColor)
+		DrawRect(screen, float64(x+14), float64(y+6), 2, 2, checkColor)
 	}
 }
 
 func (ti *TodoItem) drawTodoText(screen *ebiten.Image) {
 	textX := ti.X + 40
-	textY := ti.Y + (ti.Height+text.BoundString(basicfont.Face7x13, "A").Max.Y)/2
-
-	textColor := color.RGBA{33, 37, 41, 255}
-	displayText := ti.Todo.Text
-
-	// Truncate text if too long
-	maxWidth := ti.Width - 80 // Account for checkbox and delete button
-	if text.BoundString(basicfont.Face7x13, displayText).Max.X > maxWidth {
-		for len(displayText) > 0 {
-			if text.BoundString(basicfont.Face7x13, displayText+"...").Max.X <= maxWidth {
-				displayText += "..."
-				break
-			}
-			displayText = displayText[:len(displayText)-1]
-		}
-	}
+	top := ti.titleTop()
+
+	colors := themeRichTextColors(CurrentTheme())
 
-	// Apply strikethrough for completed tasks
+	// Gray out completed tasks
 	if ti.Todo.Completed {
-		textColor = color.RGBA{108, 117, 125, 255} // Gray color for completed
+		colors.Text = CurrentTheme().TextMuted
+		colors.Link = colors.Text
+		colors.Code = colors.Text
 	}
 
-	text.Draw(screen, displayText, basicfont.Face7x13, textX, textY, textColor)
+	fragments := ti.layoutTitle()
+	drawFragments(screen, fragments, textX, top, colors)
 
 	// Draw strikethrough line for completed tasks
-	if ti.Todo.Completed {
-		textBounds := text.BoundString(basicfont.Face7x13, displayText)
-		lineY := textY - textBounds.Max.Y/2
-		lineWidth := textBounds.Max.X
-		ebitenutil.DrawRect(screen, float64(textX), float64(lineY), float64(lineWidth), 1, textColor)
+	if ti.Todo.Completed && len(fragments) > 0 {
+		last := fragments[len(fragments)-1]
+		lineY := top + richTextBaseline - 4
+		DrawRect(screen, float64(textX), float64(lineY), float64(last.X+last.Width), 1, colors.Text)
+	}
+}
+
+// drawPriority marks the left edge of the row with the todo's priority.
+func (ti *TodoItem) drawPriority(screen *ebiten.Image) {
+	if barColor, ok := priorityColor(ti.Todo.Priority); ok {
+		DrawRect(screen, float64(ti.X), float64(ti.Y), priorityBarWidth, float64(ti.Height), barColor)
 	}
 }
 
+// priorityColor returns the color marking a priority, if it has one.
+func priorityColor(priority models.Priority) (color.RGBA, bool) {
+	theme := CurrentTheme()
+	switch priority {
+	case models.PriorityHigh:
+		return theme.Danger, true
+	case models.PriorityMedium:
+		return theme.Warning, true
+	case models.PriorityLow:
+		return theme.Accent, true
+	default:
+		return color.RGBA{}, false
+	}
+}
+
+// drawTags draws the todo's tags dimmed, right-aligned before the notes button.
+func (ti *TodoItem) drawTags(screen *ebiten.Image) {
+	label := ti.tagLabel()
+	if label == "" {
+		return
+	}
+	x := ti.NotesBtn.X - 8 - textWidth(label)
+	DrawText(screen, label, x, ti.titleTop()+richTextBaseline, CurrentTheme().TextMuted)
+}
+
+// tagLabel returns the tags as "#tag" words, truncated to a third of the row.
+func (ti *TodoItem) tagLabel() string {
+	if len(ti.Todo.Tags) == 0 {
+		return ""
+	}
+	words := make([]string, len(ti.Todo.Tags))
+	for i, tag := range ti.Todo.Tags {
+		words[i] = "#" + tag
+	}
+	return TruncateText(strings.Join(words, " "), ti.Width/3)
+}
+
+// titleTop returns the top of the line the todo text is drawn on.
+func (ti *TodoItem) titleTop() int {
+	return ti.Y + (ti.Height-richTextLineHeight)/2
+}
+
+// layoutTitle lays out the todo text as a single line of Markdown,
+// truncated to the space between the checkbox and the buttons.
+func (ti *TodoItem) layoutTitle() []textFragment {
+	maxWidth := ti.Width - 80 - notesButtonWidth - 8 // Account for checkbox, notes and delete buttons
+	if label := ti.tagLabel(); label != "" {
+		maxWidth -= textWidth(label) + 8
+	}
+	if ti.titleSource != ti.Todo.Text || ti.titleWidth != maxWidth || ti.titleFragments == nil {
+		fragments, _ := layoutRuns(nil, markdown.ParseInline(ti.Todo.Text), 0, 0, math.MaxInt32)
+		ti.titleFragments = truncateFragments(fragments, maxWidth)
+		ti.titleSource = ti.Todo.Text
+		ti.titleWidth = maxWidth
+	}
+	return ti.titleFragments
+}
+
 func (ti *TodoItem) updateComponentPositions() {
 	checkboxSize := 20
 	ti.Checkbox.SetPosition(ti.X+8, ti.Y+(ti.Height-checkboxSize)/2)
 
 	deleteSize := 24
 	ti.DeleteBtn.SetPosition(ti.X+ti.Width-deleteSize-8, ti.Y+(ti.Height-deleteSize)/2)
+	ti.NotesBtn.SetPosition(ti.X+ti.Width-deleteSize-notesButtonWidth-16, ti.Y+(ti.Height-deleteSize)/2)
+
+	notesY := ti.Y + ti.Height
+	ti.NotesView.X = ti.X + 40
+	ti.NotesView.Y = notesY
+	ti.NotesView.Width = ti.Width - 80
+	ti.NotesArea.X = ti.X + 40
+	ti.NotesArea.Y = notesY
+	ti.NotesArea.Width = ti.Width - 80
+	ti.EditNotesBtn.SetPosition(ti.X+40, notesY+notesAreaHeight+8)
+	ti.SaveNotesBtn.SetPosition(ti.X+40, notesY+notesAreaHeight+8)
+	ti.CancelNotesBtn.SetPosition(ti.X+118, notesY+notesAreaHeight+8)
 
 	textboxX := ti.X + 40
 	textboxWidth := ti.Width - 80
@@ -250,6 +551,30 @@ func (ti *TodoItem) GetDeleteButton() *Button {
 	return ti.DeleteBtn
 }
 
+func (ti *TodoItem) GetNotesButton() *Button {
+	return ti.NotesBtn
+}
+
+// RowBounds returns the area of the row itself, without the notes pane.
+func (ti *TodoItem) RowBounds() image.Rectangle {
+	return image.Rect(ti.X, ti.Y, ti.X+ti.Width, ti.Y+ti.Height)
+}
+
+// FocusWidgets returns the item's editors and buttons that are currently
+// shown and can take focus, in tab order.
+func (ti *TodoItem) FocusWidgets() []Focusable {
+	var widgets []Focusable
+	if ti.Editing {
+		widgets = append(widgets, ti.EditTextBox)
+	}
+	if ti.Expanded && ti.EditingNotes {
+		widgets = append(widgets, ti.NotesArea, ti.SaveNotesBtn, ti.CancelNotesBtn)
+	} else if ti.Expanded {
+		widgets = append(widgets, ti.EditNotesBtn, ti.CancelNotesBtn)
+	}
+	return widgets
+}
+
 func (ti *TodoItem) Contains(x, y int) bool {
-	return x >= ti.X && x <= ti.X+ti.Width && y >= ti.Y && y <= ti.Y+ti.Height
+	return x >= ti.X && x <= ti.X+ti.Width && y >= ti.Y && y <= ti.Y+ti.TotalHeight()
 }
\ No newline at end of file
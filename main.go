@@ -1,7 +1,9 @@
 package main
 
 import (
+	"flag"
 	"log"
+	"os"
 	"path/filepath"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -17,10 +19,17 @@ const (
 )
 
 func main() {
+	recordPath := flag.String("record", "", "record the session to `file` for a bug report")
+	replayPath := flag.String("replay", "", "replay the session recorded in `file`")
+	flag.Parse()
+
 	// Set window properties
 	ebiten.SetWindowSize(WindowWidth, WindowHeight)
 	ebiten.SetWindowTitle(WindowTitle)
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	ebiten.SetWindowSizeLimits(game.MinWindowWidth, game.MinWindowHeight, -1, -1)
+	// Keep the window open until the game has saved everything
+	ebiten.SetWindowClosingHandled(true)
 
 	// Get absolute path for data file
 	dataPath, err := filepath.Abs(DataFile)
@@ -29,13 +38,67 @@ func main() {
 	}
 
 	// Create and initialize game
-	g, err := game.NewGame(dataPath)
+	var g *game.Game
+	var replayDir string
+	switch {
+	case *replayPath != "":
+		g, replayDir = newReplayGame(*replayPath)
+	case *recordPath != "":
+		g, err = game.NewRecordingGame(dataPath, *recordPath)
+	default:
+		g, err = game.NewGame(dataPath)
+	}
 	if err != nil {
 		log.Fatalf("Failed to create game: %v", err)
 	}
 
-	// Run game
-	if err := ebiten.RunGame(g); err != nil {
-		log.Fatalf("Game failed: %v", err)
+	// Run game, quitting cleanly on SIGINT and SIGTERM as well
+	stopSignals := g.HandleSignals()
+	runErr := ebiten.RunGame(g)
+	stopSignals()
+	// Save pending changes even when the game failed. Close does nothing
+	// when quitting has already saved everything.
+	closeErr := g.Close()
+	if runErr != nil {
+		if closeErr != nil {
+			log.Printf("Failed to save todos: %v", closeErr)
+		}
+		log.Fatalf("Game failed: %v", runErr)
+	}
+	if closeErr != nil {
+		log.Fatalf("Failed to save todos: %v", closeErr)
+	}
+
+	if *recordPath != "" {
+		if err := g.SaveRecording(); err != nil {
+			log.Fatalf("Failed to save recording: %v", err)
+		}
+		log.Printf("Saved recording to %s", *recordPath)
+	}
+	if *replayPath != "" {
+		os.RemoveAll(replayDir)
+		if done, err := g.ReplayResult(); !done {
+			log.Printf("Replay stopped before the end")
+		} else if err != nil {
+			log.Fatalf("Replay differs from the recording: %v", err)
+		}
+	}
+}
+
+// newReplayGame creates a game that replays the recording at path on a
+// copy of its data in a temporary directory, and returns the directory.
+func newReplayGame(path string) (*game.Game, string) {
+	rec, err := game.LoadRecording(path)
+	if err != nil {
+		log.Fatalf("Failed to load recording: %v", err)
+	}
+	dir, err := os.MkdirTemp("", "todo-replay-")
+	if err != nil {
+		log.Fatalf("Failed to create replay directory: %v", err)
+	}
+	g, err := game.NewReplayGame(rec, dir)
+	if err != nil {
+		log.Fatalf("Failed to create game: %v", err)
 	}
-}
\ No newline at end of file
+	return g, dir
+}